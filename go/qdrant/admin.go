@@ -0,0 +1,169 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/firebase/genkit/go/genkit"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// AdminInput selects the collection and documents of an admin flow.
+type AdminInput struct {
+	// Collection is the name of a collection configured with [Init].
+	Collection string `json:"collection"`
+	// Where restricts the operation to documents whose metadata matches
+	// every key and value.
+	Where map[string]any `json:"where,omitempty"`
+	// Confirm must equal Collection for destructive operations to run.
+	// Otherwise they only report what would be affected.
+	Confirm string `json:"confirm,omitempty"`
+}
+
+// CollectionSummary is the output of the collection info flow.
+type CollectionSummary struct {
+	Status              string   `json:"status"`
+	Points              uint64   `json:"points"`
+	IndexedVectors      uint64   `json:"indexedVectors"`
+	Segments            uint64   `json:"segments"`
+	PayloadIndexedPaths []string `json:"payloadIndexedPaths,omitempty"`
+}
+
+// CountOutput is the output of the count and delete flows.
+type CountOutput struct {
+	Count   uint64 `json:"count"`
+	Deleted bool   `json:"deleted,omitempty"`
+}
+
+// SnapshotOutput is the output of the snapshot flow.
+type SnapshotOutput struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+// DefineAdminFlows defines flows to inspect and operate collections from
+// the Genkit developer tooling: qdrant/collectionInfo, qdrant/count,
+// qdrant/deleteByFilter and qdrant/snapshot.
+func DefineAdminFlows() {
+	genkit.DefineFlow("qdrant/collectionInfo", func(ctx context.Context, in *AdminInput) (*CollectionSummary, error) {
+		ds, err := adminStore(in)
+		if err != nil {
+			return nil, err
+		}
+		info, err := ds.client.GetCollectionInfo(ctx, ds.collectionName)
+		if err != nil {
+			return nil, fmt.Errorf("qdrant collection info failed: %v", err)
+		}
+		out := &CollectionSummary{
+			Status:         info.GetStatus().String(),
+			Points:         info.GetPointsCount(),
+			IndexedVectors: info.GetIndexedVectorsCount(),
+			Segments:       info.GetSegmentsCount(),
+		}
+		for path := range info.GetPayloadSchema() {
+			out.PayloadIndexedPaths = append(out.PayloadIndexedPaths, path)
+		}
+		return out, nil
+	})
+
+	genkit.DefineFlow("qdrant/count", func(ctx context.Context, in *AdminInput) (*CountOutput, error) {
+		ds, err := adminStore(in)
+		if err != nil {
+			return nil, err
+		}
+		n, err := ds.adminCount(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+		return &CountOutput{Count: n}, nil
+	})
+
+	genkit.DefineFlow("qdrant/deleteByFilter", func(ctx context.Context, in *AdminInput) (*CountOutput, error) {
+		ds, err := adminStore(in)
+		if err != nil {
+			return nil, err
+		}
+		if len(in.Where) == 0 {
+			return nil, fmt.Errorf("qdrant delete requires a where clause")
+		}
+		n, err := ds.adminCount(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+		if in.Confirm != in.Collection {
+			return &CountOutput{Count: n}, nil
+		}
+		filter, err := ds.equalityFilter(in.Where)
+		if err != nil {
+			return nil, err
+		}
+		if err := ds.deleteByFilter(ctx, filter); err != nil {
+			return nil, err
+		}
+		return &CountOutput{Count: n, Deleted: true}, nil
+	})
+
+	genkit.DefineFlow("qdrant/snapshot", func(ctx context.Context, in *AdminInput) (*SnapshotOutput, error) {
+		ds, err := adminStore(in)
+		if err != nil {
+			return nil, err
+		}
+		if err := ds.writable("snapshot"); err != nil {
+			return nil, err
+		}
+		snap, err := ds.client.CreateSnapshot(ctx, ds.collectionName)
+		if err != nil {
+			return nil, fmt.Errorf("qdrant snapshot failed: %v", err)
+		}
+		return &SnapshotOutput{Name: snap.GetName(), Size: snap.GetSize()}, nil
+	})
+}
+
+func adminStore(in *AdminInput) (*DocStore, error) {
+	if in == nil {
+		return nil, fmt.Errorf("qdrant admin flow: missing input")
+	}
+	ds := Store(in.Collection)
+	if ds == nil {
+		return nil, fmt.Errorf("qdrant admin flow: collection %q is not configured", in.Collection)
+	}
+	return ds, nil
+}
+
+func (ds *DocStore) adminCount(ctx context.Context, in *AdminInput) (uint64, error) {
+	filter, err := ds.equalityFilter(in.Where)
+	if err != nil {
+		return 0, err
+	}
+	n, err := ds.client.Count(ctx, &qclient.CountPoints{
+		CollectionName: ds.collectionName,
+		Filter:         ds.scopeFilter(ds.envFilter(filter, &RetrieverOptions{})),
+		Exact:          qclient.PtrOf(true),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("qdrant count failed: %v", err)
+	}
+	return n, nil
+}
+
+// deleteByFilter deletes every point matching filter in the namespace
+// and environment of the store.
+func (ds *DocStore) deleteByFilter(ctx context.Context, filter *qclient.Filter) error {
+	if err := ds.writable("delete"); err != nil {
+		return err
+	}
+	_, err := ds.client.Delete(ctx, ds.deleteRequest(filter))
+	if err != nil {
+		return fmt.Errorf("qdrant delete failed: %v", err)
+	}
+	return nil
+}
+
+// deleteRequest returns the delete request of deleteByFilter.
+func (ds *DocStore) deleteRequest(filter *qclient.Filter) *qclient.DeletePoints {
+	return &qclient.DeletePoints{
+		CollectionName: ds.collectionName,
+		Points:         qclient.NewPointsSelectorFilter(ds.scopeFilter(ds.envFilter(filter, &RetrieverOptions{}))),
+		Wait:           qclient.PtrOf(true),
+	}
+}
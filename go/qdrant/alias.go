@@ -0,0 +1,75 @@
+package qdrant
+
+import qclient "github.com/qdrant/go-client/qdrant"
+
+// vectorName returns the physical named vector documents are indexed into
+// and queried with, resolving the configured logical name through the
+// vector aliases. It is empty for the unnamed default vector.
+func (ds *DocStore) vectorName() string {
+	return ds.physicalVector(ds.logicalVector)
+}
+
+// physicalVector resolves a logical vector name through the aliases.
+func (ds *DocStore) physicalVector(logical string) string {
+	ds.aliasMu.RLock()
+	defer ds.aliasMu.RUnlock()
+	if physical, ok := ds.vectorAliases[logical]; ok {
+		return physical
+	}
+	return logical
+}
+
+// SetVectorAlias points a logical vector name at a physical named vector
+// of the collection. After an embedder upgrade has filled a new vector,
+// flipping the alias of Config.VectorName switches retrieval and indexing
+// to it, and flipping it back rolls the switch back.
+func (ds *DocStore) SetVectorAlias(logical, physical string) {
+	ds.aliasMu.Lock()
+	defer ds.aliasMu.Unlock()
+	if ds.vectorAliases == nil {
+		ds.vectorAliases = make(map[string]string)
+	}
+	ds.vectorAliases[logical] = physical
+}
+
+// pointVectors returns the vectors of a point stored under name.
+func pointVectors(name string, vector []float32) *qclient.Vectors {
+	if name == "" {
+		return qclient.NewVectors(vector...)
+	}
+	return qclient.NewVectorsMap(map[string]*qclient.Vector{name: qclient.NewVector(vector...)})
+}
+
+// vectorsConfig returns the configuration of a collection with a single
+// vector stored under name.
+func vectorsConfig(name string, params *qclient.VectorParams) *qclient.VectorsConfig {
+	if name == "" {
+		return qclient.NewVectorsConfig(params)
+	}
+	return qclient.NewVectorsConfigMap(map[string]*qclient.VectorParams{name: params})
+}
+
+// withVector selects the vector stored under name in read requests.
+func withVector(name string) *qclient.WithVectorsSelector {
+	if name == "" {
+		return qclient.NewWithVectors(true)
+	}
+	return qclient.NewWithVectorsInclude(name)
+}
+
+// outputVector returns the vector stored under name in a retrieved point.
+func outputVector(v *qclient.Vectors, name string) *qclient.Vector {
+	if name == "" {
+		return v.GetVector()
+	}
+	return v.GetVectors().GetVectors()[name]
+}
+
+// setVector stores a vector under name in a point, converting an unnamed
+// vector to the one named "".
+func setVector(point *qclient.PointStruct, name string, v *qclient.Vector) {
+	if dense := point.GetVectors().GetVector(); dense != nil {
+		point.Vectors = qclient.NewVectorsMap(map[string]*qclient.Vector{"": dense})
+	}
+	point.GetVectors().GetVectors().Vectors[name] = v
+}
@@ -0,0 +1,27 @@
+package qdrant
+
+import "testing"
+
+func TestVectorName(t *testing.T) {
+	ds := &DocStore{logicalVector: "text", vectorAliases: map[string]string{"text": "text-v1"}}
+	if got := ds.vectorName(); got != "text-v1" {
+		t.Errorf("got %q, want text-v1", got)
+	}
+	ds.SetVectorAlias("text", "text-v2")
+	if got := ds.vectorName(); got != "text-v2" {
+		t.Errorf("got %q after flipping the alias, want text-v2", got)
+	}
+	if got := (&DocStore{logicalVector: "image"}).vectorName(); got != "image" {
+		t.Errorf("got %q without an alias, want image", got)
+	}
+}
+
+func TestPointVectors(t *testing.T) {
+	if v := pointVectors("", []float32{1}); v.GetVector() == nil {
+		t.Error("unnamed vector not set")
+	}
+	v := pointVectors("text-v2", []float32{1, 2})
+	if got := len(outputVector(v, "text-v2").GetData()); got != 2 {
+		t.Errorf("named vector has %d values, want 2", got)
+	}
+}
@@ -0,0 +1,197 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// AnalyzeOptions configures [DocStore.Analyze].
+type AnalyzeOptions struct {
+	// Fields are the metadata keys whose value counts are reported.
+	// Each field needs a keyword, integer or bool payload index.
+	Fields []string
+	// FacetLimit is the maximum number of values reported per field.
+	// Defaults to 10.
+	FacetLimit int
+	// Sample is the number of points used for the vector statistics.
+	// Defaults to 100.
+	Sample int
+	// Filter restricts the analyzed points.
+	Filter *qclient.Filter
+}
+
+// Report summarizes the contents of a collection.
+type Report struct {
+	// Points is the number of points matching the filter.
+	Points uint64
+	// Values holds the most frequent values of each requested field.
+	Values map[string][]ValueCount
+	// Norms is the distribution of the L2 norm of sampled vectors.
+	Norms Distribution
+	// Similarity is the distribution of scores between sampled points
+	// and their nearest neighbours.
+	Similarity Distribution
+}
+
+// ValueCount is the number of points holding a payload value.
+type ValueCount struct {
+	Value any
+	Count uint64
+}
+
+// Distribution summarizes a set of values.
+type Distribution struct {
+	Count          int
+	Min, Max, Mean float64
+	StdDev         float64
+	P50, P90, P99  float64
+}
+
+const defaultAnalyzeSample = 100
+
+// Analyze produces a [Report] to guide payload index and score threshold
+// tuning.
+func (ds *DocStore) Analyze(ctx context.Context, opts *AnalyzeOptions) (*Report, error) {
+	if opts == nil {
+		opts = &AnalyzeOptions{}
+	}
+	sample := opts.Sample
+	if sample <= 0 {
+		sample = defaultAnalyzeSample
+	}
+
+	count, err := ds.client.Count(ctx, &qclient.CountPoints{
+		CollectionName: ds.collectionName,
+		Filter:         ds.scopeFilter(ds.envFilter(opts.Filter, &RetrieverOptions{})),
+		Exact:          qclient.PtrOf(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("qdrant count failed: %v", err)
+	}
+	report := &Report{
+		Points: count,
+		Values: make(map[string][]ValueCount),
+	}
+
+	if len(opts.Fields) > 0 {
+		if err := ds.require(ctx, FeatureFacets); err != nil {
+			return nil, err
+		}
+	}
+	for _, field := range opts.Fields {
+		req := &qclient.FacetCounts{
+			CollectionName: ds.collectionName,
+			Key:            ds.metadataField(field),
+			Filter:         ds.scopeFilter(ds.envFilter(opts.Filter, &RetrieverOptions{})),
+		}
+		if opts.FacetLimit > 0 {
+			req.Limit = qclient.PtrOf(uint64(opts.FacetLimit))
+		}
+		hits, err := ds.client.Facet(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("qdrant facet on %q failed: %v", field, err)
+		}
+		for _, h := range hits {
+			report.Values[field] = append(report.Values[field], ValueCount{
+				Value: facetValue(h.GetValue()),
+				Count: h.GetCount(),
+			})
+		}
+	}
+
+	if count == 0 {
+		return report, nil
+	}
+
+	name := ds.vectorName()
+	points, err := ds.client.Scroll(ctx, &qclient.ScrollPoints{
+		CollectionName: ds.collectionName,
+		Filter:         ds.scopeFilter(ds.envFilter(opts.Filter, &RetrieverOptions{})),
+		Limit:          qclient.PtrOf(uint32(sample)),
+		WithPayload:    qclient.NewWithPayload(false),
+		WithVectors:    withVector(name),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("qdrant scroll failed: %v", err)
+	}
+	norms := make([]float64, 0, len(points))
+	for _, p := range points {
+		var sum float64
+		for _, v := range outputVector(p.GetVectors(), name).GetData() {
+			sum += float64(v) * float64(v)
+		}
+		norms = append(norms, math.Sqrt(sum))
+	}
+	report.Norms = distribution(norms)
+
+	if count > 1 {
+		pairs, err := ds.DistancePairs(ctx, &MatrixOptions{Filter: opts.Filter, Sample: sample})
+		if err != nil {
+			return nil, err
+		}
+		scores := make([]float64, 0, len(pairs))
+		for _, p := range pairs {
+			scores = append(scores, float64(p.Score))
+		}
+		report.Similarity = distribution(scores)
+	}
+	return report, nil
+}
+
+// facetValue converts a facet value to its Go equivalent.
+func facetValue(v *qclient.FacetValue) any {
+	switch v := v.GetVariant().(type) {
+	case *qclient.FacetValue_IntegerValue:
+		return v.IntegerValue
+	case *qclient.FacetValue_BoolValue:
+		return v.BoolValue
+	case *qclient.FacetValue_StringValue:
+		return v.StringValue
+	default:
+		return nil
+	}
+}
+
+// distribution computes summary statistics of values.
+func distribution(values []float64) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	mean := sum / float64(len(sorted))
+	var variance float64
+	for _, v := range sorted {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(sorted))
+
+	return Distribution{
+		Count:  len(sorted),
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+		Mean:   mean,
+		StdDev: math.Sqrt(variance),
+		P50:    percentile(sorted, 0.50),
+		P90:    percentile(sorted, 0.90),
+		P99:    percentile(sorted, 0.99),
+	}
+}
+
+// percentile returns the nearest-rank percentile p of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
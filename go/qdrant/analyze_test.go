@@ -0,0 +1,17 @@
+package qdrant
+
+import "testing"
+
+func TestDistribution(t *testing.T) {
+	d := distribution([]float64{4, 1, 3, 2, 5, 6, 7, 8, 9, 10})
+	if d.Count != 10 || d.Min != 1 || d.Max != 10 || d.Mean != 5.5 {
+		t.Errorf("got %+v", d)
+	}
+	if d.P50 != 5 || d.P90 != 9 || d.P99 != 10 {
+		t.Errorf("got percentiles %v %v %v, want 5 9 10", d.P50, d.P90, d.P99)
+	}
+
+	if d := distribution(nil); d != (Distribution{}) {
+		t.Errorf("empty input: got %+v", d)
+	}
+}
@@ -0,0 +1,132 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// backfillBatch is the number of points scrolled and re-embedded at once.
+const backfillBatch = 64
+
+// Backfill adds the named vector vectorName to every point of the
+// collection that lacks it, embedding the stored content with embedder,
+// e.g. to migrate a collection to a new embedder. At most rate points are
+// updated per second; zero is unlimited. Points that already have the
+// vector are left alone, so an interrupted backfill can be resumed. It
+// returns the number of points updated.
+func (ds *DocStore) Backfill(ctx context.Context, vectorName string, embedder ai.Embedder, rate float64) (int, error) {
+	if err := ds.writable("Backfill"); err != nil {
+		return 0, err
+	}
+	var (
+		updated int
+		start   = time.Now()
+		batch   []*qclient.RetrievedPoint
+	)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		n, err := ds.backfillPoints(ctx, vectorName, embedder, batch)
+		batch = batch[:0]
+		if err != nil {
+			return err
+		}
+		updated += n
+		slog.DebugContext(ctx, "qdrant backfill progress", "collection", ds.collectionName, "vector", vectorName, "updated", updated)
+		if wait := backfillWait(time.Since(start), updated, rate); wait > 0 {
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+		return nil
+	}
+
+	err := ds.scroll(ctx, &qclient.ScrollPoints{
+		CollectionName: ds.collectionName,
+		Limit:          qclient.PtrOf(uint32(backfillBatch)),
+		WithPayload:    qclient.NewWithPayloadInclude(ds.contentPayloadKey, ds.metadataPayloadKey),
+		WithVectors:    qclient.NewWithVectorsInclude(vectorName),
+	}, func(p *qclient.RetrievedPoint) error {
+		// Qdrant cannot filter on the presence of a vector, so points
+		// are checked here.
+		if outputVector(p.GetVectors(), vectorName) != nil {
+			return nil
+		}
+		batch = append(batch, p)
+		if len(batch) < backfillBatch {
+			return nil
+		}
+		return flush()
+	})
+	if err == nil {
+		err = flush()
+	}
+	return updated, err
+}
+
+// backfillPoints embeds the content of points and stores it under
+// vectorName, keeping their other vectors.
+func (ds *DocStore) backfillPoints(ctx context.Context, vectorName string, embedder ai.Embedder, points []*qclient.RetrievedPoint) (int, error) {
+	docs := make([]*ai.Document, len(points))
+	for i, p := range points {
+		doc, err := ds.documentFromPayload(p.GetPayload())
+		if err != nil {
+			return 0, err
+		}
+		docs[i] = doc
+	}
+	vectors, err := ds.embedWith(ctx, embedder, nil, docs, &IndexerOptions{})
+	if err != nil {
+		return 0, err
+	}
+
+	// Points with custom shard keys must be updated in their shard.
+	byShard := make(map[string][]*qclient.PointVectors)
+	keys := make(map[string]*qclient.ShardKey)
+	for i, p := range points {
+		vector, err := checkVector(vectors[i], i, ds.vectorPolicy)
+		if err != nil {
+			return 0, err
+		}
+		key := p.GetShardKey().String()
+		keys[key] = p.GetShardKey()
+		byShard[key] = append(byShard[key], &qclient.PointVectors{
+			Id:      p.GetId(),
+			Vectors: pointVectors(vectorName, vector),
+		})
+	}
+	for key, pvs := range byShard {
+		req := &qclient.UpdatePointVectors{
+			CollectionName: ds.collectionName,
+			Wait:           qclient.PtrOf(true),
+			Points:         pvs,
+		}
+		if sk := keys[key]; sk != nil {
+			req.ShardKeySelector = &qclient.ShardKeySelector{ShardKeys: []*qclient.ShardKey{sk}}
+		}
+		if _, err := ds.dataClient().UpdateVectors(ctx, req); err != nil {
+			return 0, fmt.Errorf("qdrant backfill update failed: %v", err)
+		}
+	}
+	return len(points), nil
+}
+
+// backfillWait returns how long to wait after updating done points in
+// elapsed time to stay within rate points per second.
+func backfillWait(elapsed time.Duration, done int, rate float64) time.Duration {
+	if rate <= 0 {
+		return 0
+	}
+	target := time.Duration(float64(done) / rate * float64(time.Second))
+	return max(target-elapsed, 0)
+}
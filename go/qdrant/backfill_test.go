@@ -0,0 +1,24 @@
+package qdrant
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackfillWait(t *testing.T) {
+	for _, tc := range []struct {
+		elapsed time.Duration
+		done    int
+		rate    float64
+		want    time.Duration
+	}{
+		{time.Second, 100, 0, 0},
+		{time.Second, 100, 50, time.Second},
+		{3 * time.Second, 100, 50, 0},
+		{0, 64, 128, 500 * time.Millisecond},
+	} {
+		if got := backfillWait(tc.elapsed, tc.done, tc.rate); got != tc.want {
+			t.Errorf("backfillWait(%v, %d, %v) = %v, want %v", tc.elapsed, tc.done, tc.rate, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package qdrant
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ThrottleConfig configures how requests throttled by Qdrant, e.g. by the
+// rate limits of Qdrant Cloud, are retried.
+type ThrottleConfig struct {
+	// MaxRetries is the number of times a throttled request is retried
+	// before its error is returned. Defaults to 5.
+	MaxRetries int
+	// BaseDelay is the delay before the first retry when the server gives
+	// no retry-after hint. It doubles with every retry. Defaults to 500ms.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between retries, including server hints.
+	// Defaults to 30s.
+	MaxDelay time.Duration
+}
+
+// ThrottleStats counts the throttling events seen by a [DocStore].
+type ThrottleStats struct {
+	// Events is the number of throttled responses.
+	Events int64
+	// Retries is the number of requests retried after being throttled.
+	Retries int64
+	// Waited is the total time spent waiting before retries.
+	Waited time.Duration
+}
+
+// throttler retries throttled gRPC calls, honoring the retry-after hint of
+// the server when it sends one.
+type throttler struct {
+	cfg ThrottleConfig
+
+	mu    sync.Mutex
+	stats ThrottleStats
+}
+
+func newThrottler(cfg ThrottleConfig) *throttler {
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 5
+	}
+	if cfg.BaseDelay == 0 {
+		cfg.BaseDelay = 500 * time.Millisecond
+	}
+	if cfg.MaxDelay == 0 {
+		cfg.MaxDelay = 30 * time.Second
+	}
+	return &throttler{cfg: cfg}
+}
+
+// unaryInterceptor implements [grpc.UnaryClientInterceptor].
+func (t *throttler) unaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	for attempt := 0; ; attempt++ {
+		var header, trailer metadata.MD
+		err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Header(&header), grpc.Trailer(&trailer))...)
+		if status.Code(err) != codes.ResourceExhausted {
+			return err
+		}
+		retry := attempt < t.cfg.MaxRetries
+		delay := t.delay(attempt, retryAfter(header, trailer))
+		t.record(retry, delay)
+		if !retry {
+			return err
+		}
+		slog.WarnContext(ctx, "qdrant request throttled", "method", method, "attempt", attempt+1, "delay", delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// delay returns the time to wait before retrying. A server hint takes
+// precedence over exponential backoff; both are capped by MaxDelay.
+func (t *throttler) delay(attempt int, hint time.Duration) time.Duration {
+	d := hint
+	if d <= 0 {
+		d = t.cfg.BaseDelay << attempt
+		if d <= 0 { // overflow
+			d = t.cfg.MaxDelay
+		}
+	}
+	return min(d, t.cfg.MaxDelay)
+}
+
+func (t *throttler) record(retry bool, delay time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats.Events++
+	if retry {
+		t.stats.Retries++
+		t.stats.Waited += delay
+	}
+}
+
+// ThrottleStats returns the throttling events seen since Init. It is zero
+// unless Config.Throttle is set.
+func (ds *DocStore) ThrottleStats() ThrottleStats {
+	if ds.throttler == nil {
+		return ThrottleStats{}
+	}
+	ds.throttler.mu.Lock()
+	defer ds.throttler.mu.Unlock()
+	return ds.throttler.stats
+}
+
+// retryAfter returns the delay requested by the retry-after metadata of a
+// response, given in seconds or as an HTTP date, or 0 if there is none.
+func retryAfter(mds ...metadata.MD) time.Duration {
+	for _, md := range mds {
+		for _, v := range md.Get("retry-after") {
+			if secs, err := strconv.ParseFloat(v, 64); err == nil && secs >= 0 {
+				return time.Duration(secs * float64(time.Second))
+			}
+			if t, err := http.ParseTime(v); err == nil {
+				return max(time.Until(t), 0)
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,71 @@
+package qdrant
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryAfter(t *testing.T) {
+	if got := retryAfter(metadata.Pairs("retry-after", "1.5")); got != 1500*time.Millisecond {
+		t.Errorf("got %v, want 1.5s", got)
+	}
+	if got := retryAfter(metadata.MD{}, metadata.Pairs("retry-after", "2")); got != 2*time.Second {
+		t.Errorf("got %v from trailer, want 2s", got)
+	}
+	if got := retryAfter(metadata.Pairs("retry-after", "soon")); got != 0 {
+		t.Errorf("got %v for an invalid hint, want 0", got)
+	}
+}
+
+func TestThrottlerDelay(t *testing.T) {
+	th := newThrottler(ThrottleConfig{BaseDelay: time.Second, MaxDelay: 5 * time.Second})
+	for _, tc := range []struct {
+		attempt int
+		hint    time.Duration
+		want    time.Duration
+	}{
+		{0, 0, time.Second},
+		{2, 0, 4 * time.Second},
+		{3, 0, 5 * time.Second},
+		{3, 2 * time.Second, 2 * time.Second},
+		{0, time.Minute, 5 * time.Second},
+	} {
+		if got := th.delay(tc.attempt, tc.hint); got != tc.want {
+			t.Errorf("delay(%d, %v) = %v, want %v", tc.attempt, tc.hint, got, tc.want)
+		}
+	}
+}
+
+func TestThrottlerInterceptor(t *testing.T) {
+	th := newThrottler(ThrottleConfig{MaxRetries: 2, BaseDelay: time.Millisecond})
+	calls := 0
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		calls++
+		if calls < 3 {
+			return status.Error(codes.ResourceExhausted, "rate limited")
+		}
+		return nil
+	}
+	if err := th.unaryInterceptor(context.Background(), "/qdrant.Points/Upsert", nil, nil, nil, invoker); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Errorf("got %d calls, want 3", calls)
+	}
+	if stats := th.stats; stats.Events != 2 || stats.Retries != 2 {
+		t.Errorf("got stats %+v, want 2 events and 2 retries", stats)
+	}
+
+	// Once retries are exhausted the throttling error is returned.
+	calls = -10
+	err := th.unaryInterceptor(context.Background(), "/qdrant.Points/Upsert", nil, nil, nil, invoker)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("got %v, want a ResourceExhausted error", err)
+	}
+}
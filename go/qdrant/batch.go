@@ -0,0 +1,49 @@
+package qdrant
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// indexBatches indexes docs in batches of Config.IndexBatchSize with up to
+// Config.IndexWorkers batches in flight, stopping at the first error. The
+// first batch is indexed alone, so that creating the collection or fixing
+// its vector size happens once.
+func (ds *DocStore) indexBatches(ctx context.Context, docs []*ai.Document, iopt *IndexerOptions, shardKey *qclient.ShardKeySelector) error {
+	size := cmp.Or(iopt.BatchSize, ds.indexBatchSize)
+	if size <= 0 || size >= len(docs) {
+		return ds.indexBatch(ctx, docs, iopt, shardKey)
+	}
+	if err := ds.indexBatch(ctx, docs[:size], iopt, shardKey); err != nil {
+		return fmt.Errorf("qdrant index batch at document 0: %w", err)
+	}
+
+	workers := max(cmp.Or(iopt.Workers, ds.indexWorkers), 1)
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	slots := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for start := size; start < len(docs) && ctx.Err() == nil; start += size {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			continue
+		}
+		batch := docs[start:min(start+size, len(docs))]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if err := ds.indexBatch(ctx, batch, iopt, shardKey); err != nil {
+				cancel(fmt.Errorf("qdrant index batch at document %d: %w", start, err))
+			}
+		}()
+	}
+	wg.Wait()
+	return context.Cause(ctx)
+}
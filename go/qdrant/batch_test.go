@@ -0,0 +1,48 @@
+package qdrant
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestIndexBatches(t *testing.T) {
+	docs := make([]*ai.Document, 10)
+	for i := range docs {
+		docs[i] = ai.DocumentFromText("bad", nil)
+	}
+
+	// Quarantining every document runs all batches without upserts.
+	var mu sync.Mutex
+	quarantined := 0
+	ds := &DocStore{
+		embedder:       failingEmbedder{},
+		indexBatchSize: 3,
+		indexWorkers:   2,
+		quarantineSink: QuarantineFunc(func(context.Context, *ai.Document, error) error {
+			mu.Lock()
+			defer mu.Unlock()
+			quarantined++
+			return nil
+		}),
+	}
+	if err := ds.indexBatches(context.Background(), docs, &IndexerOptions{ContinueOnError: true}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if quarantined != len(docs) {
+		t.Errorf("quarantined %d documents, want %d", quarantined, len(docs))
+	}
+
+	// A failing first batch stops the request.
+	ds = &DocStore{embedder: failingEmbedder{}}
+	err := ds.indexBatches(context.Background(), docs, &IndexerOptions{BatchSize: 4}, nil)
+	if err == nil || !strings.Contains(err.Error(), "batch at document 0") {
+		t.Fatalf("got %v, want an error for the first batch", err)
+	}
+	if got := ds.Usage()[""].EmbeddedDocuments; got != 4 {
+		t.Errorf("embedded %d documents, want only the first batch", got)
+	}
+}
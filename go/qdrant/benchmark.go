@@ -0,0 +1,126 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// BenchmarkQuery is a query with the documents expected to be retrieved.
+type BenchmarkQuery struct {
+	Query string
+	// Relevant holds the texts of the relevant documents.
+	Relevant []string
+}
+
+// BenchmarkResult holds the metrics measured for one set of options.
+type BenchmarkResult struct {
+	Options *RetrieverOptions
+	// Latency is the distribution of retrieval latencies in milliseconds.
+	Latency Distribution
+	// Recall and Precision are averaged over all queries.
+	Recall    float64
+	Precision float64
+	// MRR is the mean reciprocal rank of the first relevant document.
+	MRR float64
+}
+
+// Benchmark runs every query against the retriever once for each entry of
+// grid and reports latency and quality metrics, so that options such as K
+// or the search parameters can be tuned by measurement.
+func Benchmark(ctx context.Context, r ai.Retriever, queries []BenchmarkQuery, grid []*RetrieverOptions) ([]BenchmarkResult, error) {
+	results := make([]BenchmarkResult, 0, len(grid))
+	for _, opts := range grid {
+		res := BenchmarkResult{Options: opts}
+		latencies := make([]float64, 0, len(queries))
+		for _, q := range queries {
+			start := time.Now()
+			resp, err := ai.Retrieve(ctx, r, ai.WithRetrieverText(q.Query), ai.WithRetrieverOpts(opts))
+			if err != nil {
+				return nil, fmt.Errorf("qdrant benchmark query %q failed: %v", q.Query, err)
+			}
+			latencies = append(latencies, float64(time.Since(start))/float64(time.Millisecond))
+
+			recall, precision, rr := scoreRetrieval(resp.Documents, q.Relevant)
+			res.Recall += recall
+			res.Precision += precision
+			res.MRR += rr
+		}
+		if n := float64(len(queries)); n > 0 {
+			res.Recall /= n
+			res.Precision /= n
+			res.MRR /= n
+		}
+		res.Latency = distribution(latencies)
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// scoreRetrieval returns the recall, precision and reciprocal rank of the
+// retrieved documents against the relevant texts.
+func scoreRetrieval(docs []*ai.Document, relevant []string) (recall, precision, rr float64) {
+	want := make(map[string]bool, len(relevant))
+	for _, t := range relevant {
+		want[t] = true
+	}
+	var hits int
+	for i, d := range docs {
+		if !want[documentText(d)] {
+			continue
+		}
+		hits++
+		if rr == 0 {
+			rr = 1 / float64(i+1)
+		}
+	}
+	if len(relevant) > 0 {
+		recall = float64(hits) / float64(len(relevant))
+	}
+	if len(docs) > 0 {
+		precision = float64(hits) / float64(len(docs))
+	}
+	return recall, precision, rr
+}
+
+// documentText concatenates the text parts of a document. The text of a
+// single part is returned as is, and longer contents are assembled in one
+// allocation of the exact size, since indexing calls it for every
+// document of every batch.
+func documentText(d *ai.Document) string {
+	switch len(d.Content) {
+	case 0:
+		return ""
+	case 1:
+		return d.Content[0].Text
+	}
+	var sb strings.Builder
+	sb.Grow(documentLen(d))
+	for _, p := range d.Content {
+		sb.WriteString(p.Text)
+	}
+	return sb.String()
+}
+
+// documentLen returns the length in bytes of the text of a document,
+// without assembling it.
+func documentLen(d *ai.Document) int {
+	n := 0
+	for _, p := range d.Content {
+		n += len(p.Text)
+	}
+	return n
+}
+
+// documentTexts returns the text of each document, so that a batch
+// assembles each text once for all its consumers.
+func documentTexts(docs []*ai.Document) []string {
+	texts := make([]string, len(docs))
+	for i, d := range docs {
+		texts[i] = documentText(d)
+	}
+	return texts
+}
@@ -0,0 +1,34 @@
+package qdrant
+
+import (
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestScoreRetrieval(t *testing.T) {
+	docs := []*ai.Document{
+		ai.DocumentFromText("miss", nil),
+		ai.DocumentFromText("a", nil),
+		ai.DocumentFromText("b", nil),
+		ai.DocumentFromText("other", nil),
+	}
+	recall, precision, rr := scoreRetrieval(docs, []string{"a", "b", "c", "d"})
+	if recall != 0.5 || precision != 0.5 || rr != 0.5 {
+		t.Errorf("got recall %v, precision %v, rr %v; want 0.5 each", recall, precision, rr)
+	}
+}
+
+func TestDocumentText(t *testing.T) {
+	doc := &ai.Document{Content: []*ai.Part{ai.NewTextPart("ab"), ai.NewTextPart("c")}}
+	if got := documentText(doc); got != "abc" || documentLen(doc) != 3 {
+		t.Errorf("got %q", got)
+	}
+	if got := documentText(&ai.Document{}); got != "" {
+		t.Errorf("got %q without content", got)
+	}
+	single := ai.DocumentFromText("plain", nil)
+	if n := testing.AllocsPerRun(10, func() { documentText(single) }); n != 0 {
+		t.Errorf("single part text allocated %v times", n)
+	}
+}
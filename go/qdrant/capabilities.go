@@ -0,0 +1,119 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// Feature is a capability of the Qdrant server that older versions lack.
+type Feature string
+
+const (
+	// FeatureQuery is the universal Query API, with prefetches and
+	// fusion.
+	FeatureQuery Feature = "query API"
+	// FeatureMultivector is the storage of multivectors, used by
+	// Config.MultiVector.
+	FeatureMultivector Feature = "multivectors"
+	// FeatureFacets is facet counting, used by [DocStore.Analyze].
+	FeatureFacets Feature = "facets"
+	// FeatureMatrix is the distance matrix API, used by
+	// [DocStore.DistancePairs] and [DocStore.FindNearDuplicates].
+	FeatureMatrix Feature = "distance matrix"
+	// FeatureFormula is score boosting with formulas.
+	FeatureFormula Feature = "formulas"
+)
+
+// featureVersions is the first Qdrant version supporting each feature.
+var featureVersions = map[Feature]version{
+	FeatureQuery:       {1, 10, 0},
+	FeatureMultivector: {1, 10, 0},
+	FeatureFacets:      {1, 12, 0},
+	FeatureMatrix:      {1, 12, 0},
+	FeatureFormula:     {1, 14, 0},
+}
+
+// ErrUnsupported is wrapped by the errors of features the Qdrant server
+// is too old for.
+var ErrUnsupported = errors.New("qdrant: feature not supported by the server")
+
+// version is a Qdrant version.
+type version [3]int
+
+func (v version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v[0], v[1], v[2])
+}
+
+func (v version) less(w version) bool {
+	for i := range v {
+		if v[i] != w[i] {
+			return v[i] < w[i]
+		}
+	}
+	return false
+}
+
+// parseVersion parses a version such as "1.12.4", "v1.12" or
+// "1.13.0-dev".
+func parseVersion(s string) (version, error) {
+	var v version
+	s, _, _ = strings.Cut(strings.TrimPrefix(s, "v"), "-")
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, fmt.Errorf("invalid version %q", s)
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid version %q", s)
+		}
+		v[i] = n
+	}
+	return v, nil
+}
+
+// Supports reports whether the server supports a feature. The server
+// version is fetched once; if it cannot be determined, every feature is
+// assumed to be supported.
+func (ds *DocStore) Supports(ctx context.Context, f Feature) bool {
+	return ds.require(ctx, f) == nil
+}
+
+// require returns an error wrapping [ErrUnsupported] if the server is too
+// old for a feature.
+func (ds *DocStore) require(ctx context.Context, f Feature) error {
+	server, ok := ds.serverVersion(ctx)
+	if !ok {
+		return nil
+	}
+	if want := featureVersions[f]; server.less(want) {
+		return fmt.Errorf("%w: %s require Qdrant >= %d.%d, server is %s", ErrUnsupported, f, want[0], want[1], server)
+	}
+	return nil
+}
+
+// serverVersion returns the version of the server, fetching it the first
+// time. It reports false if the version is unknown.
+func (ds *DocStore) serverVersion(ctx context.Context) (version, bool) {
+	ds.versionMu.Lock()
+	defer ds.versionMu.Unlock()
+	if ds.versionFetched || ds.client == nil {
+		return ds.version, ds.versionKnown
+	}
+	reply, err := ds.client.HealthCheck(ctx)
+	if err != nil {
+		// Retried on the next call.
+		return version{}, false
+	}
+	v, err := parseVersion(reply.GetVersion())
+	if err != nil {
+		slog.WarnContext(ctx, "qdrant server version not recognized", "version", reply.GetVersion())
+	}
+	ds.versionFetched = true
+	ds.version, ds.versionKnown = v, err == nil
+	return ds.version, ds.versionKnown
+}
@@ -0,0 +1,46 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	for s, want := range map[string]version{
+		"1.12.4":     {1, 12, 4},
+		"v1.10":      {1, 10, 0},
+		"1.13.0-dev": {1, 13, 0},
+	} {
+		got, err := parseVersion(s)
+		if err != nil || got != want {
+			t.Errorf("parseVersion(%q) = %v, %v; want %v", s, got, err, want)
+		}
+	}
+	for _, bad := range []string{"", "1.x", "1.2.3.4"} {
+		if _, err := parseVersion(bad); err == nil {
+			t.Errorf("parseVersion(%q): expected an error", bad)
+		}
+	}
+}
+
+func TestRequire(t *testing.T) {
+	ctx := context.Background()
+	ds := &DocStore{versionFetched: true, versionKnown: true, version: version{1, 11, 2}}
+	if err := ds.require(ctx, FeatureQuery); err != nil {
+		t.Errorf("query API: %v", err)
+	}
+	err := ds.require(ctx, FeatureFacets)
+	if !errors.Is(err, ErrUnsupported) || !strings.Contains(err.Error(), "facets require Qdrant >= 1.12, server is 1.11.2") {
+		t.Errorf("got %v", err)
+	}
+	if ds.Supports(ctx, FeatureMatrix) {
+		t.Error("distance matrix supported by 1.11")
+	}
+
+	unknown := &DocStore{}
+	if !unknown.Supports(ctx, FeatureFormula) {
+		t.Error("features must be assumed supported when the version is unknown")
+	}
+}
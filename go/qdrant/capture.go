@@ -0,0 +1,108 @@
+package qdrant
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// CaptureRecord is a retrieval captured for building evaluation datasets.
+type CaptureRecord struct {
+	Time       time.Time        `json:"time"`
+	Collection string           `json:"collection"`
+	Query      string           `json:"query"`
+	Results    []CapturedResult `json:"results"`
+}
+
+// CapturedResult is a document returned by a captured retrieval.
+type CapturedResult struct {
+	ID    string  `json:"id"`
+	Score float32 `json:"score"`
+	Text  string  `json:"text"`
+}
+
+// CaptureSink receives captured retrievals. Errors are logged and do not
+// fail the retrieval.
+type CaptureSink interface {
+	Capture(ctx context.Context, rec *CaptureRecord) error
+}
+
+// JSONLCapture is a [CaptureSink] that appends records to a JSONL file.
+type JSONLCapture struct {
+	w *jsonlWriter
+}
+
+// NewJSONLCapture opens path for appending captured retrievals.
+func NewJSONLCapture(path string) (*JSONLCapture, error) {
+	w, err := openJSONL(path)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONLCapture{w: w}, nil
+}
+
+// Capture implements [CaptureSink].
+func (c *JSONLCapture) Capture(_ context.Context, rec *CaptureRecord) error {
+	return c.w.write(rec)
+}
+
+// Close closes the underlying file.
+func (c *JSONLCapture) Close() error {
+	return c.w.close()
+}
+
+// jsonlWriter appends JSON values to a file, one per line.
+type jsonlWriter struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+func openJSONL(path string) (*jsonlWriter, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonlWriter{f: f}, nil
+}
+
+func (w *jsonlWriter) write(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err = w.f.Write(append(b, '\n'))
+	return err
+}
+
+func (w *jsonlWriter) close() error {
+	return w.f.Close()
+}
+
+// captureRetrieval sends a retrieval to the configured capture sink.
+func (ds *DocStore) captureRetrieval(ctx context.Context, query *ai.Document, results []*result) {
+	if ds.capture == nil {
+		return
+	}
+	rec := &CaptureRecord{
+		Time:       time.Now(),
+		Collection: ds.collectionName,
+		Query:      documentText(query),
+	}
+	for _, r := range results {
+		rec.Results = append(rec.Results, CapturedResult{
+			ID:    r.id,
+			Score: r.score,
+			Text:  documentText(r.doc),
+		})
+	}
+	if err := ds.capture.Capture(ctx, rec); err != nil {
+		slog.Warn("qdrant retrieval capture failed", "collection", ds.collectionName, "err", err)
+	}
+}
@@ -0,0 +1,45 @@
+package qdrant
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJSONLCapture(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.jsonl")
+	c, err := NewJSONLCapture(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, q := range []string{"first", "second"} {
+		if err := c.Capture(ctx, &CaptureRecord{Query: q, Results: []CapturedResult{{ID: "1", Score: 0.5}}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var queries []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var rec CaptureRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			t.Fatal(err)
+		}
+		queries = append(queries, rec.Query)
+	}
+	if len(queries) != 2 || queries[0] != "first" || queries[1] != "second" {
+		t.Errorf("got queries %v", queries)
+	}
+}
@@ -0,0 +1,50 @@
+package qdrant
+
+import (
+	"maps"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+const defaultChunkSize = 1000
+
+// chunkIndexKey is the metadata key holding the position of a chunk
+// within its source document.
+const chunkIndexKey = "chunk_index"
+
+// ChunkOptions configures how documents are split before indexing.
+type ChunkOptions struct {
+	// Size is the maximum chunk length in characters. Defaults to 1000.
+	Size int
+	// Overlap is the number of characters shared by consecutive chunks.
+	Overlap int
+}
+
+// Chunk splits the text of doc into chunks. Each chunk carries a copy of
+// the document metadata plus its chunk index.
+func Chunk(doc *ai.Document, opts ChunkOptions) []*ai.Document {
+	size := opts.Size
+	if size <= 0 {
+		size = defaultChunkSize
+	}
+	overlap := opts.Overlap
+	if overlap < 0 || overlap >= size {
+		overlap = 0
+	}
+
+	text := []rune(documentText(doc))
+	var chunks []*ai.Document
+	for start := 0; start < len(text); start += size - overlap {
+		end := min(start+size, len(text))
+		metadata := maps.Clone(doc.Metadata)
+		if metadata == nil {
+			metadata = make(map[string]any)
+		}
+		metadata[chunkIndexKey] = len(chunks)
+		chunks = append(chunks, ai.DocumentFromText(string(text[start:end]), metadata))
+		if end == len(text) {
+			break
+		}
+	}
+	return chunks
+}
@@ -0,0 +1,28 @@
+package qdrant
+
+import (
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestChunk(t *testing.T) {
+	doc := ai.DocumentFromText("abcdefghij", map[string]any{"source": "x"})
+	chunks := Chunk(doc, ChunkOptions{Size: 4, Overlap: 1})
+
+	want := []string{"abcd", "defg", "ghij"}
+	if len(chunks) != len(want) {
+		t.Fatalf("got %d chunks, want %d", len(chunks), len(want))
+	}
+	for i, c := range chunks {
+		if got := documentText(c); got != want[i] {
+			t.Errorf("chunk %d: got %q, want %q", i, got, want[i])
+		}
+		if c.Metadata[chunkIndexKey] != i || c.Metadata["source"] != "x" {
+			t.Errorf("chunk %d: unexpected metadata %v", i, c.Metadata)
+		}
+	}
+	if _, ok := doc.Metadata[chunkIndexKey]; ok {
+		t.Error("chunking modified the source document metadata")
+	}
+}
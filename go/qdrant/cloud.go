@@ -0,0 +1,257 @@
+package qdrant
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// defaultCloudURL is the base URL of the Qdrant Cloud management API.
+const defaultCloudURL = "https://api.cloud.qdrant.io"
+
+// Phases of a Qdrant Cloud cluster.
+const (
+	cloudPhaseHealthy   = "CLUSTER_PHASE_HEALTHY"
+	cloudPhaseSuspended = "CLUSTER_PHASE_SUSPENDED"
+)
+
+// CloudProvisioner is a [Provisioner] using the Qdrant Cloud management
+// API. It looks up the cluster named Cluster in the account, creates it
+// if there is none and resumes it if it is suspended, waits for it to be
+// healthy and returns its endpoint.
+type CloudProvisioner struct {
+	// APIKey is a management key of the account, not a database key.
+	APIKey    string
+	AccountID string
+	// Cluster is the name of the cluster.
+	Cluster string
+	// CloudProvider, Region and PackageID select where and with which
+	// resources a missing cluster is created, e.g. "aws", "eu-central-1"
+	// and a package ID listed by the booking API. If CloudProvider is
+	// empty, a missing cluster is an error.
+	CloudProvider string
+	Region        string
+	PackageID     string
+	// Nodes is the number of nodes of a created cluster. Defaults to 1.
+	Nodes int
+	// DatabaseAPIKey authenticates the store with the cluster. If empty,
+	// a database key is created for the cluster at every Provision, so
+	// set it when provisioning repeatedly.
+	DatabaseAPIKey string
+	// BaseURL is the URL of the management API. Defaults to
+	// https://api.cloud.qdrant.io.
+	BaseURL string
+	// Client is used for all requests. Defaults to http.DefaultClient.
+	Client *http.Client
+	// PollInterval is the time between checks of the cluster while it
+	// starts. Defaults to 5s. The wait is bounded by the context.
+	PollInterval time.Duration
+}
+
+type cloudCluster struct {
+	ID    string `json:"id,omitempty"`
+	Name  string `json:"name"`
+	State *struct {
+		Phase    string `json:"phase"`
+		Endpoint *struct {
+			URL      string `json:"url"`
+			GrpcPort int    `json:"grpcPort"`
+		} `json:"endpoint"`
+	} `json:"state,omitempty"`
+}
+
+// Provision implements [Provisioner].
+func (p *CloudProvisioner) Provision(ctx context.Context) (*ClusterEndpoint, error) {
+	if p.APIKey == "" || p.AccountID == "" || p.Cluster == "" {
+		return nil, errors.New("qdrant cloud: APIKey, AccountID and Cluster are required")
+	}
+	cluster, err := p.findCluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if cluster == nil {
+		if cluster, err = p.createCluster(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if cloudPhase(cluster) == cloudPhaseSuspended {
+		if err := p.call(ctx, http.MethodPost, p.clusterPath(cluster.ID)+"/unsuspend", struct{}{}, nil); err != nil {
+			return nil, err
+		}
+	}
+	if cluster, err = p.waitHealthy(ctx, cluster.ID); err != nil {
+		return nil, err
+	}
+	return p.endpoint(ctx, cluster)
+}
+
+// findCluster returns the cluster named p.Cluster, or nil if there is none.
+func (p *CloudProvisioner) findCluster(ctx context.Context) (*cloudCluster, error) {
+	var resp struct {
+		Items []*cloudCluster `json:"items"`
+	}
+	if err := p.call(ctx, http.MethodGet, p.clusterPath(""), nil, &resp); err != nil {
+		return nil, err
+	}
+	for _, c := range resp.Items {
+		if c.Name == p.Cluster {
+			return c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (p *CloudProvisioner) createCluster(ctx context.Context) (*cloudCluster, error) {
+	if p.CloudProvider == "" {
+		return nil, fmt.Errorf("qdrant cloud: cluster %q does not exist and CloudProvider is not set", p.Cluster)
+	}
+	type configuration struct {
+		NumberOfNodes int    `json:"numberOfNodes"`
+		PackageID     string `json:"packageId"`
+	}
+	req := struct {
+		Cluster struct {
+			AccountID             string        `json:"accountId"`
+			Name                  string        `json:"name"`
+			CloudProviderID       string        `json:"cloudProviderId"`
+			CloudProviderRegionID string        `json:"cloudProviderRegionId"`
+			Configuration         configuration `json:"configuration"`
+		} `json:"cluster"`
+	}{}
+	req.Cluster.AccountID, req.Cluster.Name = p.AccountID, p.Cluster
+	req.Cluster.CloudProviderID, req.Cluster.CloudProviderRegionID = p.CloudProvider, p.Region
+	req.Cluster.Configuration = configuration{NumberOfNodes: cmp.Or(p.Nodes, 1), PackageID: p.PackageID}
+	var resp struct {
+		Cluster *cloudCluster `json:"cluster"`
+	}
+	if err := p.call(ctx, http.MethodPost, p.clusterPath(""), req, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Cluster == nil || resp.Cluster.ID == "" {
+		return nil, errors.New("qdrant cloud: cluster creation returned no cluster")
+	}
+	return resp.Cluster, nil
+}
+
+// waitHealthy polls the cluster until it is healthy or ctx is done.
+func (p *CloudProvisioner) waitHealthy(ctx context.Context, id string) (*cloudCluster, error) {
+	interval := p.PollInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	for {
+		var resp struct {
+			Cluster *cloudCluster `json:"cluster"`
+		}
+		if err := p.call(ctx, http.MethodGet, p.clusterPath(id), nil, &resp); err != nil {
+			return nil, err
+		}
+		if cloudPhase(resp.Cluster) == cloudPhaseHealthy {
+			return resp.Cluster, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("qdrant cloud: cluster %q is not healthy (phase %s): %w", p.Cluster, cloudPhase(resp.Cluster), ctx.Err())
+		case <-time.After(interval):
+		}
+	}
+}
+
+// endpoint returns the gRPC endpoint of a healthy cluster, creating a
+// database key if none is configured.
+func (p *CloudProvisioner) endpoint(ctx context.Context, cluster *cloudCluster) (*ClusterEndpoint, error) {
+	if cluster.State.Endpoint == nil || cluster.State.Endpoint.URL == "" {
+		return nil, fmt.Errorf("qdrant cloud: cluster %q has no endpoint", p.Cluster)
+	}
+	u, err := url.Parse(cluster.State.Endpoint.URL)
+	if err != nil || u.Hostname() == "" {
+		return nil, fmt.Errorf("qdrant cloud: cluster %q has an invalid endpoint %q", p.Cluster, cluster.State.Endpoint.URL)
+	}
+	key := p.DatabaseAPIKey
+	if key == "" {
+		req := map[string]any{"databaseApiKey": map[string]any{
+			"accountId":  p.AccountID,
+			"clusterIds": []string{cluster.ID},
+			"name":       "genkitx-qdrant",
+		}}
+		var resp struct {
+			DatabaseAPIKey struct {
+				Key string `json:"key"`
+			} `json:"databaseApiKey"`
+		}
+		if err := p.call(ctx, http.MethodPost, "/api/auth/v2/accounts/"+url.PathEscape(p.AccountID)+"/database-api-keys", req, &resp); err != nil {
+			return nil, err
+		}
+		if key = resp.DatabaseAPIKey.Key; key == "" {
+			return nil, errors.New("qdrant cloud: database key creation returned no key")
+		}
+	}
+	return &ClusterEndpoint{
+		Host:   u.Hostname(),
+		Port:   cmp.Or(cluster.State.Endpoint.GrpcPort, 6334),
+		APIKey: key,
+		UseTLS: u.Scheme != "http",
+	}, nil
+}
+
+func (p *CloudProvisioner) clusterPath(id string) string {
+	path := "/api/cluster/v1/accounts/" + url.PathEscape(p.AccountID) + "/clusters"
+	if id != "" {
+		path += "/" + url.PathEscape(id)
+	}
+	return path
+}
+
+// call sends a request with the JSON encoding of body, if not nil, and
+// decodes the response into out, if not nil.
+func (p *CloudProvisioner) call(ctx context.Context, method, path string, body, out any) error {
+	var r io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(cmp.Or(p.BaseURL, defaultCloudURL), "/")+path, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "apikey "+p.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("qdrant cloud: %s %s failed: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("qdrant cloud: %s %s failed: %s: %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("qdrant cloud: invalid response to %s %s: %v", method, path, err)
+	}
+	return nil
+}
+
+func cloudPhase(c *cloudCluster) string {
+	if c == nil || c.State == nil {
+		return ""
+	}
+	return c.State.Phase
+}
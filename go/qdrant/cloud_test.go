@@ -0,0 +1,63 @@
+package qdrant
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCloudProvisioner(t *testing.T) {
+	var calls []string
+	polls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "apikey mgmt" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/cluster/v1/accounts/acc/clusters":
+			w.Write([]byte(`{"items": [{"id": "other", "name": "prod"}, {"id": "c1", "name": "preview", "state": {"phase": "CLUSTER_PHASE_SUSPENDED"}}]}`))
+		case "POST /api/cluster/v1/accounts/acc/clusters/c1/unsuspend":
+			w.Write([]byte(`{}`))
+		case "GET /api/cluster/v1/accounts/acc/clusters/c1":
+			if polls++; polls == 1 {
+				w.Write([]byte(`{"cluster": {"id": "c1", "name": "preview", "state": {"phase": "CLUSTER_PHASE_RESUMING"}}}`))
+				return
+			}
+			w.Write([]byte(`{"cluster": {"id": "c1", "name": "preview", "state": {"phase": "CLUSTER_PHASE_HEALTHY", "endpoint": {"url": "https://c1.eu-central.aws.cloud.qdrant.io", "grpcPort": 6334}}}}`))
+		case "POST /api/auth/v2/accounts/acc/database-api-keys":
+			var req map[string]map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req["databaseApiKey"]["clusterIds"].([]any)[0] != "c1" {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"databaseApiKey": {"key": "db-key"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	p := &CloudProvisioner{APIKey: "mgmt", AccountID: "acc", Cluster: "preview", BaseURL: srv.URL, PollInterval: 1}
+	ep, err := p.Provision(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ep.Host != "c1.eu-central.aws.cloud.qdrant.io" || ep.Port != 6334 || ep.APIKey != "db-key" || !ep.UseTLS {
+		t.Errorf("got %+v", ep)
+	}
+	if len(calls) != 5 || calls[1] != "POST /api/cluster/v1/accounts/acc/clusters/c1/unsuspend" {
+		t.Errorf("got calls %v", calls)
+	}
+
+	// A missing cluster is only created with a cloud provider.
+	p.Cluster = "missing"
+	if _, err := p.Provision(context.Background()); err == nil {
+		t.Error("expected an error for a missing cluster without CloudProvider")
+	}
+}
@@ -0,0 +1,125 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// ensureCollection creates the collection if it does not exist when
+// Config.AutoCreate is set, with the vector size returned by size. It
+// runs at Init and again before the index requests following a
+// [CollectionManager.Delete] of the collection.
+// Concurrent callers share a single creation: they wait for the
+// one in flight instead of racing it into "already exists" errors.
+func (ds *DocStore) ensureCollection(ctx context.Context, size func(context.Context) (int, error)) error {
+	if !ds.autoCreate {
+		return nil
+	}
+	ds.createMu.Lock()
+	defer ds.createMu.Unlock()
+	if ds.collectionReady {
+		return nil
+	}
+
+	exists, err := ds.client.CollectionExists(ctx, ds.collectionName)
+	if err != nil {
+		return fmt.Errorf("qdrant failed to check collection: %v", err)
+	}
+	if !exists {
+		n, err := size(ctx)
+		if err != nil {
+			return err
+		}
+		params := &qclient.VectorParams{Size: uint64(n), Distance: ds.distance}
+		vectors := vectorsConfig(ds.vectorName(), params)
+		if len(ds.namedEmbedders) > 0 || ds.multiVector != nil {
+			named := map[string]*qclient.VectorParams{ds.vectorName(): params}
+			if err := ds.namedVectorsConfig(ctx, named); err != nil {
+				return err
+			}
+			if ds.multiVector != nil {
+				if err := ds.require(ctx, FeatureMultivector); err != nil {
+					return err
+				}
+				if named[ds.multiVector.Name], err = ds.multiVector.vectorParams(ctx, ds.distance); err != nil {
+					return err
+				}
+			}
+			vectors = qclient.NewVectorsConfigMap(named)
+		}
+		err = ds.client.CreateCollection(ctx, &qclient.CreateCollection{
+			CollectionName:      ds.collectionName,
+			VectorsConfig:       vectors,
+			SparseVectorsConfig: ds.hybrid.sparseVectorsConfig(),
+			HnswConfig:          ds.hnsw.diff(),
+		})
+		if err != nil {
+			// Another process may have created it in the meantime.
+			if exists, eerr := ds.client.CollectionExists(ctx, ds.collectionName); eerr != nil || !exists {
+				return fmt.Errorf("qdrant failed to create collection: %v", err)
+			}
+		}
+	}
+	if err := ds.createIndexes(ctx); err != nil {
+		return err
+	}
+	ds.collectionReady = true
+	return nil
+}
+
+// createIndexes creates the payload indexes the store relies on.
+func (ds *DocStore) createIndexes(ctx context.Context) error {
+	if err := ds.createPayloadIndexes(ctx, ds.payloadIndexes); err != nil {
+		return err
+	}
+	if ds.namespace != "" {
+		if err := ds.createNamespaceIndex(ctx); err != nil {
+			return err
+		}
+	}
+	if ds.environment != "" {
+		if err := ds.createEnvIndex(ctx); err != nil {
+			return err
+		}
+	}
+	if ds.tenantKey != "" {
+		if err := ds.createTenantIndex(ctx); err != nil {
+			return err
+		}
+	}
+	if ds.entityModel != nil {
+		return ds.createEntityIndex(ctx)
+	}
+	return nil
+}
+
+// probeText is embedded to find the vector size of the embedder.
+const probeText = "vector size probe"
+
+// probeVectorSize returns the size of the vectors of the embedder, or
+// Config.VectorSize if set.
+func (ds *DocStore) probeVectorSize(ctx context.Context) (int, error) {
+	if ds.vectorSize > 0 {
+		return ds.vectorSize, nil
+	}
+	return probeSize(ctx, ds.embedder, ds.embedderOptions)
+}
+
+// probeSize returns the size of the vectors of an embedder.
+func probeSize(ctx context.Context, embedder ai.Embedder, options any) (int, error) {
+	resp, err := embedder.Embed(ctx, &ai.EmbedRequest{
+		Documents: []*ai.Document{ai.DocumentFromText(probeText, nil)},
+		Options:   options,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("qdrant probe embedding failed: %v", err)
+	}
+	if len(resp.Embeddings) != 1 || len(resp.Embeddings[0].Embedding) == 0 {
+		return 0, errors.New("qdrant probe embedding failed: embedder returned no vector")
+	}
+	return len(resp.Embeddings[0].Embedding), nil
+}
@@ -0,0 +1,30 @@
+package qdrant
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEnsureCollectionSkipsClient(t *testing.T) {
+	// Neither store has a client: ensureCollection must not reach Qdrant.
+	for _, ds := range []*DocStore{
+		{},
+		{autoCreate: true, collectionReady: true},
+	} {
+		size := func(context.Context) (int, error) { return 3, nil }
+		if err := ds.ensureCollection(context.Background(), size); err != nil {
+			t.Errorf("ensureCollection: %v", err)
+		}
+	}
+}
+
+func TestProbeVectorSize(t *testing.T) {
+	ds := &DocStore{vectorSize: 768, embedder: failingEmbedder{}}
+	if n, err := ds.probeVectorSize(context.Background()); err != nil || n != 768 {
+		t.Errorf("got %d, %v, want the configured size", n, err)
+	}
+	ds.vectorSize = 0
+	if n, err := ds.probeVectorSize(context.Background()); err != nil || n != 1 {
+		t.Errorf("got %d, %v, want the size of the probe embedding", n, err)
+	}
+}
@@ -0,0 +1,111 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// CollectionManager creates, inspects and deletes collections with the
+// connection of a store, so that applications can manage their lifecycle
+// without a second Qdrant client. Creating and deleting collections is
+// rejected with an error wrapping [ErrReadOnly] if the store, or the store
+// of the collection, is read-only.
+type CollectionManager struct {
+	ds     *DocStore
+	client *qclient.Client
+}
+
+// Collections returns the collection manager using the connection of the
+// store with the given collection name, or nil if [Init] was not called
+// for it.
+func Collections(name string) *CollectionManager {
+	ds := Store(name)
+	if ds == nil {
+		return nil
+	}
+	return &CollectionManager{ds: ds, client: ds.client}
+}
+
+// writable returns an error wrapping [ErrReadOnly] if the manager or the
+// store of the collection with the given name is read-only.
+func (m *CollectionManager) writable(op, name string) error {
+	if err := m.ds.writable(op); err != nil {
+		return err
+	}
+	if ds := Store(name); ds != nil {
+		return ds.writable(op)
+	}
+	return nil
+}
+
+// Create creates a collection.
+func (m *CollectionManager) Create(ctx context.Context, req *qclient.CreateCollection) error {
+	if req.GetCollectionName() == "" {
+		return errors.New("qdrant: empty collection name")
+	}
+	if err := m.writable("collection creation", req.GetCollectionName()); err != nil {
+		return err
+	}
+	if err := m.client.CreateCollection(ctx, req); err != nil {
+		return fmt.Errorf("qdrant failed to create collection: %v", err)
+	}
+	return nil
+}
+
+// Delete deletes a collection and its points. A store configured for the
+// collection creates it again on its next index request if
+// Config.AutoCreate is set.
+func (m *CollectionManager) Delete(ctx context.Context, name string) error {
+	if err := m.writable("collection deletion", name); err != nil {
+		return err
+	}
+	if err := m.client.DeleteCollection(ctx, name); err != nil {
+		return fmt.Errorf("qdrant failed to delete collection: %v", err)
+	}
+	if ds := Store(name); ds != nil {
+		ds.forgetCollection()
+	}
+	return nil
+}
+
+// Exists reports whether a collection exists.
+func (m *CollectionManager) Exists(ctx context.Context, name string) (bool, error) {
+	exists, err := m.client.CollectionExists(ctx, name)
+	if err != nil {
+		return false, fmt.Errorf("qdrant failed to check collection: %v", err)
+	}
+	return exists, nil
+}
+
+// Info returns the configuration and statistics of a collection.
+func (m *CollectionManager) Info(ctx context.Context, name string) (*qclient.CollectionInfo, error) {
+	info, err := m.client.GetCollectionInfo(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("qdrant failed to fetch collection info: %v", err)
+	}
+	return info, nil
+}
+
+// List returns the names of all collections.
+func (m *CollectionManager) List(ctx context.Context) ([]string, error) {
+	names, err := m.client.ListCollections(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("qdrant failed to list collections: %v", err)
+	}
+	return names, nil
+}
+
+// forgetCollection drops what the store knows about its collection after
+// it was deleted.
+func (ds *DocStore) forgetCollection() {
+	ds.createMu.Lock()
+	ds.collectionReady = false
+	ds.createMu.Unlock()
+	ds.shardMu.Lock()
+	ds.knownShards = nil
+	ds.shardMu.Unlock()
+	ds.schema.invalidate()
+}
@@ -0,0 +1,52 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestCollections(t *testing.T) {
+	if m := Collections("not-initialized"); m != nil {
+		t.Errorf("got %v for a collection without a store", m)
+	}
+	m := &CollectionManager{ds: &DocStore{}}
+	if err := m.Create(context.Background(), &qclient.CreateCollection{}); err == nil {
+		t.Error("expected an error for an empty collection name")
+	}
+}
+
+func TestForgetCollection(t *testing.T) {
+	ds := &DocStore{collectionReady: true, knownShards: map[string]bool{"a": true}, schema: newSchemaCache(0)}
+	ds.forgetCollection()
+	if ds.collectionReady || ds.knownShards != nil {
+		t.Errorf("store still knows its collection: ready %v, shards %v", ds.collectionReady, ds.knownShards)
+	}
+}
+
+func TestCollectionsReadOnly(t *testing.T) {
+	ctx := context.Background()
+	m := &CollectionManager{ds: &DocStore{readOnly: true}}
+	if err := m.Delete(ctx, "docs"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Delete: got %v, want ErrReadOnly", err)
+	}
+	if err := m.Create(ctx, &qclient.CreateCollection{CollectionName: "docs"}); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Create: got %v, want ErrReadOnly", err)
+	}
+
+	// The collection of a read-only store is protected from other stores.
+	mu.Lock()
+	stores["read-only-docs"] = &DocStore{collectionName: "read-only-docs", readOnly: true}
+	mu.Unlock()
+	defer func() {
+		mu.Lock()
+		delete(stores, "read-only-docs")
+		mu.Unlock()
+	}()
+	m = &CollectionManager{ds: &DocStore{}}
+	if err := m.Delete(ctx, "read-only-docs"); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Delete: got %v, want ErrReadOnly", err)
+	}
+}
@@ -0,0 +1,57 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+const condensePrompt = `Rewrite the last question of the conversation below as a standalone question that can be understood without the conversation. Keep names and details it refers to. Write only the question.
+
+Conversation:
+%s
+Last question: %s`
+
+// condenseQuery rewrites a follow-up question into a standalone query
+// using the conversation history and Config.CondenseModel, so that a
+// question like "and how do I delete it?" retrieves documents about the
+// subject of the conversation.
+func (ds *DocStore) condenseQuery(ctx context.Context, history []*ai.Message, doc *ai.Document) (*ai.Document, error) {
+	if len(history) == 0 {
+		return doc, nil
+	}
+	if ds.condenseModel == nil {
+		return nil, errors.New("qdrant: RetrieverOptions.History requires Config.CondenseModel")
+	}
+	prompt := fmt.Sprintf(condensePrompt, formatHistory(history), documentText(doc))
+	out, err := ai.GenerateText(ctx, ds.condenseModel, ai.WithTextPrompt(prompt))
+	if err != nil {
+		return nil, fmt.Errorf("qdrant query condensing failed: %v", err)
+	}
+	out = strings.TrimSpace(out)
+	if out == "" {
+		return doc, nil
+	}
+	return ai.DocumentFromText(out, doc.Metadata), nil
+}
+
+// formatHistory writes the text of messages one per line, prefixed with
+// their role.
+func formatHistory(history []*ai.Message) string {
+	var sb strings.Builder
+	for _, m := range history {
+		var text strings.Builder
+		for _, p := range m.Content {
+			if p.IsText() {
+				text.WriteString(p.Text)
+			}
+		}
+		if t := strings.TrimSpace(text.String()); t != "" {
+			fmt.Fprintf(&sb, "%s: %s\n", m.Role, t)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,32 @@
+package qdrant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestFormatHistory(t *testing.T) {
+	got := formatHistory([]*ai.Message{
+		ai.NewUserTextMessage("How do I create a collection?"),
+		ai.NewModelTextMessage("Call CreateCollection. "),
+		ai.NewUserMessage(ai.NewMediaPart("image/png", "data:,")),
+	})
+	want := "user: How do I create a collection?\nmodel: Call CreateCollection.\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCondenseQueryWithoutModel(t *testing.T) {
+	ds := &DocStore{}
+	doc := ai.DocumentFromText("and how do I delete it?", nil)
+	if got, err := ds.condenseQuery(context.Background(), nil, doc); err != nil || got != doc {
+		t.Errorf("got %v, %v without history, want the query unchanged", got, err)
+	}
+	history := []*ai.Message{ai.NewUserTextMessage("How do I create a collection?")}
+	if _, err := ds.condenseQuery(context.Background(), history, doc); err == nil {
+		t.Error("expected an error without Config.CondenseModel")
+	}
+}
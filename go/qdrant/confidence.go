@@ -0,0 +1,61 @@
+package qdrant
+
+import "github.com/firebase/genkit/go/ai"
+
+// Metadata keys of the confidence indicator attached by the retriever to
+// every returned document.
+const (
+	// TopScoreKey holds the score of the best result.
+	TopScoreKey = "_top_score"
+	// ScoreGapKey holds the difference between the scores of the best and
+	// second best results, or the top score if there is a single result.
+	ScoreGapKey = "_score_gap"
+	// AboveThresholdKey holds the number of results scoring at least
+	// RetrieverOptions.ConfidenceThreshold.
+	AboveThresholdKey = "_above_threshold"
+)
+
+// Confidence is a simple indicator of how well a retrieval matched, so
+// that a flow can decide whether to answer, ask a clarifying question or
+// fall back to another source. A low top score or few results above the
+// threshold suggest the collection does not cover the query; a small gap
+// suggests the query is ambiguous.
+type Confidence struct {
+	TopScore       float32
+	ScoreGap       float32
+	AboveThreshold int
+}
+
+// confidence computes the indicator of results sorted by descending score.
+func confidence(results []*result, threshold float32) Confidence {
+	var c Confidence
+	if len(results) == 0 {
+		return c
+	}
+	c.TopScore = results[0].score
+	c.ScoreGap = c.TopScore
+	if len(results) > 1 {
+		c.ScoreGap = c.TopScore - results[1].score
+	}
+	for _, r := range results {
+		if r.score >= threshold {
+			c.AboveThreshold++
+		}
+	}
+	return c
+}
+
+// ConfidenceFromResponse returns the confidence indicator of a retrieval.
+// It is zero if no document was retrieved. Like [ResultsFromResponse], it
+// accepts responses that went through JSON.
+func ConfidenceFromResponse(resp *ai.RetrieverResponse) Confidence {
+	if resp == nil || len(resp.Documents) == 0 {
+		return Confidence{}
+	}
+	md := resp.Documents[0].Metadata
+	return Confidence{
+		TopScore:       float32(metadataNumber(md, TopScoreKey)),
+		ScoreGap:       float32(metadataNumber(md, ScoreGapKey)),
+		AboveThreshold: int(metadataNumber(md, AboveThresholdKey)),
+	}
+}
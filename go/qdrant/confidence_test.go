@@ -0,0 +1,41 @@
+package qdrant
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestConfidence(t *testing.T) {
+	results := []*result{
+		{id: "a", score: 0.9, doc: ai.DocumentFromText("a", nil)},
+		{id: "b", score: 0.6, doc: ai.DocumentFromText("b", nil)},
+		{id: "c", score: 0.4, doc: ai.DocumentFromText("c", nil)},
+	}
+	c := confidence(results, 0.5)
+	if c.TopScore != 0.9 || c.AboveThreshold != 2 || c.ScoreGap < 0.29 || c.ScoreGap > 0.31 {
+		t.Errorf("got %+v", c)
+	}
+	if got := confidence(results[:1], 0.5); got.ScoreGap != 0.9 {
+		t.Errorf("got gap %v for a single result, want the top score", got.ScoreGap)
+	}
+
+	for _, r := range results {
+		r.annotate(retrievalInfo{available: -1, confidence: c})
+	}
+	b, err := json.Marshal(&ai.RetrieverResponse{Documents: []*ai.Document{results[0].doc}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp ai.RetrieverResponse
+	if err := json.Unmarshal(b, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if got := ConfidenceFromResponse(&resp); got != c {
+		t.Errorf("got %+v from the response, want %+v", got, c)
+	}
+	if got := ConfidenceFromResponse(&ai.RetrieverResponse{}); got != (Confidence{}) {
+		t.Errorf("got %+v for an empty response", got)
+	}
+}
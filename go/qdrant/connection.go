@@ -0,0 +1,179 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/grpc"
+)
+
+// ConnectionConfig sizes the gRPC connections of a [DocStore]. The zero
+// value uses a single channel with no limit on requests in flight.
+type ConnectionConfig struct {
+	// Channels is the number of gRPC channels queries and upserts are
+	// spread over. A single HTTP/2 connection caps the number of
+	// concurrent streams, so busy retrievers over slow links benefit
+	// from more.
+	Channels int
+	// MaxInFlight limits the number of concurrent requests to Qdrant.
+	// Requests beyond the limit wait for a free slot. Zero is unlimited.
+	MaxInFlight int
+	// AutoTune measures the latency to Qdrant at Init and chooses
+	// Channels and MaxInFlight from it, unless they are set explicitly.
+	// The chosen values are logged.
+	AutoTune bool
+}
+
+// probeCount is the number of health checks used to measure latency.
+const probeCount = 5
+
+// tuneConnection returns the channel count and in-flight limit for a
+// round-trip latency. Keeping a given throughput requires more
+// concurrent requests as latency grows, and more channels once they
+// exceed what one connection carries well.
+func tuneConnection(latency time.Duration) (channels, maxInFlight int) {
+	switch {
+	case latency < 2*time.Millisecond:
+		return 1, 32
+	case latency < 20*time.Millisecond:
+		return 2, 64
+	default:
+		return 4, 128
+	}
+}
+
+// probeLatency returns the median duration of a few health checks.
+func probeLatency(ctx context.Context, client *qclient.Client) (time.Duration, error) {
+	samples := make([]time.Duration, probeCount)
+	for i := range samples {
+		start := time.Now()
+		if _, err := client.HealthCheck(ctx); err != nil {
+			return 0, fmt.Errorf("qdrant latency probe failed: %v", err)
+		}
+		samples[i] = time.Since(start)
+	}
+	slices.Sort(samples)
+	return samples[len(samples)/2], nil
+}
+
+// inFlightLimiter bounds the number of concurrent gRPC calls. Its limit
+// is set once the connection is tuned; until then calls are not limited.
+type inFlightLimiter struct {
+	slots chan struct{}
+}
+
+// unaryInterceptor implements [grpc.UnaryClientInterceptor].
+func (l *inFlightLimiter) unaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+			defer func() { <-l.slots }()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// connect creates the clients of a store, tuning the connection first if
+// requested. The first client is used for everything but queries and
+// upserts, which are spread over all of them.
+func connect(ctx context.Context, cfg *qclient.Config, conn ConnectionConfig) ([]*qclient.Client, error) {
+	limiter := &inFlightLimiter{}
+	cfg.GrpcOptions = append(cfg.GrpcOptions, grpc.WithChainUnaryInterceptor(limiter.unaryInterceptor))
+	client, err := qclient.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	channels, maxInFlight := conn.Channels, conn.MaxInFlight
+	if conn.AutoTune {
+		latency, err := probeLatency(ctx, client)
+		if err != nil {
+			client.Close()
+			return nil, err
+		}
+		tunedChannels, tunedInFlight := tuneConnection(latency)
+		if channels == 0 {
+			channels = tunedChannels
+		}
+		if maxInFlight == 0 {
+			maxInFlight = tunedInFlight
+		}
+		slog.InfoContext(ctx, "qdrant connection tuned", "latency", latency, "channels", channels, "maxInFlight", maxInFlight)
+	}
+	if maxInFlight > 0 {
+		limiter.slots = make(chan struct{}, maxInFlight)
+	}
+
+	clients := []*qclient.Client{client}
+	for len(clients) < channels {
+		c, err := qclient.NewClient(cfg)
+		if err != nil {
+			for _, c := range clients {
+				c.Close()
+			}
+			return nil, err
+		}
+		clients = append(clients, c)
+	}
+	return clients, nil
+}
+
+// dataClient returns the client for the next query or upsert, rotating
+// over the channels of the store.
+func (ds *DocStore) dataClient() *qclient.Client {
+	if len(ds.pool) < 2 {
+		return ds.client
+	}
+	return ds.pool[ds.poolNext.Add(1)%uint64(len(ds.pool))]
+}
+
+// Default ports of Qdrant. The REST port is the one found in URLs copied
+// from the Qdrant Cloud console.
+const (
+	restPort = 6333
+	grpcPort = 6334
+)
+
+// applyURL sets the host, port and TLS settings of cfg from Config.Url.
+// The plugin speaks gRPC, so a URL with the REST port 6333 connects to the
+// gRPC port 6334 of the same host.
+func applyURL(cfg *Config) error {
+	if cfg.Url == "" {
+		return nil
+	}
+	u, err := url.Parse(cfg.Url)
+	if err != nil {
+		return fmt.Errorf("qdrant: invalid Config.Url: %v", err)
+	}
+	switch u.Scheme {
+	case "https":
+		cfg.UseTls = true
+	case "http":
+		cfg.UseTls = false
+	default:
+		return fmt.Errorf("qdrant: Config.Url %q must use http or https", cfg.Url)
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("qdrant: Config.Url %q has no host", cfg.Url)
+	}
+	cfg.GrpcHost = u.Hostname()
+	cfg.Port = grpcPort
+	if p := u.Port(); p != "" {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return fmt.Errorf("qdrant: invalid port in Config.Url %q", cfg.Url)
+		}
+		if n != restPort {
+			cfg.Port = n
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func TestTuneConnection(t *testing.T) {
+	for _, tc := range []struct {
+		latency            time.Duration
+		channels, inFlight int
+	}{
+		{500 * time.Microsecond, 1, 32},
+		{5 * time.Millisecond, 2, 64},
+		{80 * time.Millisecond, 4, 128},
+	} {
+		channels, inFlight := tuneConnection(tc.latency)
+		if channels != tc.channels || inFlight != tc.inFlight {
+			t.Errorf("tuneConnection(%v) = %d, %d, want %d, %d", tc.latency, channels, inFlight, tc.channels, tc.inFlight)
+		}
+	}
+}
+
+func TestInFlightLimiter(t *testing.T) {
+	l := &inFlightLimiter{slots: make(chan struct{}, 1)}
+	release := make(chan struct{})
+	started := make(chan struct{})
+	go l.unaryInterceptor(context.Background(), "m", nil, nil, nil, func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := l.unaryInterceptor(ctx, "m", nil, nil, nil, func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		t.Error("call ran beyond the limit")
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want deadline exceeded", err)
+	}
+	close(release)
+}
+
+func TestApplyURL(t *testing.T) {
+	for _, tc := range []struct {
+		url  string
+		host string
+		port int
+		tls  bool
+	}{
+		{"https://xyz.cloud.qdrant.io:6333", "xyz.cloud.qdrant.io", 6334, true},
+		{"https://xyz.cloud.qdrant.io", "xyz.cloud.qdrant.io", 6334, true},
+		{"http://localhost:7334", "localhost", 7334, false},
+	} {
+		cfg := Config{Url: tc.url, UseTls: !tc.tls}
+		if err := applyURL(&cfg); err != nil {
+			t.Fatalf("%s: %v", tc.url, err)
+		}
+		if cfg.GrpcHost != tc.host || cfg.Port != tc.port || cfg.UseTls != tc.tls {
+			t.Errorf("%s: got %s:%d tls %v", tc.url, cfg.GrpcHost, cfg.Port, cfg.UseTls)
+		}
+	}
+	for _, bad := range []string{"xyz.cloud.qdrant.io:6333", "grpc://host", "https://", "https://host:port"} {
+		if err := applyURL(&Config{Url: bad}); err == nil {
+			t.Errorf("%s: expected an error", bad)
+		}
+	}
+}
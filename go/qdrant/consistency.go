@@ -0,0 +1,32 @@
+package qdrant
+
+import (
+	"fmt"
+	"strconv"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// readConsistencyTypes maps the named values of
+// RetrieverOptions.Consistency to Qdrant read consistencies.
+var readConsistencyTypes = map[string]qclient.ReadConsistencyType{
+	"all":      qclient.ReadConsistencyType_All,
+	"majority": qclient.ReadConsistencyType_Majority,
+	"quorum":   qclient.ReadConsistencyType_Quorum,
+}
+
+// readConsistency parses a value of RetrieverOptions.Consistency. It
+// returns nil for the empty string, leaving the default of Qdrant.
+func readConsistency(s string) (*qclient.ReadConsistency, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if t, ok := readConsistencyTypes[s]; ok {
+		return qclient.NewReadConsistencyType(t), nil
+	}
+	factor, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || factor == 0 {
+		return nil, fmt.Errorf("consistency must be all, majority, quorum or a positive number of replicas, got %q", s)
+	}
+	return qclient.NewReadConsistencyFactor(factor), nil
+}
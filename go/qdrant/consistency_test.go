@@ -0,0 +1,26 @@
+package qdrant
+
+import (
+	"testing"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestReadConsistency(t *testing.T) {
+	if rc, err := readConsistency(""); rc != nil || err != nil {
+		t.Errorf("got %v, %v for the default consistency", rc, err)
+	}
+	rc, err := readConsistency("quorum")
+	if err != nil || rc.GetType() != qclient.ReadConsistencyType_Quorum {
+		t.Errorf("got %v, %v, want quorum", rc, err)
+	}
+	rc, err = readConsistency("2")
+	if err != nil || rc.GetFactor() != 2 {
+		t.Errorf("got %v, %v, want a factor of 2", rc, err)
+	}
+	for _, bad := range []string{"0", "-1", "strong"} {
+		if _, err := readConsistency(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// CountOptions configures [DocStore.Count].
+type CountOptions struct {
+	// Tenant restricts the count to the documents of a tenant, as
+	// RetrieverOptions.Tenant does. It is required when Config.TenantKey
+	// is set.
+	Tenant string
+}
+
+// Count returns the number of stored documents matching filter, which may
+// be nil, without retrieving them. Like retrieval, it only counts the
+// points of the namespace and environment of the store.
+func (ds *DocStore) Count(ctx context.Context, filter *qclient.Filter, opts *CountOptions) (uint64, error) {
+	req, err := ds.countRequest(filter, opts)
+	if err != nil {
+		return 0, err
+	}
+	n, err := ds.client.Count(ctx, req)
+	if err != nil {
+		return 0, fmt.Errorf("qdrant count failed: %v", err)
+	}
+	return n, nil
+}
+
+// countRequest returns the exact count request of Count.
+func (ds *DocStore) countRequest(filter *qclient.Filter, opts *CountOptions) (*qclient.CountPoints, error) {
+	if filter == nil {
+		filter = &qclient.Filter{}
+	}
+	if opts == nil {
+		opts = &CountOptions{}
+	}
+	req := &qclient.CountPoints{
+		CollectionName: ds.collectionName,
+		Exact:          qclient.PtrOf(true),
+	}
+	var err error
+	if opts.Tenant != "" && (ds.shardPerTenant || ds.tenantKey == "") {
+		if req.ShardKeySelector, err = ds.tenantShardKey(opts.Tenant); err != nil {
+			return nil, err
+		}
+	}
+	if filter, err = ds.tenantFilter(filter, opts.Tenant); err != nil {
+		return nil, err
+	}
+	req.Filter = ds.scopeFilter(ds.envFilter(filter, &RetrieverOptions{}))
+	return req, nil
+}
@@ -0,0 +1,45 @@
+package qdrant
+
+import (
+	"testing"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCountRequest(t *testing.T) {
+	filter := &qclient.Filter{Must: []*qclient.Condition{qclient.NewMatchKeyword("lang", "en")}}
+
+	// Tenants partitioned by payload are matched in the filter.
+	ds := &DocStore{collectionName: "docs", tenantKey: "tenant"}
+	if _, err := ds.countRequest(filter, nil); err == nil {
+		t.Error("expected an error for a missing tenant")
+	}
+	req, err := ds.countRequest(filter, &CountOptions{Tenant: "acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &qclient.Filter{Must: []*qclient.Condition{
+		qclient.NewMatchKeyword("tenant", "acme"),
+		qclient.NewFilterAsCondition(filter),
+	}}
+	if !proto.Equal(req.GetFilter(), want) || !req.GetExact() || req.GetShardKeySelector() != nil {
+		t.Errorf("got %v, want the filter %v", req, want)
+	}
+
+	// Tenants stored per shard key are selected by shard key.
+	ds = &DocStore{collectionName: "docs", shardPerTenant: true}
+	if req, err = ds.countRequest(filter, &CountOptions{Tenant: "acme"}); err != nil {
+		t.Fatal(err)
+	}
+	if req.GetFilter() != filter || len(req.GetShardKeySelector().GetShardKeys()) != 1 ||
+		req.GetShardKeySelector().GetShardKeys()[0].GetKeyword() != "acme" {
+		t.Errorf("got %v", req)
+	}
+
+	// A tenant needs a tenant partitioning.
+	ds = &DocStore{collectionName: "docs"}
+	if _, err := ds.countRequest(nil, &CountOptions{Tenant: "acme"}); err == nil {
+		t.Error("expected an error for a tenant without ShardPerTenant or TenantKey")
+	}
+}
@@ -0,0 +1,249 @@
+package qdrant
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// titleKey is the metadata key holding the title of a crawled page.
+const titleKey = "title"
+
+// Crawler fetches web pages and converts them to documents, so that a
+// collection can be bootstrapped from a documentation site. Links are only
+// followed within the hosts of the start pages.
+type Crawler struct {
+	// Client is used for all requests. Defaults to http.DefaultClient.
+	Client *http.Client
+	// MaxDepth is the number of links followed from the start pages.
+	// Zero fetches only the start pages.
+	MaxDepth int
+	// MaxPages limits the number of pages fetched, including the pages
+	// that fail to load or are not HTML. Defaults to 100.
+	MaxPages int
+}
+
+// Crawl fetches the start pages and the pages they link to, up to
+// MaxDepth links away, and returns one document per HTML page. Each
+// document records its URL under the "source" metadata key and its title
+// under "title". Pages that fail to load are logged and skipped.
+func (c *Crawler) Crawl(ctx context.Context, start ...string) ([]*ai.Document, error) {
+	maxPages := c.MaxPages
+	if maxPages <= 0 {
+		maxPages = 100
+	}
+	hosts := make(map[string]bool)
+	seen := make(map[string]bool)
+	var queue []string
+	for _, s := range start {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, fmt.Errorf("qdrant crawl: invalid URL %q: %v", s, err)
+		}
+		u.Fragment = ""
+		hosts[u.Host] = true
+		if !seen[u.String()] {
+			seen[u.String()] = true
+			queue = append(queue, u.String())
+		}
+	}
+
+	var docs []*ai.Document
+	fetched := 0
+	for depth := 0; depth <= c.MaxDepth && len(queue) > 0; depth++ {
+		var next []string
+		for _, page := range queue {
+			if fetched == maxPages {
+				return docs, nil
+			}
+			if err := ctx.Err(); err != nil {
+				return docs, err
+			}
+			fetched++
+			title, text, links, err := c.fetch(ctx, page)
+			if err != nil {
+				slog.WarnContext(ctx, "qdrant crawl skipped page", "url", page, "error", err)
+				continue
+			}
+			if text != "" {
+				docs = append(docs, ai.DocumentFromText(text, map[string]any{sourceKey: page, titleKey: title}))
+			}
+			for _, l := range links {
+				u, err := url.Parse(page)
+				if err != nil {
+					continue
+				}
+				ref, err := u.Parse(l)
+				if err != nil || (ref.Scheme != "http" && ref.Scheme != "https") || !hosts[ref.Host] {
+					continue
+				}
+				ref.Fragment = ""
+				if !seen[ref.String()] {
+					seen[ref.String()] = true
+					next = append(next, ref.String())
+				}
+			}
+		}
+		queue = next
+	}
+	return docs, nil
+}
+
+// Index crawls from the start pages and indexes each page into ds,
+// replacing the previous version of the page. It returns the number of
+// pages indexed.
+func (c *Crawler) Index(ctx context.Context, ds *DocStore, opts ChunkOptions, start ...string) (int, error) {
+	docs, err := c.Crawl(ctx, start...)
+	if err != nil {
+		return 0, err
+	}
+	for i, d := range docs {
+		if err := ds.ReplaceSource(ctx, d.Metadata[sourceKey].(string), []*ai.Document{d}, opts); err != nil {
+			return i, err
+		}
+	}
+	return len(docs), nil
+}
+
+// Sitemap returns the page URLs listed by a sitemap, following sitemap
+// indexes. Each sitemap is fetched once, so that indexes referencing
+// themselves or each other terminate.
+func (c *Crawler) Sitemap(ctx context.Context, sitemapURL string) ([]string, error) {
+	return c.sitemap(ctx, sitemapURL, make(map[string]bool))
+}
+
+// sitemap is Sitemap, skipping the sitemaps in visited.
+func (c *Crawler) sitemap(ctx context.Context, sitemapURL string, visited map[string]bool) ([]string, error) {
+	if visited[sitemapURL] {
+		return nil, nil
+	}
+	visited[sitemapURL] = true
+	body, _, err := c.get(ctx, sitemapURL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	var sm struct {
+		XMLName xml.Name
+		URLs    []struct {
+			Loc string `xml:"loc"`
+		} `xml:"url"`
+		Sitemaps []struct {
+			Loc string `xml:"loc"`
+		} `xml:"sitemap"`
+	}
+	if err := xml.NewDecoder(body).Decode(&sm); err != nil {
+		return nil, fmt.Errorf("qdrant crawl: invalid sitemap %s: %v", sitemapURL, err)
+	}
+	var urls []string
+	for _, u := range sm.URLs {
+		urls = append(urls, strings.TrimSpace(u.Loc))
+	}
+	for _, s := range sm.Sitemaps {
+		nested, err := c.sitemap(ctx, strings.TrimSpace(s.Loc), visited)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, nested...)
+	}
+	return urls, nil
+}
+
+// fetch returns the title, text and links of an HTML page.
+func (c *Crawler) fetch(ctx context.Context, page string) (title, text string, links []string, err error) {
+	body, contentType, err := c.get(ctx, page)
+	if err != nil {
+		return "", "", nil, err
+	}
+	defer body.Close()
+	if mt, _, _ := mime.ParseMediaType(contentType); mt != "text/html" {
+		return "", "", nil, fmt.Errorf("unsupported content type %q", contentType)
+	}
+	return htmlText(body)
+}
+
+func (c *Crawler) get(ctx context.Context, u string) (io.ReadCloser, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, "", fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	return resp.Body, resp.Header.Get("Content-Type"), nil
+}
+
+// htmlText extracts the title, the visible text and the link targets of
+// an HTML document. Block elements are separated by newlines.
+func htmlText(r io.Reader) (title, text string, links []string, err error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return "", "", nil, err
+	}
+	var sb strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			if t := strings.Join(strings.Fields(n.Data), " "); t != "" {
+				if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n") {
+					sb.WriteByte(' ')
+				}
+				sb.WriteString(t)
+			}
+			return
+		case html.ElementNode:
+			switch n.DataAtom {
+			case atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Svg:
+				return
+			case atom.Title:
+				if n.FirstChild != nil {
+					title = strings.TrimSpace(n.FirstChild.Data)
+				}
+				return
+			case atom.A:
+				for _, a := range n.Attr {
+					if a.Key == "href" {
+						links = append(links, a.Val)
+					}
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+		if n.Type == html.ElementNode && isBlock(n.DataAtom) && sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n") {
+			sb.WriteByte('\n')
+		}
+	}
+	walk(doc)
+	return title, strings.TrimSpace(sb.String()), links, nil
+}
+
+func isBlock(a atom.Atom) bool {
+	switch a {
+	case atom.P, atom.Div, atom.Br, atom.Li, atom.Tr, atom.Pre, atom.Blockquote, atom.Section, atom.Article,
+		atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Table, atom.Ul, atom.Ol:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCrawler(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><head><title>Home</title><script>var x;</script></head>
+<body><h1>Welcome</h1><p>Start <a href="/guide#intro">here</a>.</p><a href="https://example.com/">out</a></body></html>`)
+	})
+	mux.HandleFunc("/guide", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><head><title>Guide</title></head><body><p>Install it.</p><a href="/deep">deeper</a></body></html>`)
+	})
+	mux.HandleFunc("/deep", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("crawler went deeper than MaxDepth")
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	docs, err := (&Crawler{MaxDepth: 1}).Crawl(context.Background(), srv.URL+"/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("got %d documents, want 2", len(docs))
+	}
+	if got := documentText(docs[0]); got != "Welcome\nStart here .\nout" {
+		t.Errorf("got text %q", got)
+	}
+	if docs[0].Metadata[titleKey] != "Home" || docs[1].Metadata[sourceKey] != srv.URL+"/guide" {
+		t.Errorf("got metadata %v, %v", docs[0].Metadata, docs[1].Metadata)
+	}
+}
+
+func TestCrawlerSitemap(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap.xml":
+			// The index references itself, which must not recurse.
+			fmt.Fprintf(w, `<sitemapindex><sitemap><loc>%s/docs.xml</loc></sitemap><sitemap><loc>%[1]s/sitemap.xml</loc></sitemap></sitemapindex>`, srv.URL)
+		case "/docs.xml":
+			fmt.Fprint(w, `<urlset><url><loc> https://docs.example.com/a </loc></url><url><loc>https://docs.example.com/b</loc></url></urlset>`)
+		}
+	}))
+	defer srv.Close()
+
+	urls, err := (&Crawler{}).Sitemap(context.Background(), srv.URL+"/sitemap.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(urls, " ") != "https://docs.example.com/a https://docs.example.com/b" {
+		t.Errorf("got %v", urls)
+	}
+}
+
+func TestCrawlerMaxPagesCountsFailures(t *testing.T) {
+	fetches := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		if r.URL.Path == "/" {
+			fmt.Fprint(w, `<html><body><a href="/a">a</a><a href="/b">b</a><a href="/c">c</a></body></html>`)
+			return
+		}
+		http.NotFound(w, r)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	if _, err := (&Crawler{MaxDepth: 1, MaxPages: 2}).Crawl(context.Background(), srv.URL+"/"); err != nil {
+		t.Fatal(err)
+	}
+	if fetches != 2 {
+		t.Errorf("fetched %d pages, want MaxPages", fetches)
+	}
+}
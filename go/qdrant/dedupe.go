@@ -0,0 +1,65 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// DuplicateDocumentError is the reason passed to Config.Quarantine for a
+// document of an index request dropped because a later document of the
+// request has the same point ID, with [IndexerOptions.ReportDuplicates].
+type DuplicateDocumentError struct {
+	// Document and Duplicate are the indexes of the dropped document and
+	// of the document kept in the request.
+	Document  int
+	Duplicate int
+	PointID   string
+}
+
+func (e *DuplicateDocumentError) Error() string {
+	return fmt.Sprintf("qdrant: document %d has the point ID %s of document %d", e.Document, e.PointID, e.Duplicate)
+}
+
+// dedupe returns docs without the documents sharing their point ID with a
+// later document, which would be overwritten within the same request. The
+// last document of each ID is kept, as an upsert of all of them would,
+// and the order of the others is kept. docs is returned unchanged if it
+// has no duplicates.
+//
+// Documents whose ID cannot be computed are kept, so that indexing them
+// fails or quarantines them as usual.
+func (ds *DocStore) dedupe(ctx context.Context, docs []*ai.Document, iopt *IndexerOptions) []*ai.Document {
+	if len(docs) < 2 {
+		return docs
+	}
+	ids := make([]string, len(docs))
+	last := make(map[string]int, len(docs))
+	duplicates := 0
+	for i, doc := range docs {
+		id, err := ds.pointID(doc, iopt.Tenant)
+		if err != nil {
+			continue
+		}
+		if _, ok := last[id]; ok {
+			duplicates++
+		}
+		ids[i] = id
+		last[id] = i
+	}
+	if duplicates == 0 {
+		return docs
+	}
+	kept := make([]*ai.Document, 0, len(docs)-duplicates)
+	for i, doc := range docs {
+		if id := ids[i]; id != "" && last[id] != i {
+			if iopt.ReportDuplicates {
+				ds.quarantine(ctx, doc, &DuplicateDocumentError{Document: i, Duplicate: last[id], PointID: id})
+			}
+			continue
+		}
+		kept = append(kept, doc)
+	}
+	return kept
+}
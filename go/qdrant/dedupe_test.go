@@ -0,0 +1,41 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestDedupe(t *testing.T) {
+	a, b := ai.DocumentFromText("a", nil), ai.DocumentFromText("b", nil)
+	a2 := ai.DocumentFromText("a", nil)
+	docs := []*ai.Document{a, b, a2}
+
+	var reported []*DuplicateDocumentError
+	ds := &DocStore{quarantineSink: QuarantineFunc(func(_ context.Context, _ *ai.Document, reason error) error {
+		var dup *DuplicateDocumentError
+		if !errors.As(reason, &dup) {
+			t.Errorf("got reason %v", reason)
+		}
+		reported = append(reported, dup)
+		return nil
+	})}
+	got := ds.dedupe(context.Background(), docs, &IndexerOptions{})
+	if len(got) != 2 || got[0] != b || got[1] != a2 {
+		t.Errorf("got %v, want the last copy of a after b", got)
+	}
+	if len(reported) != 0 {
+		t.Errorf("reported %d duplicates without ReportDuplicates", len(reported))
+	}
+
+	ds.dedupe(context.Background(), docs, &IndexerOptions{ReportDuplicates: true})
+	if len(reported) != 1 || reported[0].Document != 0 || reported[0].Duplicate != 2 {
+		t.Errorf("got %+v, want document 0 reported as a duplicate of 2", reported)
+	}
+
+	if got := ds.dedupe(context.Background(), []*ai.Document{b}, &IndexerOptions{}); len(got) != 1 {
+		t.Errorf("got %d documents, want 1", len(got))
+	}
+}
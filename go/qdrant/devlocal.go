@@ -0,0 +1,117 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	devContainer = "genkit-qdrant-dev"
+	// devImage is pinned to the server version of the go-client release
+	// in use.
+	devImage = "qdrant/qdrant:v1.12.6"
+	// devStartTimeout bounds the wait for a started container to accept
+	// connections, including the image download.
+	devStartTimeout = 2 * time.Minute
+)
+
+var (
+	devMu      sync.Mutex
+	devStarted bool // the container was started by this process
+)
+
+// startDevLocal makes sure a Qdrant server listens on the host and port
+// of cfg, starting one in a Docker container if nothing listens there.
+// The host must be local; it defaults to localhost.
+func startDevLocal(ctx context.Context, cfg *Config) error {
+	if cfg.GrpcHost == "" {
+		cfg.GrpcHost = "localhost"
+	}
+	if cfg.Port == 0 {
+		cfg.Port = grpcPort
+	}
+	addr := net.JoinHostPort(cfg.GrpcHost, strconv.Itoa(cfg.Port))
+	if reachable(addr) {
+		return nil
+	}
+	if !isLocalHost(cfg.GrpcHost) {
+		return fmt.Errorf("qdrant: Config.DevLocal requires a local host, got %q", cfg.GrpcHost)
+	}
+
+	devMu.Lock()
+	defer devMu.Unlock()
+	if !devStarted {
+		slog.InfoContext(ctx, "qdrant starting a local server", "container", devContainer, "port", cfg.Port)
+		out, err := exec.CommandContext(ctx, "docker", "run", "-d", "--rm",
+			"--name", devContainer,
+			// The server has no API key: it is only published on the
+			// loopback interface.
+			"-p", fmt.Sprintf("%s:%d:%d", devBindAddr(cfg.GrpcHost), cfg.Port, grpcPort),
+			devImage).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("qdrant failed to start a local server with docker: %v: %s", err, strings.TrimSpace(string(out)))
+		}
+		devStarted = true
+	}
+	deadline := time.Now().Add(devStartTimeout)
+	for !reachable(addr) {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("qdrant local server did not start listening on %s", addr)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+	return nil
+}
+
+// devBindAddr returns the loopback address the local server is published
+// on for a local host.
+func devBindAddr(host string) string {
+	if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+		return "[::1]"
+	}
+	return "127.0.0.1"
+}
+
+// StopDevLocal stops the local server started for Config.DevLocal, if
+// this process started one. Its data is discarded.
+func StopDevLocal(ctx context.Context) error {
+	devMu.Lock()
+	defer devMu.Unlock()
+	if !devStarted {
+		return nil
+	}
+	out, err := exec.CommandContext(ctx, "docker", "stop", devContainer).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("qdrant failed to stop the local server: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	devStarted = false
+	return nil
+}
+
+func reachable(addr string) bool {
+	conn, err := net.DialTimeout("tcp", addr, time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+func isLocalHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,43 @@
+package qdrant
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func TestStartDevLocalUsesRunningServer(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	cfg := Config{GrpcHost: "127.0.0.1", Port: l.Addr().(*net.TCPAddr).Port}
+	if err := startDevLocal(context.Background(), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if devStarted {
+		t.Error("started a container although a server was listening")
+	}
+}
+
+func TestStartDevLocalRemoteHost(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+	cfg := Config{GrpcHost: "192.0.2.1", Port: port}
+	if err := startDevLocal(context.Background(), &cfg); err == nil {
+		t.Errorf("expected an error for remote host %s:%d", cfg.GrpcHost, port)
+	}
+}
+
+func TestDevBindAddr(t *testing.T) {
+	for host, want := range map[string]string{"localhost": "127.0.0.1", "127.0.0.1": "127.0.0.1", "::1": "[::1]"} {
+		if got := devBindAddr(host); got != want {
+			t.Errorf("devBindAddr(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
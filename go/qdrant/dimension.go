@@ -0,0 +1,165 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strconv"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// DimensionFix selects how the indexer reacts when the embedder produces
+// vectors of a different size than the collection, e.g. after switching
+// embedding models.
+type DimensionFix int
+
+const (
+	// DimensionFixNone returns a [*DimensionMismatchError].
+	DimensionFixNone DimensionFix = iota
+	// DimensionFixRecreateEmpty recreates the collection with the new
+	// vector size if it holds no points, and fails otherwise.
+	DimensionFixRecreateEmpty
+	// DimensionFixRecreate recreates the collection with the new vector
+	// size, deleting all its points. It cannot be used when the collection
+	// is shared by namespaces, environments or tenants, whose points
+	// would be deleted too.
+	DimensionFixRecreate
+)
+
+// DimensionMismatchError is returned by the indexer when the embedded
+// vectors do not have the size configured for the collection. Qdrant does
+// not support adding vectors to an existing collection, so the only fixes
+// are to use a matching embedder or recreate the collection, see
+// Config.DimensionFix.
+type DimensionMismatchError struct {
+	Collection string
+	// Expected is the vector size of the collection, and Got the size of
+	// the embedded vectors.
+	Expected, Got int
+	Err           error
+}
+
+func (e *DimensionMismatchError) Error() string {
+	return fmt.Sprintf("qdrant collection %q expects vectors of size %d, got %d", e.Collection, e.Expected, e.Got)
+}
+
+func (e *DimensionMismatchError) Unwrap() error {
+	return e.Err
+}
+
+var dimensionErrorPattern = regexp.MustCompile(`expected dim: (\d+), got (\d+)`)
+
+// dimensionError returns a *DimensionMismatchError if err reports that the
+// vectors of an upsert do not match the collection, or nil.
+func (ds *DocStore) dimensionError(err error) *DimensionMismatchError {
+	if status.Code(err) != codes.InvalidArgument {
+		return nil
+	}
+	m := dimensionErrorPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return nil
+	}
+	expected, _ := strconv.Atoi(m[1])
+	got, _ := strconv.Atoi(m[2])
+	return &DimensionMismatchError{Collection: ds.collectionName, Expected: expected, Got: got, Err: err}
+}
+
+// checkDimensionFix rejects DimensionFixRecreate for a collection shared
+// with other namespaces, environments or tenants.
+func (ds *DocStore) checkDimensionFix() error {
+	if ds.dimensionFix != DimensionFixRecreate {
+		return nil
+	}
+	if ds.namespace != "" || ds.environment != "" || ds.tenantKey != "" || ds.shardPerTenant {
+		return errors.New("qdrant: Config.DimensionFix cannot be DimensionFixRecreate when Config.Namespace, Environment, TenantKey or ShardPerTenant is set; use DimensionFixRecreateEmpty")
+	}
+	return nil
+}
+
+// fixDimension applies Config.DimensionFix to a dimension mismatch and
+// reports whether the upsert can be retried. Concurrent index requests
+// hitting the same mismatch recreate the collection once: the others find
+// it recreated with their vector size and retry.
+func (ds *DocStore) fixDimension(ctx context.Context, dm *DimensionMismatchError) (bool, error) {
+	if ds.dimensionFix != DimensionFixRecreateEmpty && ds.dimensionFix != DimensionFixRecreate {
+		return false, nil
+	}
+	ds.createMu.Lock()
+	defer ds.createMu.Unlock()
+	info, err := ds.client.GetCollectionInfo(ctx, ds.collectionName)
+	if err != nil {
+		return false, fmt.Errorf("qdrant failed to fetch collection info: %v", err)
+	}
+	if info.GetConfig().GetParams().GetVectorsConfig().GetParams().GetSize() == uint64(dm.Got) {
+		return true, nil
+	}
+	if ds.dimensionFix == DimensionFixRecreateEmpty {
+		n, err := ds.client.Count(ctx, &qclient.CountPoints{CollectionName: ds.collectionName, Exact: qclient.PtrOf(true)})
+		if err != nil {
+			return false, fmt.Errorf("qdrant count failed: %v", err)
+		}
+		if n > 0 {
+			return false, nil
+		}
+	}
+	slog.WarnContext(ctx, "qdrant recreating collection for a new vector size", "collection", ds.collectionName, "from", dm.Expected, "to", dm.Got)
+	return true, ds.recreateCollection(ctx, info.GetConfig(), uint64(dm.Got))
+}
+
+// recreateCollection drops the collection of config and creates it again
+// with the same configuration, except for the vector size, and the
+// payload indexes configured at Init. The caller must hold ds.createMu.
+func (ds *DocStore) recreateCollection(ctx context.Context, config *qclient.CollectionConfig, size uint64) error {
+	req, err := ds.recreateRequest(config, size)
+	if err != nil {
+		return err
+	}
+	if err := ds.client.DeleteCollection(ctx, ds.collectionName); err != nil {
+		return fmt.Errorf("qdrant failed to delete collection: %v", err)
+	}
+	if err := ds.client.CreateCollection(ctx, req); err != nil {
+		return fmt.Errorf("qdrant failed to recreate collection: %v", err)
+	}
+
+	ds.shardMu.Lock()
+	ds.knownShards = nil
+	ds.shardMu.Unlock()
+	ds.schema.invalidate()
+	return ds.createIndexes(ctx)
+}
+
+// recreateRequest returns the request creating the collection of config
+// again with vectors of the given size. The vector keeps its distance,
+// storage, datatype, multivector, HNSW and quantization settings, and the
+// collection its shards, replication, optimizer, WAL, HNSW, quantization,
+// strict mode and sparse vector configuration.
+func (ds *DocStore) recreateRequest(config *qclient.CollectionConfig, size uint64) (*qclient.CreateCollection, error) {
+	params := config.GetParams()
+	vectors := params.GetVectorsConfig().GetParams()
+	if vectors == nil {
+		return nil, fmt.Errorf("qdrant collection %q does not use a single unnamed vector", ds.collectionName)
+	}
+	vectors = proto.Clone(vectors).(*qclient.VectorParams)
+	vectors.Size = size
+	return &qclient.CreateCollection{
+		CollectionName:         ds.collectionName,
+		VectorsConfig:          qclient.NewVectorsConfig(vectors),
+		ShardNumber:            qclient.PtrOf(params.GetShardNumber()),
+		ReplicationFactor:      params.ReplicationFactor,
+		WriteConsistencyFactor: params.WriteConsistencyFactor,
+		OnDiskPayload:          qclient.PtrOf(params.GetOnDiskPayload()),
+		ShardingMethod:         params.ShardingMethod,
+		SparseVectorsConfig:    params.GetSparseVectorsConfig(),
+		HnswConfig:             config.GetHnswConfig(),
+		OptimizersConfig:       config.GetOptimizerConfig(),
+		WalConfig:              config.GetWalConfig(),
+		QuantizationConfig:     config.GetQuantizationConfig(),
+		StrictModeConfig:       config.GetStrictModeConfig(),
+	}, nil
+}
@@ -0,0 +1,96 @@
+package qdrant
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDimensionError(t *testing.T) {
+	ds := &DocStore{collectionName: "docs"}
+	err := fmt.Errorf("Upsert() failed: %w", status.Error(codes.InvalidArgument, "Wrong input: Vector dimension error: expected dim: 768, got 1536"))
+	dm := ds.dimensionError(err)
+	if dm == nil {
+		t.Fatal("expected a dimension mismatch")
+	}
+	if dm.Expected != 768 || dm.Got != 1536 || dm.Collection != "docs" {
+		t.Errorf("got %+v", dm)
+	}
+	if !errors.Is(dm, err) {
+		t.Error("the mismatch does not wrap the Qdrant error")
+	}
+
+	if ds.dimensionError(status.Error(codes.InvalidArgument, "Wrong input: bad payload")) != nil {
+		t.Error("unexpected mismatch for another invalid argument")
+	}
+	if ds.dimensionError(nil) != nil {
+		t.Error("unexpected mismatch for a nil error")
+	}
+}
+
+func TestRecreateRequestKeepsConfiguration(t *testing.T) {
+	hnsw := &qclient.HnswConfigDiff{M: qclient.PtrOf(uint64(32))}
+	quantization := qclient.NewQuantizationScalar(&qclient.ScalarQuantization{Type: qclient.QuantizationType_Int8})
+	vectors := &qclient.VectorParams{
+		Size:               4,
+		Distance:           qclient.Distance_Dot,
+		HnswConfig:         &qclient.HnswConfigDiff{EfConstruct: qclient.PtrOf(uint64(200))},
+		QuantizationConfig: quantization,
+		OnDisk:             qclient.PtrOf(true),
+		Datatype:           qclient.Datatype_Float16.Enum(),
+	}
+	config := &qclient.CollectionConfig{
+		Params: &qclient.CollectionParams{
+			ShardNumber:   2,
+			VectorsConfig: qclient.NewVectorsConfig(vectors),
+		},
+		HnswConfig:         hnsw,
+		OptimizerConfig:    &qclient.OptimizersConfigDiff{IndexingThreshold: qclient.PtrOf(uint64(1000))},
+		WalConfig:          &qclient.WalConfigDiff{WalCapacityMb: qclient.PtrOf(uint64(64))},
+		QuantizationConfig: quantization,
+	}
+	ds := &DocStore{collectionName: "docs"}
+	req, err := ds.recreateRequest(config, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := proto.Clone(vectors).(*qclient.VectorParams)
+	want.Size = 8
+	if got := req.GetVectorsConfig().GetParams(); !proto.Equal(got, want) {
+		t.Errorf("got vector params %v, want %v", got, want)
+	}
+	if vectors.Size != 4 {
+		t.Error("the vector params of the collection info were modified")
+	}
+	if req.GetShardNumber() != 2 || !proto.Equal(req.GetHnswConfig(), hnsw) ||
+		req.GetOptimizersConfig().GetIndexingThreshold() != 1000 || req.GetWalConfig().GetWalCapacityMb() != 64 ||
+		!proto.Equal(req.GetQuantizationConfig(), quantization) {
+		t.Errorf("got %v", req)
+	}
+
+	config.Params.VectorsConfig = qclient.NewVectorsConfigMap(map[string]*qclient.VectorParams{"dense": vectors})
+	if _, err := ds.recreateRequest(config, 8); err == nil {
+		t.Error("expected an error for named vectors")
+	}
+}
+
+func TestCheckDimensionFix(t *testing.T) {
+	if err := (&DocStore{dimensionFix: DimensionFixRecreate}).checkDimensionFix(); err != nil {
+		t.Errorf("got %v for a collection of its own", err)
+	}
+	for _, ds := range []*DocStore{{namespace: "app"}, {environment: "staging"}, {tenantKey: "org"}, {shardPerTenant: true}} {
+		ds.dimensionFix = DimensionFixRecreate
+		if err := ds.checkDimensionFix(); err == nil {
+			t.Errorf("got no error for the shared collection of %+v", ds)
+		}
+		ds.dimensionFix = DimensionFixRecreateEmpty
+		if err := ds.checkDimensionFix(); err != nil {
+			t.Errorf("got %v for DimensionFixRecreateEmpty", err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// Discover steers an exploratory search with pairs of examples rather than
+// a single query embedding: each pair splits the vector space into a
+// positive side and a negative side, and results lie on the positive side
+// of as many pairs as possible.
+//
+// With a target, results are then ranked by their similarity to it. The
+// target is Target if set, or else the embedded text of the query
+// document, if any. Without a target, the context alone ranks the
+// results, which explores the region it delimits.
+//
+// Scores are not similarities: they count the pairs satisfied first, so
+// RetrieverOptions.ScoreThreshold and the confidence indicator are of
+// little use. The examples are compared with the dense vector queried, as
+// for [Recommend].
+type Discover struct {
+	Target  *RecommendExample `json:"target,omitempty"`
+	Context []ContextPair     `json:"context"`
+}
+
+// ContextPair is a pair of examples of [Discover]: results should be
+// closer to Positive than to Negative.
+type ContextPair struct {
+	Positive RecommendExample `json:"positive"`
+	Negative RecommendExample `json:"negative"`
+}
+
+// byExample reports whether ropt searches with examples, Recommend or
+// Discover, which need no query text.
+func (ropt *RetrieverOptions) byExample() bool {
+	return ropt.Recommend != nil || ropt.Discover != nil
+}
+
+func (d *Discover) validate() error {
+	if len(d.Context) == 0 {
+		return errors.New("discover: no context pairs")
+	}
+	if d.Target != nil {
+		if _, err := d.Target.input(); err != nil {
+			return fmt.Errorf("discover: target: %v", err)
+		}
+	}
+	for i, p := range d.Context {
+		if _, err := p.Positive.input(); err != nil {
+			return fmt.Errorf("discover: context %d: positive: %v", i, err)
+		}
+		if _, err := p.Negative.input(); err != nil {
+			return fmt.Errorf("discover: context %d: negative: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// discoverQuery sets the discovery query of RetrieverOptions.Discover on
+// query, or a context query if it has no target.
+func (ds *DocStore) discoverQuery(ctx context.Context, query *qclient.QueryPoints, qdoc *ai.Document, ropt *RetrieverOptions) error {
+	if ds.multiVector.primary() {
+		return errors.New("qdrant: RetrieverOptions.Discover requires a dense vector")
+	}
+	d := ropt.Discover
+	pairs := &qclient.ContextInput{Pairs: make([]*qclient.ContextInputPair, len(d.Context))}
+	for i, p := range d.Context {
+		positive, err := p.Positive.input()
+		if err != nil {
+			return err
+		}
+		negative, err := p.Negative.input()
+		if err != nil {
+			return err
+		}
+		pairs.Pairs[i] = &qclient.ContextInputPair{Positive: positive, Negative: negative}
+	}
+	var target *qclient.VectorInput
+	switch {
+	case d.Target != nil:
+		var err error
+		if target, err = d.Target.input(); err != nil {
+			return err
+		}
+	case strings.TrimSpace(documentText(qdoc)) != "":
+		q, err := ds.embedQuery(ctx, qdoc, ropt)
+		if err != nil {
+			return err
+		}
+		target = q.GetNearest()
+	}
+	if target == nil {
+		query.Query = qclient.NewQueryContext(pairs)
+		return nil
+	}
+	query.Query = qclient.NewQueryDiscover(&qclient.DiscoverInput{Target: target, Context: pairs})
+	return nil
+}
@@ -0,0 +1,61 @@
+package qdrant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestDiscoverOptions(t *testing.T) {
+	pair := map[string]any{"positive": map[string]any{"id": "1"}, "negative": map[string]any{"vector": []any{0.5, 1}}}
+	ropt, err := parseRetrieverOptions(map[string]any{"discover": map[string]any{
+		"target":  map[string]any{"id": "5c56c793-69f3-4fbf-87e6-c4bf54c28c26"},
+		"context": []any{pair},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ropt.Discover.Target.ID == "" || len(ropt.Discover.Context) != 1 {
+		t.Errorf("got %+v", ropt.Discover)
+	}
+	for _, bad := range []map[string]any{
+		{"discover": map[string]any{}},
+		{"discover": map[string]any{"target": map[string]any{"id": "1"}}},
+		{"discover": map[string]any{"context": []any{map[string]any{"positive": map[string]any{"id": "1"}}}}},
+		{"discover": map[string]any{"target": map[string]any{"id": "not-an-id"}, "context": []any{pair}}},
+		{"discover": map[string]any{"context": []any{pair}}, "recommend": map[string]any{"positive": []any{map[string]any{"id": "1"}}}},
+		{"discover": map[string]any{"context": []any{pair}}, "search": "sparse"},
+	} {
+		if _, err := parseRetrieverOptions(bad); err == nil {
+			t.Errorf("%v: expected an error", bad)
+		}
+	}
+}
+
+func TestDiscoverQuery(t *testing.T) {
+	ds := &DocStore{}
+	pairs := []ContextPair{{Positive: RecommendExample{ID: "7"}, Negative: RecommendExample{Vector: []float32{1, 0}}}}
+
+	query := &qclient.QueryPoints{}
+	ropt := &RetrieverOptions{Discover: &Discover{Target: &RecommendExample{ID: "3"}, Context: pairs}}
+	if err := ds.discoverQuery(context.Background(), query, &ai.Document{}, ropt); err != nil {
+		t.Fatal(err)
+	}
+	d := query.GetQuery().GetDiscover()
+	if d.GetTarget().GetId().GetNum() != 3 || d.GetContext().GetPairs()[0].GetPositive().GetId().GetNum() != 7 ||
+		len(d.GetContext().GetPairs()[0].GetNegative().GetDense().GetData()) != 2 {
+		t.Errorf("got %v", d)
+	}
+
+	// Without a target or query text, the context alone is searched.
+	query = &qclient.QueryPoints{}
+	ropt = &RetrieverOptions{Discover: &Discover{Context: pairs}}
+	if err := ds.discoverQuery(context.Background(), query, &ai.Document{}, ropt); err != nil {
+		t.Fatal(err)
+	}
+	if c := query.GetQuery().GetContext(); len(c.GetPairs()) != 1 {
+		t.Errorf("got %v", query.GetQuery())
+	}
+}
@@ -0,0 +1,148 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// maxDuplicateSample is the default number of points compared by
+// FindNearDuplicates, which bounds the size of the distance matrix.
+const maxDuplicateSample = 1000
+
+// DuplicateOptions configures [DocStore.FindNearDuplicates].
+type DuplicateOptions struct {
+	// Filter restricts the documents that are compared.
+	Filter *qclient.Filter
+	// Sample is the number of points compared. Defaults to every point
+	// matching Filter, up to 1000. Larger collections are better scanned
+	// in parts, e.g. with a Filter per source, than with a larger Sample,
+	// as the cost of the distance matrix grows with the sample.
+	Sample int
+	// Neighbours is the number of nearest neighbours inspected per point.
+	// Defaults to 3.
+	Neighbours int
+}
+
+// DuplicateCluster is a group of near-identical documents.
+type DuplicateCluster struct {
+	IDs       []string
+	Documents []*ai.Document
+}
+
+// FindNearDuplicates reports clusters of documents whose pairwise
+// similarity is at least threshold. Scores are compared as similarities,
+// so the collection should use cosine or dot product distance.
+func (ds *DocStore) FindNearDuplicates(ctx context.Context, threshold float32, opts *DuplicateOptions) ([]DuplicateCluster, error) {
+	if opts == nil {
+		opts = &DuplicateOptions{}
+	}
+	sample := opts.Sample
+	if sample <= 0 {
+		n, err := ds.client.Count(ctx, &qclient.CountPoints{
+			CollectionName: ds.collectionName,
+			Filter:         ds.scopeFilter(ds.envFilter(opts.Filter, &RetrieverOptions{})),
+			Exact:          qclient.PtrOf(true),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("qdrant count failed: %v", err)
+		}
+		sample = int(min(n, maxDuplicateSample))
+	}
+	if sample < 2 {
+		return nil, nil
+	}
+
+	pairs, err := ds.DistancePairs(ctx, &MatrixOptions{
+		Filter: opts.Filter,
+		Sample: sample,
+		Limit:  opts.Neighbours,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	groups := clusterPairs(pairs, threshold)
+	if len(groups) == 0 {
+		return nil, nil
+	}
+
+	var ids []*qclient.PointId
+	for _, g := range groups {
+		for _, id := range g {
+			ids = append(ids, pointIDOf(id))
+		}
+	}
+	points, err := ds.client.Get(ctx, &qclient.GetPoints{
+		CollectionName: ds.collectionName,
+		Ids:            ids,
+		WithPayload:    qclient.NewWithPayloadInclude(ds.contentPayloadKey, ds.metadataPayloadKey),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("qdrant get failed: %v", err)
+	}
+	docs := make(map[string]*ai.Document, len(points))
+	for _, p := range points {
+		d, err := ds.documentFromPayload(p.GetPayload())
+		if err != nil {
+			return nil, err
+		}
+		docs[pointIDString(p.GetId())] = d
+	}
+
+	clusters := make([]DuplicateCluster, 0, len(groups))
+	for _, g := range groups {
+		c := DuplicateCluster{IDs: g}
+		for _, id := range g {
+			c.Documents = append(c.Documents, docs[id])
+		}
+		clusters = append(clusters, c)
+	}
+	return clusters, nil
+}
+
+// clusterPairs groups the points connected by pairs scoring at least
+// threshold, using union-find. Groups are returned in order of first
+// appearance and only groups of two or more points are kept.
+func clusterPairs(pairs []MatrixPair, threshold float32) [][]string {
+	parent := make(map[string]string)
+	var find func(string) string
+	find = func(x string) string {
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+
+	var order []string
+	for _, p := range pairs {
+		if p.Score < threshold {
+			continue
+		}
+		for _, id := range []string{p.A, p.B} {
+			if _, ok := parent[id]; !ok {
+				parent[id] = id
+				order = append(order, id)
+			}
+		}
+		if ra, rb := find(p.A), find(p.B); ra != rb {
+			parent[rb] = ra
+		}
+	}
+
+	index := make(map[string]int)
+	var groups [][]string
+	for _, id := range order {
+		root := find(id)
+		i, ok := index[root]
+		if !ok {
+			i = len(groups)
+			index[root] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], id)
+	}
+	return groups
+}
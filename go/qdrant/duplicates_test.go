@@ -0,0 +1,45 @@
+package qdrant
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestClusterPairs(t *testing.T) {
+	pairs := []MatrixPair{
+		{A: "a", B: "b", Score: 0.99},
+		{A: "c", B: "d", Score: 0.5},
+		{A: "b", B: "e", Score: 0.97},
+		{A: "f", B: "g", Score: 0.98},
+	}
+	got := clusterPairs(pairs, 0.95)
+	want := [][]string{{"a", "b", "e"}, {"f", "g"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestFindNearDuplicatesSample(t *testing.T) {
+	var sample uint64
+	ds := countingStore(t, "docs", 0)
+	ds.client = fakeClient(t, func(_ string, req any) (proto.Message, error) {
+		switch req := req.(type) {
+		case *qclient.HealthCheckRequest:
+			return &qclient.HealthCheckReply{Version: "1.12.0"}, nil
+		case *qclient.SearchMatrixPoints:
+			sample = req.GetSample()
+			return &qclient.SearchMatrixPairsResponse{}, nil
+		}
+		return &qclient.CountResponse{Result: &qclient.CountResult{Count: 50000}}, nil
+	})
+	if _, err := ds.FindNearDuplicates(context.Background(), 0.95, nil); err != nil {
+		t.Fatal(err)
+	}
+	if sample != maxDuplicateSample {
+		t.Errorf("got sample %d, want %d", sample, maxDuplicateSample)
+	}
+}
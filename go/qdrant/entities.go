@@ -0,0 +1,116 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// entitiesPayloadKey is the payload field holding extracted entities.
+const entitiesPayloadKey = "_entities"
+
+const entityPrompt = `List the named entities (people, organizations, places, products, concepts) mentioned in the text below.
+Write one entity per line, with no numbering or other text. Write nothing if there are none.
+
+Text:
+%s`
+
+// extractEntities asks the entity model for the entities of text and
+// returns them normalized to lower case without duplicates.
+func (ds *DocStore) extractEntities(ctx context.Context, text string) ([]any, error) {
+	out, err := ai.GenerateText(ctx, ds.entityModel, ai.WithTextPrompt(fmt.Sprintf(entityPrompt, text)))
+	if err != nil {
+		return nil, fmt.Errorf("qdrant entity extraction failed: %v", err)
+	}
+	return parseEntities(out), nil
+}
+
+// parseEntities splits model output into normalized entities.
+func parseEntities(out string) []any {
+	seen := make(map[string]bool)
+	entities := []any{}
+	for _, line := range strings.Split(out, "\n") {
+		e := strings.ToLower(strings.Trim(strings.TrimSpace(line), "-*•\t "))
+		if e == "" || seen[e] {
+			continue
+		}
+		seen[e] = true
+		entities = append(entities, e)
+	}
+	return entities
+}
+
+// createEntityIndex creates the keyword index on the entities field.
+func (ds *DocStore) createEntityIndex(ctx context.Context) error {
+	_, err := ds.client.CreateFieldIndex(ctx, &qclient.CreateFieldIndexCollection{
+		CollectionName: ds.collectionName,
+		FieldName:      entitiesPayloadKey,
+		FieldType:      qclient.FieldType_FieldTypeKeyword.Enum(),
+		Wait:           qclient.PtrOf(true),
+	})
+	if err != nil {
+		return fmt.Errorf("qdrant failed to create entity index: %v", err)
+	}
+	return nil
+}
+
+// queryWithEntities runs query both restricted to points sharing an
+// entity with the query text and unrestricted, and returns the entity
+// matches followed by the remaining vector matches.
+func (ds *DocStore) queryWithEntities(ctx context.Context, ropt *RetrieverOptions, query *qclient.QueryPoints, qv queryVectors, text string) ([]*qclient.ScoredPoint, error) {
+	entities, err := ds.extractEntities(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	if len(entities) == 0 {
+		return ds.query(ctx, ds.searchQuery(query, qv))
+	}
+
+	keywords := make([]string, len(entities))
+	for i, e := range entities {
+		keywords[i] = e.(string)
+	}
+	filtered, err := ds.queryPoints(ctx, ropt)
+	if err != nil {
+		return nil, err
+	}
+	filtered.Query = query.Query
+	filtered.Filter = &qclient.Filter{
+		Must: []*qclient.Condition{
+			qclient.NewMatchKeywords(entitiesPayloadKey, keywords...),
+			qclient.NewFilterAsCondition(query.Filter),
+		},
+	}
+
+	batch, err := ds.queryBatch(ctx, &qclient.QueryBatchPoints{
+		CollectionName:  ds.collectionName,
+		QueryPoints:     []*qclient.QueryPoints{ds.searchQuery(filtered, qv), ds.searchQuery(query, qv)},
+		ReadConsistency: query.ReadConsistency,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mergePoints(batch[0].GetResult(), batch[1].GetResult(), int(query.GetLimit())), nil
+}
+
+// mergePoints returns the points of first followed by those of second
+// not already included, up to limit points. A limit of zero keeps all.
+func mergePoints(first, second []*qclient.ScoredPoint, limit int) []*qclient.ScoredPoint {
+	seen := make(map[string]bool)
+	var merged []*qclient.ScoredPoint
+	for _, p := range append(append([]*qclient.ScoredPoint(nil), first...), second...) {
+		id := pointIDString(p.GetId())
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		merged = append(merged, p)
+		if limit > 0 && len(merged) == limit {
+			break
+		}
+	}
+	return merged
+}
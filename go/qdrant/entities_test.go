@@ -0,0 +1,29 @@
+package qdrant
+
+import (
+	"reflect"
+	"testing"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestParseEntities(t *testing.T) {
+	got := parseEntities("Paris\n- France\n\n  paris \n* Eiffel Tower")
+	want := []any{"paris", "france", "eiffel tower"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestMergePoints(t *testing.T) {
+	p := func(id uint64) *qclient.ScoredPoint { return &qclient.ScoredPoint{Id: qclient.NewIDNum(id)} }
+	merged := mergePoints([]*qclient.ScoredPoint{p(3), p(1)}, []*qclient.ScoredPoint{p(1), p(2), p(4)}, 3)
+
+	var ids []string
+	for _, m := range merged {
+		ids = append(ids, pointIDString(m.GetId()))
+	}
+	if want := []string{"3", "1", "2"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("got %v, want %v", ids, want)
+	}
+}
@@ -0,0 +1,39 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// envPayloadKey is the payload field holding Config.Environment.
+const envPayloadKey = "env"
+
+// envFilter restricts filter, which may be nil, to the points of the
+// environment of the store, unless the retriever options ask for every
+// environment.
+func (ds *DocStore) envFilter(filter *qclient.Filter, ropt *RetrieverOptions) *qclient.Filter {
+	if ds.environment == "" || ropt.AnyEnvironment {
+		return filter
+	}
+	must := []*qclient.Condition{qclient.NewMatchKeyword(envPayloadKey, ds.environment)}
+	if filter != nil {
+		must = append(must, qclient.NewFilterAsCondition(filter))
+	}
+	return &qclient.Filter{Must: must}
+}
+
+// createEnvIndex creates the keyword index on the environment field.
+func (ds *DocStore) createEnvIndex(ctx context.Context) error {
+	_, err := ds.client.CreateFieldIndex(ctx, &qclient.CreateFieldIndexCollection{
+		CollectionName: ds.collectionName,
+		FieldName:      envPayloadKey,
+		FieldType:      qclient.FieldType_FieldTypeKeyword.Enum(),
+		Wait:           qclient.PtrOf(true),
+	})
+	if err != nil {
+		return fmt.Errorf("qdrant failed to create environment index: %v", err)
+	}
+	return nil
+}
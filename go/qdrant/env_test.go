@@ -0,0 +1,61 @@
+package qdrant
+
+import (
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestEnvFilter(t *testing.T) {
+	filter := &qclient.Filter{Must: []*qclient.Condition{qclient.NewMatchKeyword("lang", "en")}}
+	ds := &DocStore{}
+	if got := ds.envFilter(filter, &RetrieverOptions{}); got != filter {
+		t.Error("filter changed without an environment")
+	}
+
+	ds.environment = "staging"
+	got := ds.envFilter(filter, &RetrieverOptions{})
+	if len(got.GetMust()) != 2 || got.Must[0].GetField().GetKey() != envPayloadKey ||
+		got.Must[0].GetField().GetMatch().GetKeyword() != "staging" || got.Must[1].GetFilter() != filter {
+		t.Errorf("got %v", got)
+	}
+	if got := ds.envFilter(filter, &RetrieverOptions{AnyEnvironment: true}); got != filter {
+		t.Error("filter changed with AnyEnvironment")
+	}
+	if got := ds.envFilter(nil, &RetrieverOptions{}); len(got.GetMust()) != 1 {
+		t.Errorf("got %v for a nil filter, want the environment condition only", got)
+	}
+}
+
+func TestDeleteRequestEnv(t *testing.T) {
+	ds := &DocStore{collectionName: "docs", namespace: "app", environment: "staging"}
+	source := &qclient.Filter{Must: []*qclient.Condition{qclient.NewMatchKeyword("metadata.source_id", "a.md")}}
+	filter := ds.deleteRequest(source).GetPoints().GetFilter()
+	if len(filter.GetMust()) != 2 || filter.Must[0].GetField().GetKey() != namespacePayloadKey {
+		t.Fatalf("got %v, want the namespace condition first", filter)
+	}
+	env := filter.Must[1].GetFilter()
+	if env.GetMust()[0].GetField().GetKey() != envPayloadKey || env.Must[0].GetField().GetMatch().GetKeyword() != "staging" ||
+		env.Must[1].GetFilter() != source {
+		t.Errorf("got %v, want the environment condition around the source filter", env)
+	}
+}
+
+func TestEnvPointID(t *testing.T) {
+	doc := ai.DocumentFromText("hello", nil)
+	id := func(ds *DocStore) string {
+		id, err := ds.pointID(doc, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	staging, production := id(&DocStore{environment: "staging"}), id(&DocStore{environment: "production"})
+	if staging == production || staging == id(&DocStore{}) {
+		t.Errorf("got IDs %s and %s, want distinct IDs per environment", staging, production)
+	}
+	if id(&DocStore{namespace: "a", environment: "staging"}) == id(&DocStore{namespace: "a"}) {
+		t.Error("environment not part of the namespaced ID")
+	}
+}
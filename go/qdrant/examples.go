@@ -0,0 +1,96 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// exampleOutputKey is the metadata key holding the output of an example.
+const exampleOutputKey = "example_output"
+
+// Example is an input/output pair used in few-shot prompts.
+type Example struct {
+	Input  string `json:"input"`
+	Output string `json:"output"`
+}
+
+// ExampleSelector stores prompt examples in a collection and selects the
+// ones most similar to an input, to build dynamic few-shot prompts.
+type ExampleSelector struct {
+	indexer   ai.Indexer
+	retriever ai.Retriever
+	k         int
+}
+
+// NewExampleSelector returns a selector using the collection configured
+// with [Init] under the given name. Select returns up to k examples.
+func NewExampleSelector(collection string, k int) (*ExampleSelector, error) {
+	if Store(collection) == nil {
+		return nil, fmt.Errorf("qdrant: collection %q is not configured", collection)
+	}
+	return &ExampleSelector{indexer: Indexer(collection), retriever: Retriever(collection), k: k}, nil
+}
+
+// Add indexes examples. The input is embedded; the output is stored in
+// the metadata.
+func (s *ExampleSelector) Add(ctx context.Context, examples ...Example) error {
+	docs := make([]*ai.Document, 0, len(examples))
+	for _, e := range examples {
+		docs = append(docs, ai.DocumentFromText(e.Input, map[string]any{exampleOutputKey: e.Output}))
+	}
+	return ai.Index(ctx, s.indexer, ai.WithIndexerDocs(docs...))
+}
+
+// Select returns the examples most similar to input.
+func (s *ExampleSelector) Select(ctx context.Context, input string) ([]Example, error) {
+	resp, err := ai.Retrieve(ctx, s.retriever, ai.WithRetrieverText(input), ai.WithRetrieverOpts(&RetrieverOptions{K: s.k}))
+	if err != nil {
+		return nil, err
+	}
+	examples := make([]Example, 0, len(resp.Documents))
+	for _, d := range resp.Documents {
+		examples = append(examples, Example{
+			Input:  documentText(d),
+			Output: metadataString(d.Metadata, exampleOutputKey),
+		})
+	}
+	return examples, nil
+}
+
+// FormatExamples renders examples as a few-shot prompt section.
+func FormatExamples(examples []Example) string {
+	var sb strings.Builder
+	for _, e := range examples {
+		fmt.Fprintf(&sb, "Input: %s\nOutput: %s\n\n", e.Input, e.Output)
+	}
+	return sb.String()
+}
+
+// ExampleQuery is the input of the tool defined by [ExampleSelector.DefineTool].
+type ExampleQuery struct {
+	Input string `json:"input"`
+}
+
+// DefineTool defines a Genkit tool that selects examples for an input.
+func (s *ExampleSelector) DefineTool(name, description string) *ai.ToolDef[ExampleQuery, []Example] {
+	return ai.DefineTool(name, description, func(ctx context.Context, q ExampleQuery) ([]Example, error) {
+		return s.Select(ctx, q.Input)
+	})
+}
+
+// metadataString returns the string stored under key in retrieved
+// metadata, or "" if there is none.
+func metadataString(metadata map[string]any, key string) string {
+	switch v := metadata[key].(type) {
+	case string:
+		return v
+	case *qclient.Value:
+		return v.GetStringValue()
+	default:
+		return ""
+	}
+}
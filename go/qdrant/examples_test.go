@@ -0,0 +1,17 @@
+package qdrant
+
+import "testing"
+
+func TestFormatExamples(t *testing.T) {
+	got := FormatExamples([]Example{{Input: "2+2", Output: "4"}, {Input: "3+3", Output: "6"}})
+	want := "Input: 2+2\nOutput: 4\n\nInput: 3+3\nOutput: 6\n\n"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestNewExampleSelectorUnknownCollection(t *testing.T) {
+	if _, err := NewExampleSelector("not-initialized", 3); err == nil {
+		t.Error("expected an error for a collection without a store")
+	}
+}
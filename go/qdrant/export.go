@@ -0,0 +1,84 @@
+package qdrant
+
+import (
+	"context"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// iterateBatch is the number of points fetched per scroll by
+// DocStore.Iterate.
+const iterateBatch = 256
+
+// Iterate calls fn for every stored document matching filter, which may
+// be nil, in point ID order, for backups, audits and re-indexing
+// pipelines. Like retrieval, it only visits the points of the namespace
+// and environment of the store. It stops at the first error of fn, which
+// is returned.
+//
+// Documents are returned as they were indexed: a text part holding the
+// content, and the metadata as plain Go values, without the score and ID
+// metadata of the retriever, so that indexing them again gives them the
+// same point IDs.
+func (ds *DocStore) Iterate(ctx context.Context, filter *qclient.Filter, fn func(*ai.Document) error) error {
+	if filter == nil {
+		filter = &qclient.Filter{}
+	}
+	return ds.scroll(ctx, &qclient.ScrollPoints{
+		CollectionName: ds.collectionName,
+		Filter:         ds.envFilter(filter, &RetrieverOptions{}),
+		Limit:          qclient.PtrOf(uint32(iterateBatch)),
+		WithPayload:    qclient.NewWithPayloadInclude(ds.contentPayloadKey, ds.metadataPayloadKey),
+	}, func(p *qclient.RetrievedPoint) error {
+		doc, err := ds.exportedDocument(p.GetPayload())
+		if err != nil {
+			return err
+		}
+		return fn(doc)
+	})
+}
+
+// exportedDocument reconstructs the document indexed as a point payload,
+// with plain metadata values.
+func (ds *DocStore) exportedDocument(payload map[string]*qclient.Value) (*ai.Document, error) {
+	doc, err := ds.documentFromPayload(payload)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range doc.Metadata {
+		doc.Metadata[k] = plainValue(v.(*qclient.Value))
+	}
+	return doc, nil
+}
+
+// plainValue converts a payload value to the Go value it was converted
+// from, up to the numeric type: nil, bool, int64, float64, string,
+// map[string]any or []any.
+func plainValue(v *qclient.Value) any {
+	switch k := v.GetKind().(type) {
+	case *qclient.Value_BoolValue:
+		return k.BoolValue
+	case *qclient.Value_IntegerValue:
+		return k.IntegerValue
+	case *qclient.Value_DoubleValue:
+		return k.DoubleValue
+	case *qclient.Value_StringValue:
+		return k.StringValue
+	case *qclient.Value_StructValue:
+		fields := k.StructValue.GetFields()
+		m := make(map[string]any, len(fields))
+		for name, f := range fields {
+			m[name] = plainValue(f)
+		}
+		return m
+	case *qclient.Value_ListValue:
+		values := k.ListValue.GetValues()
+		s := make([]any, len(values))
+		for i, item := range values {
+			s[i] = plainValue(item)
+		}
+		return s
+	}
+	return nil
+}
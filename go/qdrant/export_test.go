@@ -0,0 +1,43 @@
+package qdrant
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestExportedDocument(t *testing.T) {
+	ds := &DocStore{contentPayloadKey: "content", metadataPayloadKey: "metadata"}
+	for _, doc := range []*ai.Document{
+		ai.DocumentFromText("plain", nil),
+		ai.DocumentFromText("nested", map[string]any{
+			"lang":  "en",
+			"page":  3,
+			"score": 0.5,
+			"tags":  []string{"a", "b"},
+			"info":  map[string]any{"draft": true, "parent": nil},
+		}),
+	} {
+		point, err := ds.point(context.Background(), nil, doc, documentText(doc), []float32{1}, &IndexerOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ds.exportedDocument(point.Payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Indexing an exported document again gives it the same point.
+		id, err := ds.pointID(got, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := point.GetId().GetUuid(); id != want {
+			t.Errorf("%s: got point ID %s, want %s", documentText(doc), id, want)
+		}
+		if tags, ok := got.Metadata["tags"]; ok && !reflect.DeepEqual(tags, []any{"a", "b"}) {
+			t.Errorf("got tags %#v", tags)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// Extractor converts the content of a file into text, e.g. to index PDF
+// or Word documents. Extractors are registered per MIME type with
+// [RegisterExtractor].
+type Extractor interface {
+	// Extract returns the text of data and optional metadata to attach
+	// to the document, such as a title or page count.
+	Extract(ctx context.Context, data []byte, mimeType string) (text string, metadata map[string]any, err error)
+}
+
+// ExtractorFunc adapts a function to the [Extractor] interface.
+type ExtractorFunc func(ctx context.Context, data []byte, mimeType string) (string, map[string]any, error)
+
+// Extract implements [Extractor].
+func (f ExtractorFunc) Extract(ctx context.Context, data []byte, mimeType string) (string, map[string]any, error) {
+	return f(ctx, data, mimeType)
+}
+
+// PlainText is the default [Extractor] for text/* files. It returns the
+// content unchanged if it is valid UTF-8.
+var PlainText Extractor = ExtractorFunc(func(ctx context.Context, data []byte, mimeType string) (string, map[string]any, error) {
+	if !utf8.Valid(data) {
+		return "", nil, fmt.Errorf("content of type %s is not valid UTF-8", mimeType)
+	}
+	return string(data), nil, nil
+})
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   = make(map[string]Extractor)
+)
+
+// RegisterExtractor sets the extractor used by the ingestion flow and
+// [DirWatcher] for files of the given MIME type, such as
+// "application/pdf". A type of the form "image/*" matches all subtypes.
+// A nil extractor removes the registration.
+func RegisterExtractor(mimeType string, e Extractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	if e == nil {
+		delete(extractors, mimeType)
+		return
+	}
+	extractors[mimeType] = e
+}
+
+// extractor returns the extractor for a MIME type, falling back to a
+// wildcard registration and then to [PlainText] for text types.
+func extractor(mimeType string) Extractor {
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+	if e, ok := extractors[mimeType]; ok {
+		return e
+	}
+	major, _, _ := strings.Cut(mimeType, "/")
+	if e, ok := extractors[major+"/*"]; ok {
+		return e
+	}
+	if major == "text" {
+		return PlainText
+	}
+	return nil
+}
+
+// fileMIMEType returns the MIME type of a file from its extension, or
+// from its content if the extension is unknown.
+func fileMIMEType(path string, data []byte) string {
+	t := mime.TypeByExtension(filepath.Ext(path))
+	if t == "" {
+		t = http.DetectContentType(data)
+	}
+	if mt, _, err := mime.ParseMediaType(t); err == nil {
+		return mt
+	}
+	return t
+}
+
+// readDocument reads a file into a document with the extractor of its
+// MIME type. The path is recorded under the "source" metadata key.
+func readDocument(ctx context.Context, path string) (*ai.Document, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return extractDocument(ctx, path, b)
+}
+
+func extractDocument(ctx context.Context, path string, data []byte) (*ai.Document, error) {
+	mimeType := fileMIMEType(path, data)
+	e := extractor(mimeType)
+	if e == nil {
+		return nil, fmt.Errorf("no extractor registered for %s files (%s)", mimeType, path)
+	}
+	text, metadata, err := e.Extract(ctx, data, mimeType)
+	if err != nil {
+		return nil, fmt.Errorf("extracting %s: %v", path, err)
+	}
+	md := make(map[string]any, len(metadata)+1)
+	maps.Copy(md, metadata)
+	md[sourceKey] = path
+	return ai.DocumentFromText(text, md), nil
+}
@@ -0,0 +1,32 @@
+package qdrant
+
+import (
+	"context"
+	"testing"
+)
+
+func TestExtractDocument(t *testing.T) {
+	ctx := context.Background()
+	doc, err := extractDocument(ctx, "notes.txt", []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if documentText(doc) != "hello" || doc.Metadata[sourceKey] != "notes.txt" {
+		t.Errorf("got %q with metadata %v", documentText(doc), doc.Metadata)
+	}
+
+	if _, err := extractDocument(ctx, "report.pdf", []byte("%PDF-1.7")); err == nil {
+		t.Error("expected an error without a PDF extractor")
+	}
+	RegisterExtractor("application/pdf", ExtractorFunc(func(ctx context.Context, data []byte, mimeType string) (string, map[string]any, error) {
+		return "report text", map[string]any{"pages": 3}, nil
+	}))
+	defer RegisterExtractor("application/pdf", nil)
+	doc, err = extractDocument(ctx, "report.pdf", []byte("%PDF-1.7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if documentText(doc) != "report text" || doc.Metadata["pages"] != 3 {
+		t.Errorf("got %q with metadata %v", documentText(doc), doc.Metadata)
+	}
+}
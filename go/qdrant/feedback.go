@@ -0,0 +1,158 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// FeedbackLabel is a relevance judgement on a retrieved document.
+type FeedbackLabel int
+
+const (
+	FeedbackDown FeedbackLabel = -1
+	FeedbackUp   FeedbackLabel = 1
+)
+
+// Payload keys of the points stored in the feedback collection. Point
+// IDs are content hashes, so feedback also records the collection and
+// namespace of the point, which may share the feedback collection with
+// others holding the same documents.
+const (
+	feedbackQueryKey      = "query_id"
+	feedbackPointKey      = "point_id"
+	feedbackCollectionKey = "collection"
+	feedbackNamespaceKey  = "namespace"
+	feedbackLabelKey      = "label"
+	feedbackTimeKey       = "time"
+)
+
+// ensureFeedbackCollection creates the vectorless feedback collection
+// if it does not exist yet.
+func (ds *DocStore) ensureFeedbackCollection(ctx context.Context) error {
+	exists, err := ds.client.CollectionExists(ctx, ds.feedbackCollection)
+	if err != nil {
+		return fmt.Errorf("qdrant failed to check feedback collection: %v", err)
+	}
+	if exists {
+		return nil
+	}
+	err = ds.client.CreateCollection(ctx, &qclient.CreateCollection{
+		CollectionName: ds.feedbackCollection,
+		VectorsConfig:  qclient.NewVectorsConfigMap(map[string]*qclient.VectorParams{}),
+	})
+	if err != nil {
+		return fmt.Errorf("qdrant failed to create feedback collection: %v", err)
+	}
+	for _, key := range []string{feedbackPointKey, feedbackCollectionKey, feedbackNamespaceKey} {
+		_, err = ds.client.CreateFieldIndex(ctx, &qclient.CreateFieldIndexCollection{
+			CollectionName: ds.feedbackCollection,
+			FieldName:      key,
+			FieldType:      qclient.FieldType_FieldTypeKeyword.Enum(),
+			Wait:           qclient.PtrOf(true),
+		})
+		if err != nil {
+			return fmt.Errorf("qdrant failed to index feedback collection: %v", err)
+		}
+	}
+	return nil
+}
+
+// RecordFeedback stores a relevance label for a point returned by the
+// query identified by queryID. Recording feedback again for the same
+// query and point replaces the previous label.
+func (ds *DocStore) RecordFeedback(ctx context.Context, queryID, pointID string, label FeedbackLabel) error {
+	if ds.feedbackCollection == "" {
+		return errors.New("qdrant: no feedback collection configured")
+	}
+	if label != FeedbackUp && label != FeedbackDown {
+		return fmt.Errorf("qdrant: invalid feedback label %d", label)
+	}
+	_, err := ds.client.Upsert(ctx, &qclient.UpsertPoints{
+		CollectionName: ds.feedbackCollection,
+		Points:         []*qclient.PointStruct{ds.feedbackPoint(queryID, pointID, label, time.Now())},
+	})
+	if err != nil {
+		return fmt.Errorf("qdrant failed to record feedback: %v", err)
+	}
+	return nil
+}
+
+// feedbackPoint returns the feedback point of a label, identified by the
+// query, the collection and namespace of the store, and the point.
+func (ds *DocStore) feedbackPoint(queryID, pointID string, label FeedbackLabel, now time.Time) *qclient.PointStruct {
+	id := uuid.NewSHA1(uuid.NameSpaceOID, []byte(queryID+"\x00"+ds.collectionName+"\x00"+ds.namespace+"\x00"+pointID))
+	payload := map[string]any{
+		feedbackQueryKey:      queryID,
+		feedbackPointKey:      pointID,
+		feedbackCollectionKey: ds.collectionName,
+		feedbackLabelKey:      int(label),
+		feedbackTimeKey:       now.UTC().Format(time.RFC3339),
+	}
+	if ds.namespace != "" {
+		payload[feedbackNamespaceKey] = ds.namespace
+	}
+	return &qclient.PointStruct{
+		Id:      qclient.NewID(id.String()),
+		Vectors: qclient.NewVectorsMap(map[string]*qclient.Vector{}),
+		Payload: qclient.NewValueMap(payload),
+	}
+}
+
+// feedbackFilter matches the feedback on the points ids of the collection
+// and namespace of the store.
+func (ds *DocStore) feedbackFilter(ids []string) *qclient.Filter {
+	namespace := qclient.NewIsEmpty(feedbackNamespaceKey)
+	if ds.namespace != "" {
+		namespace = qclient.NewMatchKeyword(feedbackNamespaceKey, ds.namespace)
+	}
+	return &qclient.Filter{
+		Must: []*qclient.Condition{
+			qclient.NewMatchKeywords(feedbackPointKey, ids...),
+			qclient.NewMatchKeyword(feedbackCollectionKey, ds.collectionName),
+			namespace,
+		},
+	}
+}
+
+// applyFeedback adds boost times the net feedback of each result to its
+// score and reorders the results accordingly.
+func (ds *DocStore) applyFeedback(ctx context.Context, results []*result, boost float32) error {
+	if ds.feedbackCollection == "" || len(results) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(results))
+	for _, r := range results {
+		ids = append(ids, r.id)
+	}
+	net := make(map[string]int)
+	err := ds.scroll(ctx, &qclient.ScrollPoints{
+		CollectionName: ds.feedbackCollection,
+		Filter:         ds.feedbackFilter(ids),
+		WithPayload:    qclient.NewWithPayloadInclude(feedbackPointKey, feedbackLabelKey),
+	}, func(p *qclient.RetrievedPoint) error {
+		net[p.Payload[feedbackPointKey].GetStringValue()] += int(p.Payload[feedbackLabelKey].GetIntegerValue())
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	rescore(results, net, boost)
+	return nil
+}
+
+// rescore adjusts the result scores by the net feedback and sorts the
+// results by descending score.
+func rescore(results []*result, net map[string]int, boost float32) {
+	for _, r := range results {
+		r.score += boost * float32(net[r.id])
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+}
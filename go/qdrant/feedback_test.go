@@ -0,0 +1,46 @@
+package qdrant
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRescore(t *testing.T) {
+	results := []*result{
+		{id: "a", score: 0.9},
+		{id: "b", score: 0.85},
+		{id: "c", score: 0.8},
+	}
+	rescore(results, map[string]int{"a": -2, "c": 1}, 0.1)
+
+	var order []string
+	for _, r := range results {
+		order = append(order, r.id)
+	}
+	if got := order[0] + order[1] + order[2]; got != "cba" {
+		t.Errorf("got order %q, want %q", got, "cba")
+	}
+}
+
+func TestFeedbackScope(t *testing.T) {
+	docs, faq := &DocStore{collectionName: "docs"}, &DocStore{collectionName: "faq", namespace: "app"}
+	now := time.Now()
+	a, b := docs.feedbackPoint("q", "p", FeedbackUp, now), faq.feedbackPoint("q", "p", FeedbackUp, now)
+	if a.GetId().GetUuid() == b.GetId().GetUuid() {
+		t.Error("feedback on the same point of two collections shares its ID")
+	}
+	if got := b.GetPayload()[feedbackCollectionKey].GetStringValue(); got != "faq" {
+		t.Errorf("got collection %q, want faq", got)
+	}
+	if _, ok := a.GetPayload()[feedbackNamespaceKey]; ok {
+		t.Error("namespace stored without a namespace")
+	}
+
+	must := faq.feedbackFilter([]string{"p"}).GetMust()
+	if len(must) != 3 || must[1].GetField().GetMatch().GetKeyword() != "faq" || must[2].GetField().GetMatch().GetKeyword() != "app" {
+		t.Errorf("got %v, want the feedback of the faq collection in namespace app", must)
+	}
+	if must := docs.feedbackFilter([]string{"p"}).GetMust(); must[2].GetIsEmpty().GetKey() != feedbackNamespaceKey {
+		t.Errorf("got %v, want the feedback without namespace", must[2])
+	}
+}
@@ -0,0 +1,102 @@
+package qdrant
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/google/uuid"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// MatchUUID returns a condition matching points whose field holds the given
+// UUID. The field is a full payload path such as "_metadata.user_id".
+// The UUID is normalized to its canonical form before matching.
+func MatchUUID(field, id string) (*qclient.Condition, error) {
+	u, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("qdrant: invalid UUID %q for field %q: %v", id, field, err)
+	}
+	return qclient.NewMatchKeyword(field, u.String()), nil
+}
+
+// MatchUUIDs returns a condition matching points whose field holds any of
+// the given UUIDs.
+func MatchUUIDs(field string, ids ...string) (*qclient.Condition, error) {
+	values := make([]string, 0, len(ids))
+	for _, id := range ids {
+		u, err := uuid.Parse(id)
+		if err != nil {
+			return nil, fmt.Errorf("qdrant: invalid UUID %q for field %q: %v", id, field, err)
+		}
+		values = append(values, u.String())
+	}
+	return qclient.NewMatchKeywords(field, values...), nil
+}
+
+// equalityFilter converts a map of metadata keys to values into a filter
+// requiring every key to match its value. Strings match keywords, bools
+// match booleans and whole numbers match integers.
+func (ds *DocStore) equalityFilter(where map[string]any) (*qclient.Filter, error) {
+	if len(where) == 0 {
+		return nil, nil
+	}
+	keys := make([]string, 0, len(where))
+	for k := range where {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	conds := make([]*qclient.Condition, 0, len(keys))
+	for _, k := range keys {
+		field := ds.metadataField(k)
+		switch v := where[k].(type) {
+		case string:
+			conds = append(conds, qclient.NewMatchKeyword(field, v))
+		case bool:
+			conds = append(conds, qclient.NewMatchBool(field, v))
+		case int:
+			conds = append(conds, qclient.NewMatchInt(field, int64(v)))
+		case int64:
+			conds = append(conds, qclient.NewMatchInt(field, v))
+		case float64:
+			if v != math.Trunc(v) {
+				return nil, fmt.Errorf("qdrant: cannot match non-integer number %v for %q", v, k)
+			}
+			conds = append(conds, qclient.NewMatchInt(field, int64(v)))
+		default:
+			return nil, fmt.Errorf("qdrant: cannot match value of type %T for %q", v, k)
+		}
+	}
+	return &qclient.Filter{Must: conds}, nil
+}
+
+// retrieverFilter returns the filter of the retriever options combined with
+// the conditions of their Where map, restricted to the namespace and
+// environment of the store.
+func (ds *DocStore) retrieverFilter(ropt *RetrieverOptions) (*qclient.Filter, error) {
+	filter, err := ds.optionsFilter(ropt)
+	if err != nil {
+		return nil, err
+	}
+	return ds.scopeFilter(ds.envFilter(filter, ropt)), nil
+}
+
+// optionsFilter returns the filter of the retriever options combined with
+// the conditions of their Where map.
+func (ds *DocStore) optionsFilter(ropt *RetrieverOptions) (*qclient.Filter, error) {
+	where, err := ds.equalityFilter(ropt.Where)
+	if err != nil {
+		return nil, err
+	}
+	if where == nil {
+		return &ropt.Filter, nil
+	}
+	must := make([]*qclient.Condition, 0, len(ropt.Filter.Must)+len(where.Must))
+	must = append(must, ropt.Filter.Must...)
+	return &qclient.Filter{
+		Must:      append(must, where.Must...),
+		Should:    ropt.Filter.Should,
+		MustNot:   ropt.Filter.MustNot,
+		MinShould: ropt.Filter.MinShould,
+	}, nil
+}
@@ -0,0 +1,150 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// FilterIssue is a condition of a filter that the collection cannot serve
+// as is, reported by [DocStore.ValidateFilter].
+type FilterIssue struct {
+	// Key is the payload path of the condition, e.g. "_metadata.lang".
+	Key string
+	// Want lists the index types that can serve the condition.
+	Want []PayloadIndexType
+	// Got is the type of the existing index on Key, or "" if there is none.
+	Got PayloadIndexType
+}
+
+func (i FilterIssue) String() string {
+	want := make([]string, len(i.Want))
+	for j, t := range i.Want {
+		want[j] = string(t)
+	}
+	if i.Got == "" {
+		return fmt.Sprintf("no payload index on %q, want %s", i.Key, strings.Join(want, " or "))
+	}
+	return fmt.Sprintf("payload index on %q has type %s, want %s", i.Key, i.Got, strings.Join(want, " or "))
+}
+
+// Index types reported by [DocStore.ValidateFilter] that [PayloadIndexSpec]
+// cannot create.
+const (
+	payloadIndexText PayloadIndexType = "text"
+	payloadIndexGeo  PayloadIndexType = "geo"
+)
+
+// schemaIndexTypes maps the payload schema types of Qdrant to index types.
+var schemaIndexTypes = map[qclient.PayloadSchemaType]PayloadIndexType{
+	qclient.PayloadSchemaType_Keyword:  PayloadIndexKeyword,
+	qclient.PayloadSchemaType_Integer:  PayloadIndexInteger,
+	qclient.PayloadSchemaType_Float:    PayloadIndexFloat,
+	qclient.PayloadSchemaType_Geo:      payloadIndexGeo,
+	qclient.PayloadSchemaType_Text:     payloadIndexText,
+	qclient.PayloadSchemaType_Bool:     PayloadIndexBool,
+	qclient.PayloadSchemaType_Datetime: PayloadIndexDatetime,
+	qclient.PayloadSchemaType_Uuid:     PayloadIndexUUID,
+}
+
+// ValidateFilter checks the field conditions of a filter against the
+// payload indexes of the collection, without running a query. It returns
+// one issue per condition on a field that has no index or an index of a
+// type that cannot serve the condition, so that filters can be checked
+// before they fail in production on collections requiring indexes.
+func (ds *DocStore) ValidateFilter(ctx context.Context, filter *qclient.Filter) ([]FilterIssue, error) {
+	schema, err := ds.payloadSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return filterIssues(filter, schema), nil
+}
+
+// payloadSchema returns the index type of each indexed payload path. It
+// is cached for Config.SchemaCacheTTL.
+func (ds *DocStore) payloadSchema(ctx context.Context) (map[string]PayloadIndexType, error) {
+	if schema, ok := ds.schema.get(time.Now()); ok {
+		return schema, nil
+	}
+	info, err := ds.client.GetCollectionInfo(ctx, ds.collectionName)
+	if err != nil {
+		return nil, fmt.Errorf("qdrant failed to fetch collection info: %v", err)
+	}
+	schema := make(map[string]PayloadIndexType, len(info.GetPayloadSchema()))
+	for key, s := range info.GetPayloadSchema() {
+		schema[key] = schemaIndexTypes[s.GetDataType()]
+	}
+	ds.schema.set(schema, time.Now())
+	return schema, nil
+}
+
+// filterIssues returns the issues of filter against schema, sorted by key.
+func filterIssues(filter *qclient.Filter, schema map[string]PayloadIndexType) []FilterIssue {
+	var issues []FilterIssue
+	for _, req := range indexRequirements(filter, "") {
+		got, ok := schema[req.Key]
+		if ok && slices.Contains(req.Want, got) {
+			continue
+		}
+		issues = append(issues, FilterIssue{Key: req.Key, Want: req.Want, Got: got})
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Key < issues[j].Key })
+	return issues
+}
+
+// indexRequirement is the index types able to serve a field condition.
+type indexRequirement struct {
+	Key  string
+	Want []PayloadIndexType
+}
+
+// indexRequirements returns the index requirements of the field
+// conditions of a filter, including nested filters. Keys of nested
+// conditions are prefixed with the path of their array.
+func indexRequirements(filter *qclient.Filter, prefix string) []indexRequirement {
+	var reqs []indexRequirement
+	conds := slices.Concat(filter.GetMust(), filter.GetShould(), filter.GetMustNot(), filter.GetMinShould().GetConditions())
+	for _, c := range conds {
+		switch c := c.GetConditionOneOf().(type) {
+		case *qclient.Condition_Field:
+			if want := conditionIndexTypes(c.Field); want != nil {
+				reqs = append(reqs, indexRequirement{Key: prefix + c.Field.GetKey(), Want: want})
+			}
+		case *qclient.Condition_Filter:
+			reqs = append(reqs, indexRequirements(c.Filter, prefix)...)
+		case *qclient.Condition_Nested:
+			reqs = append(reqs, indexRequirements(c.Nested.GetFilter(), prefix+c.Nested.GetKey()+"[].")...)
+		}
+	}
+	return reqs
+}
+
+// conditionIndexTypes returns the index types able to serve a field
+// condition, or nil if it needs none.
+func conditionIndexTypes(fc *qclient.FieldCondition) []PayloadIndexType {
+	switch {
+	case fc.GetMatch() != nil:
+		switch fc.GetMatch().GetMatchValue().(type) {
+		case *qclient.Match_Keyword, *qclient.Match_Keywords, *qclient.Match_ExceptKeywords:
+			return []PayloadIndexType{PayloadIndexKeyword, PayloadIndexUUID}
+		case *qclient.Match_Integer, *qclient.Match_Integers, *qclient.Match_ExceptIntegers:
+			return []PayloadIndexType{PayloadIndexInteger}
+		case *qclient.Match_Boolean:
+			return []PayloadIndexType{PayloadIndexBool}
+		case *qclient.Match_Text:
+			return []PayloadIndexType{payloadIndexText}
+		}
+	case fc.GetRange() != nil:
+		return []PayloadIndexType{PayloadIndexFloat, PayloadIndexInteger}
+	case fc.GetDatetimeRange() != nil:
+		return []PayloadIndexType{PayloadIndexDatetime}
+	case fc.GetGeoBoundingBox() != nil, fc.GetGeoRadius() != nil, fc.GetGeoPolygon() != nil:
+		return []PayloadIndexType{payloadIndexGeo}
+	}
+	return nil
+}
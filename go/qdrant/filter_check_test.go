@@ -0,0 +1,43 @@
+package qdrant
+
+import (
+	"testing"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestFilterIssues(t *testing.T) {
+	filter := &qclient.Filter{
+		Must: []*qclient.Condition{
+			qclient.NewMatch("_metadata.lang", "en"),
+			qclient.NewRange("_metadata.year", &qclient.Range{Gte: qclient.PtrOf(2020.0)}),
+			qclient.NewIsEmpty("_metadata.tags"),
+		},
+		Should: []*qclient.Condition{
+			qclient.NewFilterAsCondition(&qclient.Filter{
+				Must: []*qclient.Condition{qclient.NewMatchBool("_metadata.draft", false)},
+			}),
+			qclient.NewNestedFilter("_metadata.authors", &qclient.Filter{
+				Must: []*qclient.Condition{qclient.NewMatch("name", "ada")},
+			}),
+		},
+	}
+	schema := map[string]PayloadIndexType{
+		"_metadata.lang": PayloadIndexKeyword,
+		"_metadata.year": PayloadIndexDatetime,
+	}
+	issues := filterIssues(filter, schema)
+	want := []FilterIssue{
+		{Key: "_metadata.authors[].name", Want: []PayloadIndexType{PayloadIndexKeyword, PayloadIndexUUID}},
+		{Key: "_metadata.draft", Want: []PayloadIndexType{PayloadIndexBool}},
+		{Key: "_metadata.year", Want: []PayloadIndexType{PayloadIndexFloat, PayloadIndexInteger}, Got: PayloadIndexDatetime},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("got %v, want %v", issues, want)
+	}
+	for i := range want {
+		if issues[i].String() != want[i].String() {
+			t.Errorf("issue %d: got %q, want %q", i, issues[i], want[i])
+		}
+	}
+}
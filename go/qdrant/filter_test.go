@@ -0,0 +1,68 @@
+package qdrant
+
+import (
+	"testing"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestMatchUUID(t *testing.T) {
+	cond, err := MatchUUID("_metadata.user", "6BA7B810-9DAD-11D1-80B4-00C04FD430C8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cond.GetField().GetMatch().GetKeyword()
+	if want := "6ba7b810-9dad-11d1-80b4-00c04fd430c8"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := MatchUUIDs("_metadata.user", "6ba7b810-9dad-11d1-80b4-00c04fd430c8", "not-a-uuid"); err == nil {
+		t.Error("expected an error for an invalid UUID")
+	}
+}
+
+func TestEqualityFilter(t *testing.T) {
+	ds := &DocStore{metadataPayloadKey: metadataPayloadKey}
+	filter, err := ds.equalityFilter(map[string]any{"lang": "en", "year": float64(2024), "draft": false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	must := filter.GetMust()
+	if len(must) != 3 {
+		t.Fatalf("got %d conditions, want 3", len(must))
+	}
+	if f := must[0].GetField(); f.GetKey() != "_metadata.draft" || f.GetMatch().GetBoolean() {
+		t.Errorf("unexpected condition %v", f)
+	}
+	if f := must[1].GetField(); f.GetKey() != "_metadata.lang" || f.GetMatch().GetKeyword() != "en" {
+		t.Errorf("unexpected condition %v", f)
+	}
+	if f := must[2].GetField(); f.GetKey() != "_metadata.year" || f.GetMatch().GetInteger() != 2024 {
+		t.Errorf("unexpected condition %v", f)
+	}
+
+	if _, err := ds.equalityFilter(map[string]any{"score": 0.5}); err == nil {
+		t.Error("expected an error for a non-integer number")
+	}
+}
+
+func TestRetrieverFilter(t *testing.T) {
+	ds := &DocStore{metadataPayloadKey: metadataPayloadKey}
+	ropt := &RetrieverOptions{
+		Filter: qclient.Filter{
+			Must:    []*qclient.Condition{qclient.NewMatchKeyword("_metadata.lang", "en")},
+			MustNot: []*qclient.Condition{qclient.NewMatchBool("_metadata.draft", true)},
+		},
+		Where: map[string]any{"year": 2024},
+	}
+	filter, err := ds.retrieverFilter(ropt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filter.GetMust()) != 2 || len(filter.GetMustNot()) != 1 {
+		t.Errorf("got filter %v, want 2 must and 1 must-not conditions", filter)
+	}
+	if len(ropt.Filter.Must) != 1 {
+		t.Errorf("retrieverFilter modified the options filter")
+	}
+}
@@ -0,0 +1,120 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// DefaultPromptTemplate is the prompt used by [NewRAGFlow] when
+// [FlowConfig.PromptTemplate] is empty.
+const DefaultPromptTemplate = `Answer the question using only the numbered context below.
+Cite the context you use with its number in brackets, e.g. [1].
+If the context does not contain the answer, say that you don't know.
+
+Context:
+{{range .Documents}}[{{.Index}}] {{.Text}}
+{{end}}
+Question: {{.Question}}`
+
+// FlowConfig configures the flow defined by [NewRAGFlow].
+type FlowConfig struct {
+	Retriever ai.Retriever
+	Model     ai.Model
+	// PromptTemplate is a text/template executed with the fields
+	// Question and Documents; each document has an Index and a Text.
+	PromptTemplate string
+	// K is the number of documents retrieved per question.
+	K int
+}
+
+// RAGOutput is the output of the flow defined by [NewRAGFlow].
+type RAGOutput struct {
+	Answer string `json:"answer"`
+	// Citations are the context documents referenced by the answer.
+	Citations []Citation `json:"citations"`
+}
+
+// Citation is a context document referenced by a generated answer.
+type Citation struct {
+	Index    int            `json:"index"`
+	Text     string         `json:"text"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+type promptDocument struct {
+	Index int
+	Text  string
+}
+
+// NewRAGFlow defines a Genkit flow that retrieves context for a question,
+// generates an answer with the model and reports the cited documents.
+func NewRAGFlow(name string, cfg FlowConfig) (*genkit.Flow[string, *RAGOutput, struct{}], error) {
+	if cfg.Retriever == nil || cfg.Model == nil {
+		return nil, errors.New("qdrant RAG flow requires a retriever and a model")
+	}
+	text := cfg.PromptTemplate
+	if text == "" {
+		text = DefaultPromptTemplate
+	}
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("qdrant RAG flow prompt template: %v", err)
+	}
+
+	return genkit.DefineFlow(name, func(ctx context.Context, question string) (*RAGOutput, error) {
+		resp, err := ai.Retrieve(ctx, cfg.Retriever, ai.WithRetrieverText(question), ai.WithRetrieverOpts(&RetrieverOptions{K: cfg.K}))
+		if err != nil {
+			return nil, err
+		}
+
+		docs := make([]promptDocument, len(resp.Documents))
+		for i, d := range resp.Documents {
+			docs[i] = promptDocument{Index: i + 1, Text: documentText(d)}
+		}
+		var prompt strings.Builder
+		if err := tmpl.Execute(&prompt, map[string]any{"Question": question, "Documents": docs}); err != nil {
+			return nil, fmt.Errorf("qdrant RAG flow prompt template: %v", err)
+		}
+
+		answer, err := ai.GenerateText(ctx, cfg.Model, ai.WithTextPrompt(prompt.String()))
+		if err != nil {
+			return nil, err
+		}
+
+		out := &RAGOutput{Answer: answer}
+		for _, i := range citedIndexes(answer, len(docs)) {
+			out.Citations = append(out.Citations, Citation{
+				Index:    i,
+				Text:     docs[i-1].Text,
+				Metadata: resp.Documents[i-1].Metadata,
+			})
+		}
+		return out, nil
+	}), nil
+}
+
+var citationPattern = regexp.MustCompile(`\[(\d+)\]`)
+
+// citedIndexes returns the distinct 1-based document indexes cited in text
+// in order of first citation, ignoring indexes outside 1..n.
+func citedIndexes(text string, n int) []int {
+	seen := make(map[int]bool)
+	var indexes []int
+	for _, m := range citationPattern.FindAllStringSubmatch(text, -1) {
+		i, err := strconv.Atoi(m[1])
+		if err != nil || i < 1 || i > n || seen[i] {
+			continue
+		}
+		seen[i] = true
+		indexes = append(indexes, i)
+	}
+	return indexes
+}
@@ -0,0 +1,13 @@
+package qdrant
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCitedIndexes(t *testing.T) {
+	got := citedIndexes("Paris [2] is the capital [1][2], see also [7] and [x].", 3)
+	if want := []int{2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
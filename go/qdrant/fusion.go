@@ -0,0 +1,141 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// localFusion reports whether a query fuses single level dense or sparse
+// prefetches with RRF, which the client can do itself.
+func localFusion(query *qclient.QueryPoints) bool {
+	fusion, ok := query.GetQuery().GetVariant().(*qclient.Query_Fusion)
+	if !ok || fusion.Fusion != qclient.Fusion_RRF || len(query.Prefetch) == 0 {
+		return false
+	}
+	for _, p := range query.Prefetch {
+		in := p.GetQuery().GetNearest()
+		if len(p.Prefetch) > 0 || (in.GetDense() == nil && in.GetSparse() == nil) {
+			return false
+		}
+	}
+	return true
+}
+
+// queryFusion runs a query on the server. With Config.ClientFusion or
+// against servers without the Query API, fusion queries run their
+// prefetches as parallel searches fused on the client instead, and other
+// queries fail with an error naming the required version.
+func (ds *DocStore) queryFusion(ctx context.Context, query *qclient.QueryPoints, run func() ([]*qclient.ScoredPoint, error)) ([]*qclient.ScoredPoint, error) {
+	if localFusion(query) && (ds.clientFusion || !ds.Supports(ctx, FeatureQuery)) {
+		return ds.fuseLocally(ctx, query)
+	}
+	points, err := run()
+	if status.Code(err) == codes.Unimplemented {
+		if localFusion(query) {
+			slog.WarnContext(ctx, "qdrant server cannot fuse queries, fusing on the client", "collection", query.CollectionName)
+			return ds.fuseLocally(ctx, query)
+		}
+		if rerr := ds.require(ctx, FeatureQuery); rerr != nil {
+			return nil, rerr
+		}
+	}
+	return points, err
+}
+
+// fuseLocally searches the prefetches of a fusion query in parallel and
+// fuses their results with reciprocal rank fusion.
+func (ds *DocStore) fuseLocally(ctx context.Context, query *qclient.QueryPoints) ([]*qclient.ScoredPoint, error) {
+	lists := make([][]*qclient.ScoredPoint, len(query.Prefetch))
+	errs := make([]error, len(query.Prefetch))
+	var wg sync.WaitGroup
+	for i, p := range query.Prefetch {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := ds.dataClient().GetPointsClient().Search(ctx, prefetchSearch(query, p))
+			lists[i], errs[i] = resp.GetResult(), err
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("qdrant search failed: %w", err)
+		}
+	}
+	return rrfFuse(lists, int(query.GetOffset()), int(query.GetLimit())), nil
+}
+
+// prefetchSearch converts a prefetch of query into a search request.
+func prefetchSearch(query *qclient.QueryPoints, p *qclient.PrefetchQuery) *qclient.SearchPoints {
+	filter := p.Filter
+	if query.Filter != nil && !proto.Equal(query.Filter, p.Filter) {
+		filter = &qclient.Filter{Must: []*qclient.Condition{
+			qclient.NewFilterAsCondition(query.Filter),
+		}}
+		if p.Filter != nil {
+			filter.Must = append(filter.Must, qclient.NewFilterAsCondition(p.Filter))
+		}
+	}
+	limit := p.GetLimit()
+	if limit == 0 {
+		limit = query.GetOffset() + query.GetLimit()
+	}
+	req := &qclient.SearchPoints{
+		CollectionName:   query.CollectionName,
+		Filter:           filter,
+		Limit:            limit,
+		WithPayload:      query.WithPayload,
+		WithVectors:      query.WithVectors,
+		Params:           p.Params,
+		ScoreThreshold:   p.ScoreThreshold,
+		VectorName:       p.Using,
+		ReadConsistency:  query.ReadConsistency,
+		ShardKeySelector: query.ShardKeySelector,
+	}
+	in := p.GetQuery().GetNearest()
+	if sparse := in.GetSparse(); sparse != nil {
+		req.Vector = sparse.GetValues()
+		req.SparseIndices = &qclient.SparseIndices{Data: sparse.GetIndices()}
+	} else {
+		req.Vector = in.GetDense().GetData()
+	}
+	return req
+}
+
+// rrfFuse fuses ranked lists of points with reciprocal rank fusion and
+// returns limit of them after offset. A point keeps the payload of its
+// first occurrence.
+func rrfFuse(lists [][]*qclient.ScoredPoint, offset, limit int) []*qclient.ScoredPoint {
+	var fused []*qclient.ScoredPoint
+	byID := make(map[string]*qclient.ScoredPoint)
+	for _, list := range lists {
+		for rank, p := range list {
+			score := float32(1) / float32(rrfK+rank+1)
+			id := pointIDString(p.Id)
+			if f, ok := byID[id]; ok {
+				f.Score += score
+				continue
+			}
+			f := &qclient.ScoredPoint{Id: p.Id, Payload: p.Payload, Vectors: p.Vectors, Score: score}
+			byID[id] = f
+			fused = append(fused, f)
+		}
+	}
+	sort.SliceStable(fused, func(i, j int) bool { return fused[i].Score > fused[j].Score })
+	if offset >= len(fused) {
+		return nil
+	}
+	fused = fused[offset:]
+	if limit > 0 && limit < len(fused) {
+		fused = fused[:limit]
+	}
+	return fused
+}
@@ -0,0 +1,61 @@
+package qdrant
+
+import (
+	"testing"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestLocalFusion(t *testing.T) {
+	hybrid := &HybridConfig{SparseVector: "sparse", Fusion: qclient.Fusion_RRF}
+	query := &qclient.QueryPoints{
+		CollectionName: "docs",
+		Query:          qclient.NewQuery(1, 2),
+		Using:          qclient.PtrOf("dense"),
+		Limit:          qclient.PtrOf(uint64(5)),
+		Filter:         &qclient.Filter{Must: []*qclient.Condition{qclient.NewMatchKeyword("lang", "en")}},
+	}
+	if localFusion(query) {
+		t.Error("a dense query is not a fusion query")
+	}
+	fused := hybrid.hybridQuery(query, &SparseVector{Indices: []uint32{3}, Values: []float32{0.5}})
+	if !localFusion(fused) {
+		t.Fatalf("cannot fuse %v locally", fused)
+	}
+
+	dense := prefetchSearch(fused, fused.Prefetch[0])
+	if dense.GetVectorName() != "dense" || len(dense.Vector) != 2 || dense.Limit != 20 || !proto.Equal(dense.Filter, query.Filter) {
+		t.Errorf("got dense search %v", dense)
+	}
+	sparse := prefetchSearch(fused, fused.Prefetch[1])
+	if sparse.GetVectorName() != "sparse" || sparse.GetSparseIndices().GetData()[0] != 3 || sparse.Vector[0] != 0.5 {
+		t.Errorf("got sparse search %v", sparse)
+	}
+
+	rerank := (&MultiVectorConfig{Name: "colbert", Rerank: true}).multiQuery(query, [][]float32{{1}})
+	if localFusion(rerank) {
+		t.Error("a rerank query is not a fusion query")
+	}
+	nested := hybrid.hybridQuery(fused, &SparseVector{Indices: []uint32{3}, Values: []float32{0.5}})
+	if localFusion(nested) {
+		t.Error("nested prefetches cannot be fused locally")
+	}
+}
+
+func TestRRFFuse(t *testing.T) {
+	p := func(id string) *qclient.ScoredPoint { return &qclient.ScoredPoint{Id: qclient.NewID(id)} }
+	a := "00000000-0000-0000-0000-00000000000a"
+	b := "00000000-0000-0000-0000-00000000000b"
+	c := "00000000-0000-0000-0000-00000000000c"
+	got := rrfFuse([][]*qclient.ScoredPoint{{p(a), p(b)}, {p(b), p(c)}}, 0, 0)
+	if len(got) != 3 || pointIDString(got[0].Id) != b {
+		t.Fatalf("got %v, want b first", got)
+	}
+	if got := rrfFuse([][]*qclient.ScoredPoint{{p(a), p(b)}, {p(b), p(c)}}, 1, 1); len(got) != 1 || pointIDString(got[0].Id) != a {
+		t.Errorf("got page %v, want a", got)
+	}
+	if got := rrfFuse([][]*qclient.ScoredPoint{{p(a)}}, 2, 1); got != nil {
+		t.Errorf("got %v past the end", got)
+	}
+}
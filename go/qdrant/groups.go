@@ -0,0 +1,100 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// GroupKey holds the value of RetrieverOptions.GroupBy.Field shared by the
+// documents of a group, as a string.
+const GroupKey = "_group"
+
+// GroupBy groups the results by a metadata field, such as the source
+// document of chunks, so that a single long document cannot fill all K
+// results. K is then the number of groups.
+type GroupBy struct {
+	// Field is the metadata key the results are grouped by. It must hold
+	// strings or whole numbers; a point whose field holds a list belongs
+	// to the group of each value.
+	Field string `json:"field"`
+	// Size is the number of top hits returned per group. Defaults to 1,
+	// which deduplicates the results.
+	Size int `json:"size,omitempty"`
+}
+
+func (g *GroupBy) validate() error {
+	if g.Field == "" {
+		return errors.New("groupBy.field must not be empty")
+	}
+	if g.Size < 0 {
+		return errors.New("groupBy.size must not be negative")
+	}
+	return nil
+}
+
+// queryGroups runs query through the QueryGroups API and returns the hits
+// of the groups in order, along with the group of each hit.
+func (ds *DocStore) queryGroups(ctx context.Context, query *qclient.QueryPoints, g *GroupBy) ([]*qclient.ScoredPoint, []string, error) {
+	req := &qclient.QueryPointGroups{
+		CollectionName:   query.CollectionName,
+		Prefetch:         query.Prefetch,
+		Query:            query.Query,
+		Using:            query.Using,
+		Filter:           query.Filter,
+		Params:           query.Params,
+		ScoreThreshold:   query.ScoreThreshold,
+		WithPayload:      query.WithPayload,
+		WithVectors:      query.WithVectors,
+		LookupFrom:       query.LookupFrom,
+		Limit:            query.Limit,
+		GroupSize:        qclient.PtrOf(uint64(max(g.Size, 1))),
+		GroupBy:          ds.metadataField(g.Field),
+		ReadConsistency:  query.ReadConsistency,
+		Timeout:          query.Timeout,
+		ShardKeySelector: query.ShardKeySelector,
+	}
+	var groups []*qclient.PointGroup
+	err := ds.withFilterIndexes(ctx, queryFilters(query), func() (err error) {
+		groups, err = ds.dataClient().QueryGroups(ctx, req)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	var points []*qclient.ScoredPoint
+	var ids []string
+	for _, group := range groups {
+		id := groupID(group.GetId())
+		for _, hit := range group.GetHits() {
+			points = append(points, hit)
+			ids = append(ids, id)
+		}
+	}
+	return points, ids, nil
+}
+
+// groupID returns the string form of a group ID.
+func groupID(id *qclient.GroupId) string {
+	switch v := id.GetKind().(type) {
+	case *qclient.GroupId_StringValue:
+		return v.StringValue
+	case *qclient.GroupId_IntegerValue:
+		return strconv.FormatInt(v.IntegerValue, 10)
+	case *qclient.GroupId_UnsignedValue:
+		return strconv.FormatUint(v.UnsignedValue, 10)
+	}
+	return ""
+}
+
+// annotateGroups attaches its group to each result.
+func annotateGroups(results []*result, groups []string) {
+	for i, r := range results {
+		if r.doc.Metadata == nil {
+			r.doc.Metadata = make(map[string]any)
+		}
+		r.doc.Metadata[GroupKey] = groups[i]
+	}
+}
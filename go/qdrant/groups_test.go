@@ -0,0 +1,56 @@
+package qdrant
+
+import (
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestGroupID(t *testing.T) {
+	for _, c := range []struct {
+		id   *qclient.GroupId
+		want string
+	}{
+		{&qclient.GroupId{Kind: &qclient.GroupId_StringValue{StringValue: "a.pdf"}}, "a.pdf"},
+		{&qclient.GroupId{Kind: &qclient.GroupId_IntegerValue{IntegerValue: -3}}, "-3"},
+		{&qclient.GroupId{Kind: &qclient.GroupId_UnsignedValue{UnsignedValue: 7}}, "7"},
+		{nil, ""},
+	} {
+		if got := groupID(c.id); got != c.want {
+			t.Errorf("groupID(%v) = %q, want %q", c.id, got, c.want)
+		}
+	}
+}
+
+func TestGroupByOptions(t *testing.T) {
+	ropt, err := parseRetrieverOptions(map[string]any{"k": 3, "groupBy": map[string]any{"field": "source", "size": 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ropt.GroupBy.Field != "source" || ropt.GroupBy.Size != 2 {
+		t.Errorf("got %+v", ropt.GroupBy)
+	}
+	for _, bad := range []map[string]any{
+		{"groupBy": map[string]any{"size": 2}},
+		{"groupBy": map[string]any{"field": "source", "size": -1}},
+		{"groupBy": map[string]any{"field": "source"}, "offset": 5},
+		{"groupBy": map[string]any{"field": "source"}, "mmr": map[string]any{"lambda": 0.5}},
+	} {
+		if _, err := parseRetrieverOptions(bad); err == nil {
+			t.Errorf("%v: expected an error", bad)
+		}
+	}
+}
+
+func TestAnnotateGroups(t *testing.T) {
+	results := []*result{
+		{id: "1", doc: ai.DocumentFromText("a", nil)},
+		{id: "2", doc: ai.DocumentFromText("b", nil)},
+	}
+	annotateGroups(results, []string{"x.pdf", "y.pdf"})
+	resp := &ai.RetrieverResponse{Documents: []*ai.Document{results[0].doc, results[1].doc}}
+	if got := ResultsFromResponse(resp); got[0].Group != "x.pdf" || got[1].Group != "y.pdf" {
+		t.Errorf("got groups %q and %q", got[0].Group, got[1].Group)
+	}
+}
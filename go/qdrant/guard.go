@@ -0,0 +1,86 @@
+package qdrant
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// ErrTrivialQuery is returned by the retriever for queries rejected by
+// Config.QueryGuard with [GuardError].
+var ErrTrivialQuery = errors.New("qdrant: query is empty or too short to retrieve documents")
+
+// GuardPolicy selects what the retriever does with a trivial query.
+type GuardPolicy int
+
+const (
+	// GuardEmpty returns no documents.
+	GuardEmpty GuardPolicy = iota
+	// GuardError returns [ErrTrivialQuery].
+	GuardError
+)
+
+// defaultStopwords are the words ignored by a [QueryGuardConfig] without
+// Stopwords.
+var defaultStopwords = []string{
+	"a", "an", "and", "are", "as", "at", "be", "by", "do", "for", "from", "how",
+	"i", "in", "is", "it", "me", "my", "of", "on", "or", "so", "that", "the",
+	"this", "to", "was", "what", "when", "where", "who", "why", "with", "you",
+}
+
+// QueryGuardConfig detects empty or trivially short queries before they
+// are embedded, saving the embedding cost and the noise of matches for
+// queries like "hi" or "the".
+type QueryGuardConfig struct {
+	// MinWords is the number of words other than stopwords a query needs.
+	// Defaults to 1.
+	MinWords int
+	// MinChars is the number of letters and digits a query needs.
+	MinChars int
+	// Stopwords are the words that do not count towards MinWords,
+	// compared case-insensitively. Defaults to common English words;
+	// an empty, non-nil slice counts every word.
+	Stopwords []string
+	Policy    GuardPolicy
+}
+
+// queryGuard is a prepared [QueryGuardConfig].
+type queryGuard struct {
+	minWords, minChars int
+	stopwords          map[string]bool
+	policy             GuardPolicy
+}
+
+func newQueryGuard(cfg QueryGuardConfig) *queryGuard {
+	g := &queryGuard{minWords: cfg.MinWords, minChars: cfg.MinChars, policy: cfg.Policy}
+	if g.minWords <= 0 {
+		g.minWords = 1
+	}
+	stopwords := cfg.Stopwords
+	if stopwords == nil {
+		stopwords = defaultStopwords
+	}
+	g.stopwords = make(map[string]bool, len(stopwords))
+	for _, w := range stopwords {
+		g.stopwords[strings.ToLower(w)] = true
+	}
+	return g
+}
+
+// trivial reports whether a query falls short of the guard.
+func (g *queryGuard) trivial(text string) bool {
+	if g == nil {
+		return false
+	}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	var n, chars int
+	for _, w := range words {
+		chars += len([]rune(w))
+		if !g.stopwords[w] {
+			n++
+		}
+	}
+	return n < g.minWords || chars < g.minChars
+}
@@ -0,0 +1,33 @@
+package qdrant
+
+import "testing"
+
+func TestQueryGuard(t *testing.T) {
+	g := newQueryGuard(QueryGuardConfig{MinChars: 4})
+	for _, tc := range []struct {
+		text    string
+		trivial bool
+	}{
+		{"", true},
+		{"   ?!", true},
+		{"what is the", true},
+		{"Go", true},
+		{"qdrant", false},
+		{"What is a payload index?", false},
+	} {
+		if got := g.trivial(tc.text); got != tc.trivial {
+			t.Errorf("trivial(%q) = %v, want %v", tc.text, got, tc.trivial)
+		}
+	}
+
+	if newQueryGuard(QueryGuardConfig{Stopwords: []string{}}).trivial("the") {
+		t.Error("stopword counted with an empty stopword list")
+	}
+	if newQueryGuard(QueryGuardConfig{MinWords: 2}).trivial("payload indexes") {
+		t.Error("two-word query rejected with MinWords 2")
+	}
+	var disabled *queryGuard
+	if disabled.trivial("") {
+		t.Error("disabled guard rejected a query")
+	}
+}
@@ -0,0 +1,263 @@
+package qdrant
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// defaultVirtualNodes is the default number of ring positions per
+// collection of a hash router.
+const defaultVirtualNodes = 64
+
+// rrfK is the rank constant of reciprocal rank fusion.
+const rrfK = 60
+
+// HashRouterConfig configures [InitHashRouter].
+type HashRouterConfig struct {
+	// Name is the name of the indexer and retriever.
+	Name string
+	// Collections are the collection names of the stores, each set up
+	// with [Init] beforehand. The stores should share their point ID
+	// configuration, such as Config.IDMetadataKeys and Config.Namespace,
+	// since documents are routed by the point ID computed by the first.
+	Collections []string
+	// VirtualNodes is the number of positions of each collection on the
+	// hash ring. More positions spread documents more evenly. Defaults
+	// to 64.
+	VirtualNodes int
+}
+
+// HashRouter splits a corpus over several collections, e.g. on different
+// clusters, behind a single indexer and retriever. Each document goes to
+// one store by consistent hashing of its point ID, so adding a collection
+// only moves the documents the new collection takes over. Queries are
+// sent to all stores and their results merged by score, or fused by
+// reciprocal rank if the stores use different embedders or distances,
+// whose scores cannot be compared.
+type HashRouter struct {
+	stores []*DocStore
+	ring   []ringNode // sorted by hash
+	// byScore is set when the scores of the stores are comparable.
+	byScore bool
+}
+
+type ringNode struct {
+	hash  uint64
+	store int
+}
+
+// InitHashRouter defines an indexer and a retriever named cfg.Name that
+// route through the stores of cfg.Collections.
+func InitHashRouter(cfg HashRouterConfig) (*HashRouter, error) {
+	if cfg.Name == "" {
+		return nil, errors.New("qdrant: hash router name is required")
+	}
+	r, err := newHashRouter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	ai.DefineIndexer(provider, cfg.Name, r.Index)
+	ai.DefineRetriever(provider, cfg.Name, r.Retrieve)
+	return r, nil
+}
+
+func newHashRouter(cfg HashRouterConfig) (*HashRouter, error) {
+	if len(cfg.Collections) == 0 {
+		return nil, errors.New("qdrant: hash router needs at least one collection")
+	}
+	vnodes := cfg.VirtualNodes
+	if vnodes <= 0 {
+		vnodes = defaultVirtualNodes
+	}
+	stores := make([]*DocStore, len(cfg.Collections))
+	for i, name := range cfg.Collections {
+		if stores[i] = Store(name); stores[i] == nil {
+			return nil, fmt.Errorf("qdrant: collection %q was not initialized", name)
+		}
+	}
+	return buildHashRouter(stores, vnodes), nil
+}
+
+// buildHashRouter places vnodes positions per store on the ring.
+// Positions depend only on the collection name, so that the ring stays
+// the same when collections are added or reordered.
+func buildHashRouter(stores []*DocStore, vnodes int) *HashRouter {
+	r := &HashRouter{stores: stores, byScore: true}
+	for _, ds := range stores[1:] {
+		if embedderName(ds.embedder) != embedderName(stores[0].embedder) || ds.distance != stores[0].distance {
+			r.byScore = false
+		}
+	}
+	for i, ds := range stores {
+		for v := range vnodes {
+			r.ring = append(r.ring, ringNode{hash: ringHash(ds.collectionName + "#" + strconv.Itoa(v)), store: i})
+		}
+	}
+	sort.Slice(r.ring, func(i, j int) bool { return r.ring[i].hash < r.ring[j].hash })
+	return r
+}
+
+// ringHash hashes ring positions and point IDs. FNV spreads short,
+// similar keys poorly over the ring, so a cryptographic hash is used.
+func ringHash(s string) uint64 {
+	sum := sha256.Sum256([]byte(s))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// route returns the index of the store owning a point ID: the first ring
+// position at or after its hash.
+func (r *HashRouter) route(id string) int {
+	h := ringHash(id)
+	i := sort.Search(len(r.ring), func(i int) bool { return r.ring[i].hash >= h })
+	if i == len(r.ring) {
+		i = 0
+	}
+	return r.ring[i].store
+}
+
+// Index implements the genkit Indexer. Each store receives its documents
+// with the options of the request.
+func (r *HashRouter) Index(ctx context.Context, req *ai.IndexerRequest) error {
+	iopt, err := parseIndexerOptions(req.Options)
+	if err != nil {
+		return err
+	}
+	parts, err := r.split(req.Documents, iopt.Tenant)
+	if err != nil {
+		return err
+	}
+	for i, docs := range parts {
+		if len(docs) == 0 {
+			continue
+		}
+		if err := r.stores[i].Index(ctx, &ai.IndexerRequest{Documents: docs, Options: req.Options}); err != nil {
+			return fmt.Errorf("qdrant hash router: collection %q: %w", r.stores[i].collectionName, err)
+		}
+	}
+	return nil
+}
+
+// split returns the documents of each store. Documents are routed by
+// their point ID as computed by the first store, with the namespace,
+// environment, tenant and Config.IDMetadataKeys it applies.
+func (r *HashRouter) split(docs []*ai.Document, tenant string) ([][]*ai.Document, error) {
+	parts := make([][]*ai.Document, len(r.stores))
+	for _, doc := range docs {
+		id, err := r.stores[0].pointID(doc, tenant)
+		if err != nil {
+			return nil, err
+		}
+		s := r.route(id)
+		parts[s] = append(parts[s], doc)
+	}
+	return parts, nil
+}
+
+// Retrieve implements the genkit Retriever. The query is sent to every
+// store with the options of the request, and the results are ordered by
+// score, or by reciprocal rank fusion if the scores are not comparable,
+// and truncated to K. Documents keep the metadata of their store,
+// including their score.
+func (r *HashRouter) Retrieve(ctx context.Context, req *ai.RetrieverRequest) (*ai.RetrieverResponse, error) {
+	ropt, err := parseRetrieverOptions(req.Options)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]*ai.RetrieverResponse, len(r.stores))
+	errs := make([]error, len(r.stores))
+	var wg sync.WaitGroup
+	for i, ds := range r.stores {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i], errs[i] = ds.Retrieve(ctx, req)
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("qdrant hash router: collection %q: %w", r.stores[i].collectionName, err)
+		}
+	}
+	limit := cmp.Or(ropt.K, defaultQueryLimit)
+	if r.byScore {
+		return &ai.RetrieverResponse{Documents: mergeScores(responses, limit, lowerIsBetter(r.stores[0].distance))}, nil
+	}
+	return &ai.RetrieverResponse{Documents: fuseRanks(responses, limit)}, nil
+}
+
+// mergeScores merges lists ordered by the score metadata of their
+// documents, keeping at most limit documents.
+func mergeScores(responses []*ai.RetrieverResponse, limit int, ascending bool) []*ai.Document {
+	var docs []*ai.Document
+	for _, resp := range responses {
+		docs = append(docs, resp.Documents...)
+	}
+	slices.SortStableFunc(docs, func(a, b *ai.Document) int {
+		c := cmp.Compare(metadataNumber(b.Metadata, ScoreKey), metadataNumber(a.Metadata, ScoreKey))
+		if ascending {
+			return -c
+		}
+		return c
+	})
+	if len(docs) > limit {
+		docs = docs[:limit]
+	}
+	return docs
+}
+
+// lowerIsBetter reports whether lower scores are better for a distance.
+func lowerIsBetter(d qclient.Distance) bool {
+	return d == qclient.Distance_Euclid || d == qclient.Distance_Manhattan
+}
+
+// embedderName returns the name of an embedder, or "" for none.
+func embedderName(e ai.Embedder) string {
+	if e == nil {
+		return ""
+	}
+	return e.Name()
+}
+
+// fuseRanks merges ranked lists by reciprocal rank fusion, keeping at
+// most limit documents.
+func fuseRanks(responses []*ai.RetrieverResponse, limit int) []*ai.Document {
+	type fused struct {
+		doc   *ai.Document
+		score float64
+	}
+	var all []fused
+	for _, resp := range responses {
+		for rank, doc := range resp.Documents {
+			all = append(all, fused{doc, 1 / float64(rrfK+rank+1)})
+		}
+	}
+	slices.SortStableFunc(all, func(a, b fused) int {
+		switch {
+		case a.score > b.score:
+			return -1
+		case a.score < b.score:
+			return 1
+		}
+		return 0
+	})
+	if len(all) > limit {
+		all = all[:limit]
+	}
+	docs := make([]*ai.Document, len(all))
+	for i, f := range all {
+		docs[i] = f.doc
+	}
+	return docs
+}
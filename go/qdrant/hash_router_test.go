@@ -0,0 +1,120 @@
+package qdrant
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func testHashRouter(names ...string) *HashRouter {
+	stores := make([]*DocStore, len(names))
+	for i, name := range names {
+		stores[i] = &DocStore{collectionName: name}
+	}
+	return buildHashRouter(stores, defaultVirtualNodes)
+}
+
+func TestRouteIsStable(t *testing.T) {
+	r := testHashRouter("a", "b", "c")
+	counts := make([]int, 3)
+	for i := range 3000 {
+		id := fmt.Sprintf("doc-%d", i)
+		s := r.route(id)
+		if r.route(id) != s {
+			t.Fatalf("route(%s) is not deterministic", id)
+		}
+		counts[s]++
+	}
+	for i, n := range counts {
+		if n < 500 {
+			t.Errorf("collection %d got %d of 3000 documents", i, n)
+		}
+	}
+
+	// Adding a collection only moves documents to the new collection.
+	grown := testHashRouter("a", "b", "c", "d")
+	for i := range 3000 {
+		id := fmt.Sprintf("doc-%d", i)
+		if before, after := r.route(id), grown.route(id); before != after && after != 3 {
+			t.Fatalf("%s moved from %d to %d", id, before, after)
+		}
+	}
+}
+
+func TestFuseRanks(t *testing.T) {
+	doc := func(s string) *ai.Document { return ai.DocumentFromText(s, nil) }
+	docs := fuseRanks([]*ai.RetrieverResponse{
+		{Documents: []*ai.Document{doc("a1"), doc("a2"), doc("a3")}},
+		{Documents: []*ai.Document{doc("b1")}},
+	}, 3)
+	var got []string
+	for _, d := range docs {
+		got = append(got, documentText(d))
+	}
+	if want := []string{"a1", "b1", "a2"}; len(got) != 3 || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestMergeScores(t *testing.T) {
+	doc := func(s string, score float32) *ai.Document {
+		return ai.DocumentFromText(s, map[string]any{ScoreKey: score})
+	}
+	responses := []*ai.RetrieverResponse{
+		{Documents: []*ai.Document{doc("a1", 0.9), doc("a2", 0.8), doc("a3", 0.7)}},
+		{Documents: []*ai.Document{doc("b1", 0.5)}},
+	}
+	var got []string
+	for _, d := range mergeScores(responses, 3, false) {
+		got = append(got, documentText(d))
+	}
+	if want := []string{"a1", "a2", "a3"}; len(got) != 3 || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if d := mergeScores(responses, 1, true); documentText(d[0]) != "b1" {
+		t.Errorf("got %s first, want the lowest distance", documentText(d[0]))
+	}
+
+	if r := testHashRouter("a", "b"); !r.byScore {
+		t.Error("stores sharing their embedder should be merged by score")
+	}
+	r := buildHashRouter([]*DocStore{{collectionName: "a"}, {collectionName: "b", embedder: failingEmbedder{}}}, 8)
+	if r.byScore {
+		t.Error("stores with different embedders should be fused by rank")
+	}
+}
+
+func TestHashRouterSplit(t *testing.T) {
+	r := testHashRouter("a", "b", "c")
+	for _, ds := range r.stores {
+		ds.namespace = "ns"
+		ds.idMetadataKeys = []string{"url"}
+	}
+	docs := make([]*ai.Document, 100)
+	for i := range docs {
+		docs[i] = ai.DocumentFromText(fmt.Sprintf("doc-%d", i), map[string]any{"url": fmt.Sprintf("u%d", i%10), "fetched": i})
+	}
+	parts, err := r.split(docs, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Documents are routed by the point ID the store gives them, so
+	// documents sharing it share a collection.
+	owner := make(map[string]int)
+	for s, part := range parts {
+		for _, doc := range part {
+			id, err := r.stores[0].pointID(doc, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := r.route(id); s != want {
+				t.Errorf("%s routed to %d, want %d", documentText(doc), s, want)
+			}
+			if o, ok := owner[id]; ok && o != s {
+				t.Errorf("point %s routed to %d and %d", id, o, s)
+			}
+			owner[id] = s
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package qdrant
+
+import (
+	"errors"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// HNSWConfig tunes the HNSW index of an auto-created collection, trading
+// recall against latency, memory and indexing time. Zero values keep the
+// defaults of Qdrant.
+type HNSWConfig struct {
+	// M is the number of edges per node of the graph. Higher values give
+	// better recall for more memory.
+	M int
+	// EfConstruct is the number of neighbours considered while building
+	// the graph. Higher values give better recall for slower indexing.
+	EfConstruct int
+	// OnDisk stores the graph on disk instead of in memory.
+	OnDisk bool
+	// PayloadM is the number of edges per node of the graphs built for
+	// each value of the tenant payload indexes, e.g. of Config.TenantKey.
+	PayloadM int
+	// PayloadOnly skips the global graph and only builds the per-tenant
+	// graphs of PayloadM, as recommended when every query is restricted
+	// to a tenant. It requires PayloadM: Qdrant would otherwise take the
+	// zero M for the per-tenant graphs and build no graph at all.
+	PayloadOnly bool
+}
+
+func (h *HNSWConfig) validate() error {
+	if h.M < 0 || h.EfConstruct < 0 || h.PayloadM < 0 {
+		return errors.New("qdrant: Config.HNSW values must not be negative")
+	}
+	if h.PayloadOnly && h.M != 0 {
+		return errors.New("qdrant: Config.HNSW.PayloadOnly cannot be combined with M")
+	}
+	if h.PayloadOnly && h.PayloadM == 0 {
+		return errors.New("qdrant: Config.HNSW.PayloadOnly requires PayloadM")
+	}
+	return nil
+}
+
+// diff returns the HNSW configuration of the collection, or nil for the
+// defaults of Qdrant.
+func (h *HNSWConfig) diff() *qclient.HnswConfigDiff {
+	if h == nil {
+		return nil
+	}
+	d := &qclient.HnswConfigDiff{OnDisk: optionalBool(h.OnDisk)}
+	if h.M > 0 {
+		d.M = qclient.PtrOf(uint64(h.M))
+	}
+	if h.PayloadOnly {
+		d.M = qclient.PtrOf(uint64(0))
+	}
+	if h.EfConstruct > 0 {
+		d.EfConstruct = qclient.PtrOf(uint64(h.EfConstruct))
+	}
+	if h.PayloadM > 0 {
+		d.PayloadM = qclient.PtrOf(uint64(h.PayloadM))
+	}
+	return d
+}
@@ -0,0 +1,26 @@
+package qdrant
+
+import "testing"
+
+func TestHNSWDiff(t *testing.T) {
+	var none *HNSWConfig
+	if none.diff() != nil {
+		t.Error("got a configuration without HNSW settings")
+	}
+
+	d := (&HNSWConfig{M: 32, EfConstruct: 200, OnDisk: true}).diff()
+	if d.GetM() != 32 || d.GetEfConstruct() != 200 || !d.GetOnDisk() || d.PayloadM != nil {
+		t.Errorf("got %v", d)
+	}
+
+	d = (&HNSWConfig{PayloadM: 16, PayloadOnly: true}).diff()
+	if d.M == nil || d.GetM() != 0 || d.GetPayloadM() != 16 {
+		t.Errorf("got %v, want m 0 and payload_m 16", d)
+	}
+
+	for _, bad := range []HNSWConfig{{M: -1}, {M: 16, PayloadOnly: true}, {PayloadOnly: true}} {
+		if err := bad.validate(); err == nil {
+			t.Errorf("%+v: expected an error", bad)
+		}
+	}
+}
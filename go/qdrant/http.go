@@ -0,0 +1,137 @@
+package qdrant
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// IngestHandler is an [http.Handler] indexing the documents posted to it,
+// so that external systems can push content without writing Go code.
+//
+// Requests are JSON objects of the form
+//
+//	{"documents": [{"text": "...", "metadata": {...}}], "metadata": {...}}
+//
+// where the top-level metadata is added to every document. Documents are
+// chunked and indexed in batches; the response is an [IngestOutput].
+type IngestHandler struct {
+	Indexer ai.Indexer
+	// Token, if set, must be sent as a bearer token in the Authorization
+	// header.
+	Token string
+	// Chunk configures how documents are split before indexing.
+	Chunk ChunkOptions
+	// BatchSize is the number of chunks per index request. Defaults to 64.
+	BatchSize int
+	// MaxConcurrent is the number of requests indexed at once. Further
+	// requests are rejected with 429 Too Many Requests until one
+	// completes. Defaults to 4.
+	MaxConcurrent int
+	// MaxBodyBytes limits the size of a request. Defaults to 10 MiB.
+	MaxBodyBytes int64
+
+	once  sync.Once
+	slots chan struct{}
+}
+
+type ingestRequest struct {
+	Documents []struct {
+		Text     string         `json:"text"`
+		Metadata map[string]any `json:"metadata,omitempty"`
+	} `json:"documents"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// ServeHTTP implements [http.Handler].
+func (h *IngestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	select {
+	case h.semaphore() <- struct{}{}:
+		defer func() { <-h.slots }()
+	default:
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "too many concurrent ingestions", http.StatusTooManyRequests)
+		return
+	}
+
+	maxBytes := h.MaxBodyBytes
+	if maxBytes <= 0 {
+		maxBytes = 10 << 20
+	}
+	var req ingestRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), status)
+		return
+	}
+	if len(req.Documents) == 0 {
+		http.Error(w, "invalid request: no documents", http.StatusBadRequest)
+		return
+	}
+
+	docs := make([]*ai.Document, len(req.Documents))
+	for i, d := range req.Documents {
+		docs[i] = ai.DocumentFromText(d.Text, d.Metadata)
+	}
+	chunks := chunkDocuments(docs, req.Metadata, h.Chunk)
+	size := h.BatchSize
+	if size <= 0 {
+		size = 64
+	}
+	for start := 0; start < len(chunks); start += size {
+		batch := chunks[start:min(start+size, len(chunks))]
+		if err := h.Indexer.Index(r.Context(), &ai.IndexerRequest{Documents: batch}); err != nil {
+			slog.ErrorContext(r.Context(), "qdrant ingest handler failed", "error", err)
+			http.Error(w, "indexing failed", http.StatusBadGateway)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&IngestOutput{Documents: len(docs), Chunks: len(chunks)})
+}
+
+func (h *IngestHandler) authorized(r *http.Request) bool {
+	if h.Token == "" {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(h.Token)) == 1
+}
+
+// semaphore returns the channel limiting concurrent ingestions, creating
+// it on first use.
+func (h *IngestHandler) semaphore() chan struct{} {
+	h.once.Do(func() {
+		n := h.MaxConcurrent
+		if n <= 0 {
+			n = 4
+		}
+		h.slots = make(chan struct{}, n)
+	})
+	return h.slots
+}
@@ -0,0 +1,50 @@
+package qdrant
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIngestHandler(t *testing.T) {
+	indexer := &fakeIndexer{}
+	h := &IngestHandler{Indexer: indexer, Token: "secret", BatchSize: 1}
+
+	post := func(token, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/ingest", strings.NewReader(body))
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := post("wrong", `{"documents": [{"text": "a"}]}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d with a wrong token, want 401", w.Code)
+	}
+	if w := post("secret", `{"docs": []}`); w.Code != http.StatusBadRequest {
+		t.Errorf("got status %d for an unknown field, want 400", w.Code)
+	}
+
+	w := post("secret", `{"documents": [{"text": "a", "metadata": {"lang": "en"}}, {"text": "b"}], "metadata": {"source": "cms"}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", w.Code, w.Body)
+	}
+	if len(indexer.indexed) != 2 {
+		t.Fatalf("indexed %d documents, want 2", len(indexer.indexed))
+	}
+	if md := indexer.indexed[0].Metadata; md["lang"] != "en" || md["source"] != "cms" {
+		t.Errorf("got metadata %v", md)
+	}
+
+	// A full handler rejects requests instead of queueing them.
+	h.semaphore()
+	for range cap(h.slots) {
+		h.slots <- struct{}{}
+	}
+	if w := post("secret", `{"documents": [{"text": "c"}]}`); w.Code != http.StatusTooManyRequests {
+		t.Errorf("got status %d when saturated, want 429", w.Code)
+	}
+}
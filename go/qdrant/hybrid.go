@@ -0,0 +1,194 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/protobuf/proto"
+)
+
+// SparseVector is a sparse vector, such as term weights.
+type SparseVector struct {
+	Indices []uint32
+	Values  []float32
+}
+
+// SparseEmbedder computes the sparse vectors of texts, e.g. BM25 term
+// weights or the output of a BM42 or SPLADE model. It is used alongside
+// the dense [ai.Embedder] of a store, see [HybridConfig].
+type SparseEmbedder interface {
+	Embed(ctx context.Context, texts []string) ([]SparseVector, error)
+}
+
+// TermHash is a [SparseEmbedder] mapping each lowercased word to a hashed
+// index weighted by its frequency. Combined with the IDF modifier Qdrant
+// applies to auto-created sparse vectors, it ranks documents like BM25.
+type TermHash struct{}
+
+// Embed implements [SparseEmbedder].
+func (TermHash) Embed(_ context.Context, texts []string) ([]SparseVector, error) {
+	vectors := make([]SparseVector, len(texts))
+	for i, text := range texts {
+		vectors[i] = termHash(text)
+	}
+	return vectors, nil
+}
+
+func termHash(text string) SparseVector {
+	counts := make(map[uint32]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, w := range words {
+		h := fnv.New32a()
+		h.Write([]byte(w))
+		counts[h.Sum32()]++
+	}
+	var v SparseVector
+	for i := range counts {
+		v.Indices = append(v.Indices, i)
+	}
+	sort.Slice(v.Indices, func(a, b int) bool { return v.Indices[a] < v.Indices[b] })
+	for _, i := range v.Indices {
+		v.Values = append(v.Values, float32(1+math.Log(float64(counts[i]))))
+	}
+	return v
+}
+
+// HybridConfig enables hybrid search: documents are indexed with a dense
+// and a sparse vector, and queries search both and fuse the results in
+// Qdrant, which improves recall for keyword-heavy queries.
+type HybridConfig struct {
+	// SparseVector is the name of the sparse vector. Defaults to "sparse".
+	SparseVector string
+	// Embedder computes the sparse vectors. Defaults to [TermHash].
+	Embedder SparseEmbedder
+	// Fusion combines the dense and sparse results. Defaults to
+	// reciprocal rank fusion.
+	Fusion qclient.Fusion
+	// PrefetchLimit is the number of candidates taken from each of the
+	// dense and sparse searches. Defaults to four times K, and at least 20.
+	PrefetchLimit int
+}
+
+func newHybrid(cfg HybridConfig) *HybridConfig {
+	if cfg.SparseVector == "" {
+		cfg.SparseVector = "sparse"
+	}
+	if cfg.Embedder == nil {
+		cfg.Embedder = TermHash{}
+	}
+	return &cfg
+}
+
+// encode returns the sparse vectors of document texts.
+func (h *HybridConfig) encode(ctx context.Context, texts []string) ([]SparseVector, error) {
+	vectors, err := h.Embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("qdrant sparse embedding failed: %v", err)
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("qdrant sparse embedding failed: embedder returned %d vectors for %d texts", len(vectors), len(texts))
+	}
+	return vectors, nil
+}
+
+// add adds the sparse vector of a document to its point. The dense vector
+// keeps its name; the unnamed vector is the one named "".
+func (h *HybridConfig) add(point *qclient.PointStruct, sparse SparseVector) {
+	setVector(point, h.SparseVector, qclient.NewVectorSparse(sparse.Indices, sparse.Values))
+}
+
+// sparseVectorsConfig returns the sparse vector configuration of an
+// auto-created collection, or nil without hybrid search.
+func (h *HybridConfig) sparseVectorsConfig() *qclient.SparseVectorConfig {
+	if h == nil {
+		return nil
+	}
+	return qclient.NewSparseVectorsConfig(map[string]*qclient.SparseVectorParams{
+		h.SparseVector: {Modifier: qclient.Modifier_Idf.Enum()},
+	})
+}
+
+// hybridQuery returns a copy of a dense query that also searches the
+// sparse vector and fuses both results. The score threshold and search
+// params apply to the dense search. It returns query itself if sparse is
+// nil, and a sparse query without score threshold if query has no dense
+// query.
+func (h *HybridConfig) hybridQuery(query *qclient.QueryPoints, sparse *SparseVector) *qclient.QueryPoints {
+	if h == nil || sparse == nil {
+		return query
+	}
+	if query.Query == nil {
+		q := proto.Clone(query).(*qclient.QueryPoints)
+		q.Query = qclient.NewQuerySparse(sparse.Indices, sparse.Values)
+		q.Using, q.ScoreThreshold = &h.SparseVector, nil
+		return q
+	}
+	limit := uint64(h.PrefetchLimit)
+	if limit == 0 {
+		limit = max(4*(query.GetOffset()+query.GetLimit()), 20)
+	}
+	q := proto.Clone(query).(*qclient.QueryPoints)
+	q.Prefetch = []*qclient.PrefetchQuery{
+		{
+			Prefetch:       q.Prefetch,
+			Query:          q.Query,
+			Using:          q.Using,
+			Filter:         q.Filter,
+			Params:         q.Params,
+			ScoreThreshold: q.ScoreThreshold,
+			Limit:          &limit,
+		},
+		{
+			Query:  qclient.NewQuerySparse(sparse.Indices, sparse.Values),
+			Using:  &h.SparseVector,
+			Filter: q.Filter,
+			Params: q.Params,
+			Limit:  &limit,
+		},
+	}
+	q.Query = qclient.NewQueryFusion(h.Fusion)
+	q.Using, q.Params, q.ScoreThreshold = nil, nil, nil
+	return q
+}
+
+// SearchMode selects the vectors searched by the retriever.
+type SearchMode string
+
+const (
+	SearchHybrid SearchMode = "hybrid"
+	SearchDense  SearchMode = "dense"
+	SearchSparse SearchMode = "sparse"
+)
+
+// querySparse returns the sparse vector of the query document, or nil if
+// the search mode does not use one.
+func (ds *DocStore) querySparse(ctx context.Context, doc *ai.Document, mode SearchMode) (*SparseVector, error) {
+	switch mode {
+	case SearchDense:
+		return nil, nil
+	case "":
+		if ds.hybrid == nil {
+			return nil, nil
+		}
+	case SearchHybrid, SearchSparse:
+		if ds.hybrid == nil {
+			return nil, fmt.Errorf("qdrant: %s search requires Config.Hybrid", mode)
+		}
+	default:
+		return nil, fmt.Errorf("qdrant: unknown search mode %q", mode)
+	}
+	vectors, err := ds.hybrid.encode(ctx, []string{documentText(doc)})
+	if err != nil {
+		return nil, err
+	}
+	return &vectors[0], nil
+}
@@ -0,0 +1,107 @@
+package qdrant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestTermHash(t *testing.T) {
+	vectors, err := TermHash{}.Embed(context.Background(), []string{"Go, go GO! qdrant", ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := vectors[0]
+	if len(v.Indices) != 2 || len(v.Values) != 2 {
+		t.Fatalf("got %+v, want two terms", v)
+	}
+	if v.Indices[0] >= v.Indices[1] {
+		t.Errorf("indices %v are not sorted", v.Indices)
+	}
+	var repeated float32
+	for _, w := range v.Values {
+		repeated = max(repeated, w)
+	}
+	if repeated <= 1 {
+		t.Errorf("got weights %v, want the repeated term weighted above 1", v.Values)
+	}
+	if len(vectors[1].Indices) != 0 {
+		t.Errorf("got %+v for an empty text", vectors[1])
+	}
+}
+
+func TestHybridQuery(t *testing.T) {
+	h := newHybrid(HybridConfig{})
+	query := &qclient.QueryPoints{
+		CollectionName: "docs",
+		Query:          qclient.NewQuery(1, 2),
+		Limit:          qclient.PtrOf(uint64(3)),
+		Filter:         &qclient.Filter{Must: []*qclient.Condition{qclient.NewMatch("lang", "en")}},
+		ScoreThreshold: qclient.PtrOf(float32(0.5)),
+	}
+	if got := h.hybridQuery(query, nil); got != query {
+		t.Error("query changed without a sparse vector")
+	}
+
+	q := h.hybridQuery(query, &SparseVector{Indices: []uint32{7}, Values: []float32{1}})
+	if len(q.Prefetch) != 2 || q.GetQuery().GetFusion() != qclient.Fusion_RRF || q.ScoreThreshold != nil {
+		t.Fatalf("got %v", q)
+	}
+	if dense := q.Prefetch[0]; dense.GetLimit() != 20 || dense.GetScoreThreshold() != 0.5 || dense.Filter == nil {
+		t.Errorf("got dense prefetch %v", dense)
+	}
+	if sparse := q.Prefetch[1]; sparse.GetUsing() != "sparse" || sparse.Filter == nil {
+		t.Errorf("got sparse prefetch %v", sparse)
+	}
+	if query.Prefetch != nil || query.ScoreThreshold == nil {
+		t.Error("original query was modified")
+	}
+
+	// Prefetches cover the results skipped by an offset.
+	query.Offset = qclient.PtrOf(uint64(7))
+	q = h.hybridQuery(query, &SparseVector{Indices: []uint32{7}, Values: []float32{1}})
+	if dense := q.Prefetch[0]; dense.GetLimit() != 40 || q.GetOffset() != 7 {
+		t.Errorf("got dense prefetch %v with offset", dense)
+	}
+	query.Offset = nil
+
+	query.Query = nil
+	q = h.hybridQuery(query, &SparseVector{Indices: []uint32{7}, Values: []float32{1}})
+	if q.GetQuery().GetNearest().GetSparse() == nil || q.GetUsing() != "sparse" || q.Prefetch != nil || q.ScoreThreshold != nil {
+		t.Errorf("got %v, want a sparse query", q)
+	}
+}
+
+func TestQuerySparseMode(t *testing.T) {
+	ctx := context.Background()
+	doc := ai.DocumentFromText("payload index", nil)
+	dense := &DocStore{}
+	if v, err := dense.querySparse(ctx, doc, ""); v != nil || err != nil {
+		t.Errorf("got %v, %v without hybrid search", v, err)
+	}
+	if _, err := dense.querySparse(ctx, doc, SearchSparse); err == nil {
+		t.Error("expected an error for sparse search without Config.Hybrid")
+	}
+	hybrid := &DocStore{hybrid: newHybrid(HybridConfig{})}
+	if v, err := hybrid.querySparse(ctx, doc, SearchDense); v != nil || err != nil {
+		t.Errorf("got %v, %v for dense search", v, err)
+	}
+	if v, err := hybrid.querySparse(ctx, doc, ""); v == nil || err != nil {
+		t.Errorf("got %v, %v, want a sparse vector", v, err)
+	}
+	if _, err := hybrid.querySparse(ctx, doc, "bm25"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
+
+func TestHybridAdd(t *testing.T) {
+	h := newHybrid(HybridConfig{SparseVector: "terms"})
+	point := &qclient.PointStruct{Vectors: pointVectors("", []float32{1, 2})}
+	h.add(point, SparseVector{Indices: []uint32{1}, Values: []float32{1}})
+	named := point.GetVectors().GetVectors().GetVectors()
+	if len(named[""].GetData()) != 2 || named["terms"].GetIndices() == nil {
+		t.Errorf("got vectors %v", named)
+	}
+}
@@ -0,0 +1,117 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// missingIndexPattern matches the error of Qdrant for a filter on a field
+// without the payload index the collection requires for it.
+var missingIndexPattern = regexp.MustCompile(`Index required but not found for "([^"]+)"`)
+
+// fieldTypes maps index types to the field types of Qdrant.
+var fieldTypes = map[PayloadIndexType]qclient.FieldType{
+	PayloadIndexKeyword:  qclient.FieldType_FieldTypeKeyword,
+	PayloadIndexInteger:  qclient.FieldType_FieldTypeInteger,
+	PayloadIndexFloat:    qclient.FieldType_FieldTypeFloat,
+	PayloadIndexDatetime: qclient.FieldType_FieldTypeDatetime,
+	PayloadIndexUUID:     qclient.FieldType_FieldTypeUuid,
+	PayloadIndexBool:     qclient.FieldType_FieldTypeBool,
+	payloadIndexText:     qclient.FieldType_FieldTypeText,
+	payloadIndexGeo:      qclient.FieldType_FieldTypeGeo,
+}
+
+// query runs a query, creating the payload index it misses and retrying
+// once if Config.AutoCreateFilterIndexes is set. Fusion queries fall back
+// to client-side fusion, see queryFusion.
+func (ds *DocStore) query(ctx context.Context, query *qclient.QueryPoints) ([]*qclient.ScoredPoint, error) {
+	var points []*qclient.ScoredPoint
+	err := ds.withFilterIndexes(ctx, queryFilters(query), func() (err error) {
+		points, err = ds.queryFusion(ctx, query, func() ([]*qclient.ScoredPoint, error) {
+			return ds.dataClient().Query(ctx, query)
+		})
+		return err
+	})
+	return points, err
+}
+
+// queryBatch is query for a batch of queries.
+func (ds *DocStore) queryBatch(ctx context.Context, batch *qclient.QueryBatchPoints) ([]*qclient.BatchResult, error) {
+	var filters []*qclient.Filter
+	for _, q := range batch.GetQueryPoints() {
+		filters = append(filters, queryFilters(q)...)
+	}
+	var results []*qclient.BatchResult
+	err := ds.withFilterIndexes(ctx, filters, func() (err error) {
+		results, err = ds.dataClient().QueryBatch(ctx, batch)
+		return err
+	})
+	return results, err
+}
+
+// withFilterIndexes runs fn and, if it fails on a missing payload index
+// and Config.AutoCreateFilterIndexes is set on a writable store, creates
+// the index with the type inferred from the conditions on its field in
+// filters and runs fn again.
+func (ds *DocStore) withFilterIndexes(ctx context.Context, filters []*qclient.Filter, fn func() error) error {
+	err := fn()
+	if err == nil || !ds.autoFilterIndexes || ds.readOnly {
+		return err
+	}
+	m := missingIndexPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	indexType, ok := inferIndexType(filters, m[1])
+	if !ok {
+		return err
+	}
+	// Another request may have created it in the meantime.
+	if schema, ok := ds.schema.get(time.Now()); ok && schema[m[1]] == indexType {
+		return fn()
+	}
+	slog.InfoContext(ctx, "qdrant creating missing payload index", "collection", ds.collectionName, "field", m[1], "type", indexType)
+	_, cerr := ds.client.CreateFieldIndex(ctx, &qclient.CreateFieldIndexCollection{
+		CollectionName: ds.collectionName,
+		FieldName:      m[1],
+		FieldType:      fieldTypes[indexType].Enum(),
+		Wait:           qclient.PtrOf(true),
+	})
+	if cerr != nil {
+		return fmt.Errorf("%w (creating the missing index failed: %v)", err, cerr)
+	}
+	ds.schema.addIndex(m[1], indexType)
+	return fn()
+}
+
+// inferIndexType returns the index type serving the conditions on key in
+// filters.
+func inferIndexType(filters []*qclient.Filter, key string) (PayloadIndexType, bool) {
+	for _, f := range filters {
+		for _, req := range indexRequirements(f, "") {
+			if req.Key == key {
+				return req.Want[0], true
+			}
+		}
+	}
+	return "", false
+}
+
+// queryFilters returns the filters of a query and of its prefetches.
+func queryFilters(query *qclient.QueryPoints) []*qclient.Filter {
+	filters := []*qclient.Filter{query.GetFilter()}
+	var walk func([]*qclient.PrefetchQuery)
+	walk = func(prefetch []*qclient.PrefetchQuery) {
+		for _, p := range prefetch {
+			filters = append(filters, p.GetFilter())
+			walk(p.GetPrefetch())
+		}
+	}
+	walk(query.GetPrefetch())
+	return filters
+}
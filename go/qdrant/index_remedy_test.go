@@ -0,0 +1,42 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestInferIndexType(t *testing.T) {
+	query := &qclient.QueryPoints{
+		Filter: &qclient.Filter{Must: []*qclient.Condition{qclient.NewMatch("_metadata.lang", "en")}},
+		Prefetch: []*qclient.PrefetchQuery{{
+			Filter: &qclient.Filter{Must: []*qclient.Condition{qclient.NewMatchInt("_metadata.year", 2024)}},
+		}},
+	}
+	filters := queryFilters(query)
+	if got, ok := inferIndexType(filters, "_metadata.year"); !ok || got != PayloadIndexInteger {
+		t.Errorf("got %q, %v for the prefetch condition, want integer", got, ok)
+	}
+	if got, ok := inferIndexType(filters, "_metadata.lang"); !ok || got != PayloadIndexKeyword {
+		t.Errorf("got %q, %v, want keyword", got, ok)
+	}
+	if _, ok := inferIndexType(filters, "_metadata.other"); ok {
+		t.Error("inferred a type for a field without conditions")
+	}
+}
+
+func TestWithFilterIndexesPassesOtherErrors(t *testing.T) {
+	missing := errors.New(`Bad request: Index required but not found for "_metadata.other" of one of the following types: [keyword]`)
+	for _, ds := range []*DocStore{{}, {autoFilterIndexes: true}} {
+		calls := 0
+		err := ds.withFilterIndexes(context.Background(), nil, func() error {
+			calls++
+			return missing
+		})
+		if !errors.Is(err, missing) || calls != 1 {
+			t.Errorf("autoFilterIndexes %v: got %v after %d calls, want the error after one call", ds.autoFilterIndexes, err, calls)
+		}
+	}
+}
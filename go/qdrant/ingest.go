@@ -0,0 +1,90 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// IngestFlowName is the name of the flow defined by [DefineIngestFlow].
+const IngestFlowName = "qdrant/ingest"
+
+// sourceKey is the metadata key holding the file a document was read from.
+const sourceKey = "source"
+
+// IngestInput is the input of the ingestion flow. Either Documents or
+// Path must be set.
+type IngestInput struct {
+	// Collection is the name of a collection configured with [Init].
+	Collection string         `json:"collection"`
+	Documents  []*ai.Document `json:"documents,omitempty"`
+	// Path is a local file to ingest as one document. Its text is read
+	// with the [Extractor] registered for its MIME type.
+	Path string `json:"path,omitempty"`
+	// Metadata is added to every ingested document.
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// IngestOutput is the output of the ingestion flow.
+type IngestOutput struct {
+	Documents int `json:"documents"`
+	Chunks    int `json:"chunks"`
+}
+
+// DefineIngestFlow defines a flow named [IngestFlowName] that chunks
+// documents and indexes them, so that ingestion can be run and traced
+// from the Genkit Dev UI.
+func DefineIngestFlow(opts ChunkOptions) *genkit.Flow[*IngestInput, *IngestOutput, struct{}] {
+	return genkit.DefineFlow(IngestFlowName, func(ctx context.Context, in *IngestInput) (*IngestOutput, error) {
+		return ingest(ctx, in, opts)
+	})
+}
+
+func ingest(ctx context.Context, in *IngestInput, opts ChunkOptions) (*IngestOutput, error) {
+	if in == nil {
+		return nil, errors.New("qdrant ingest: missing input")
+	}
+	if Store(in.Collection) == nil {
+		return nil, fmt.Errorf("qdrant ingest: collection %q is not configured", in.Collection)
+	}
+
+	docs := in.Documents
+	if in.Path != "" {
+		doc, err := readDocument(ctx, in.Path)
+		if err != nil {
+			return nil, fmt.Errorf("qdrant ingest: %v", err)
+		}
+		docs = append(docs, doc)
+	}
+	if len(docs) == 0 {
+		return nil, errors.New("qdrant ingest: no documents or path given")
+	}
+
+	chunks := chunkDocuments(docs, in.Metadata, opts)
+	if err := ai.Index(ctx, Indexer(in.Collection), ai.WithIndexerDocs(chunks...)); err != nil {
+		return nil, err
+	}
+	return &IngestOutput{Documents: len(docs), Chunks: len(chunks)}, nil
+}
+
+// chunkDocuments adds metadata to copies of docs and splits them into
+// chunks.
+func chunkDocuments(docs []*ai.Document, metadata map[string]any, opts ChunkOptions) []*ai.Document {
+	var chunks []*ai.Document
+	for _, d := range docs {
+		if len(metadata) > 0 {
+			md := maps.Clone(d.Metadata)
+			if md == nil {
+				md = make(map[string]any)
+			}
+			maps.Copy(md, metadata)
+			d = &ai.Document{Content: d.Content, Metadata: md}
+		}
+		chunks = append(chunks, Chunk(d, opts)...)
+	}
+	return chunks
+}
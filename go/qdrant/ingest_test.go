@@ -0,0 +1,13 @@
+package qdrant
+
+import (
+	"context"
+	"testing"
+)
+
+func TestIngestUnknownCollection(t *testing.T) {
+	_, err := ingest(context.Background(), &IngestInput{Collection: "not-initialized"}, ChunkOptions{})
+	if err == nil {
+		t.Error("expected an error for a collection without a store")
+	}
+}
@@ -0,0 +1,148 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// LoadTestConfig configures [DocStore.LoadTest].
+type LoadTestConfig struct {
+	// Collection is the collection written to and queried. It must
+	// already exist with vectors of size Dim. Random points are upserted,
+	// so it should not be a production collection.
+	Collection string
+	// Dim is the size of the generated vectors.
+	Dim int
+	// Concurrency is the number of concurrent workers. Defaults to 1.
+	Concurrency int
+	// BatchSize is the number of points per upsert. Defaults to 64.
+	BatchSize int
+	// Upserts is the total number of upsert batches.
+	Upserts int
+	// Queries is the total number of queries, run after the upserts.
+	Queries int
+	// K is the limit of each query. Defaults to 10.
+	K int
+}
+
+// LoadTestReport holds the measurements of a load test. Latencies are
+// in milliseconds.
+type LoadTestReport struct {
+	UpsertLatency    Distribution
+	QueryLatency     Distribution
+	PointsPerSecond  float64
+	QueriesPerSecond float64
+	Errors           int
+}
+
+// LoadTest measures upsert and query throughput of the store's client
+// against cfg.Collection.
+func (ds *DocStore) LoadTest(ctx context.Context, cfg LoadTestConfig) (*LoadTestReport, error) {
+	if cfg.Collection == "" || cfg.Dim <= 0 {
+		return nil, errors.New("qdrant load test requires a collection and a vector size")
+	}
+	if err := ds.writable("LoadTest"); err != nil {
+		return nil, err
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 64
+	}
+	if cfg.K <= 0 {
+		cfg.K = 10
+	}
+
+	report := &LoadTestReport{}
+	upserts, errs, elapsed := runLoad(ctx, cfg.Concurrency, cfg.Upserts, func(ctx context.Context) error {
+		points := make([]*qclient.PointStruct, cfg.BatchSize)
+		for i := range points {
+			points[i] = &qclient.PointStruct{
+				Id:      qclient.NewID(uuid.NewString()),
+				Vectors: qclient.NewVectorsDense(randomVector(cfg.Dim)),
+			}
+		}
+		_, err := ds.client.Upsert(ctx, &qclient.UpsertPoints{
+			CollectionName: cfg.Collection,
+			Points:         points,
+			Wait:           qclient.PtrOf(true),
+		})
+		return err
+	})
+	report.UpsertLatency = distribution(upserts)
+	report.Errors += errs
+	if elapsed > 0 {
+		report.PointsPerSecond = float64(len(upserts)*cfg.BatchSize) / elapsed.Seconds()
+	}
+
+	queries, errs, elapsed := runLoad(ctx, cfg.Concurrency, cfg.Queries, func(ctx context.Context) error {
+		_, err := ds.client.Query(ctx, &qclient.QueryPoints{
+			CollectionName: cfg.Collection,
+			Query:          qclient.NewQueryDense(randomVector(cfg.Dim)),
+			Limit:          qclient.PtrOf(uint64(cfg.K)),
+		})
+		return err
+	})
+	report.QueryLatency = distribution(queries)
+	report.Errors += errs
+	if elapsed > 0 {
+		report.QueriesPerSecond = float64(len(queries)) / elapsed.Seconds()
+	}
+	return report, ctx.Err()
+}
+
+// runLoad runs op n times across the given number of workers. It returns
+// the latencies of the successful calls in milliseconds, the number of
+// failed calls and the total elapsed time.
+func runLoad(ctx context.Context, workers, n int, op func(context.Context) error) ([]float64, int, time.Duration) {
+	jobs := make(chan struct{})
+	var (
+		mu        sync.Mutex
+		latencies []float64
+		errs      int
+		wg        sync.WaitGroup
+	)
+	start := time.Now()
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				t := time.Now()
+				err := op(ctx)
+				d := float64(time.Since(t)) / float64(time.Millisecond)
+				mu.Lock()
+				if err != nil {
+					errs++
+				} else {
+					latencies = append(latencies, d)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for range n {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- struct{}{}
+	}
+	close(jobs)
+	wg.Wait()
+	return latencies, errs, time.Since(start)
+}
+
+func randomVector(dim int) []float32 {
+	v := make([]float32, dim)
+	for i := range v {
+		v[i] = rand.Float32()*2 - 1
+	}
+	return v
+}
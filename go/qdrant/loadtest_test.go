@@ -0,0 +1,21 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRunLoad(t *testing.T) {
+	var calls atomic.Int32
+	latencies, errs, _ := runLoad(context.Background(), 4, 10, func(context.Context) error {
+		if calls.Add(1)%5 == 0 {
+			return errors.New("boom")
+		}
+		return nil
+	})
+	if calls.Load() != 10 || len(latencies) != 8 || errs != 2 {
+		t.Errorf("got %d calls, %d latencies, %d errors; want 10, 8, 2", calls.Load(), len(latencies), errs)
+	}
+}
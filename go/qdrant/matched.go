@@ -0,0 +1,137 @@
+package qdrant
+
+import (
+	"slices"
+	"strings"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// MatchedConditionsKey holds the indexes, in RetrieverOptions.Filter.Should,
+// of the conditions the document matches. It is only set when the filter
+// has should conditions. Conditions that cannot be evaluated from the
+// payload, such as full-text matches and nested filters, are never listed.
+const MatchedConditionsKey = "_matched_conditions"
+
+// annotateMatches attaches to each result the indexes of the should
+// conditions its payload matches.
+func annotateMatches(results []*result, should []*qclient.Condition) {
+	if len(should) == 0 {
+		return
+	}
+	for _, r := range results {
+		matched := []int{}
+		for i, c := range should {
+			if conditionMatches(r.payload, c) {
+				matched = append(matched, i)
+			}
+		}
+		if r.doc.Metadata == nil {
+			r.doc.Metadata = make(map[string]any)
+		}
+		r.doc.Metadata[MatchedConditionsKey] = matched
+	}
+}
+
+// conditionMatches reports whether payload is known to match a field or
+// is-empty condition.
+func conditionMatches(payload map[string]*qclient.Value, c *qclient.Condition) bool {
+	switch c := c.GetConditionOneOf().(type) {
+	case *qclient.Condition_Field:
+		return fieldMatches(payloadValues(payload, c.Field.GetKey()), c.Field)
+	case *qclient.Condition_IsEmpty:
+		return len(payloadValues(payload, c.IsEmpty.GetKey())) == 0
+	}
+	return false
+}
+
+// fieldMatches reports whether values are known to match a field
+// condition.
+func fieldMatches(values []*qclient.Value, fc *qclient.FieldCondition) bool {
+	if r := fc.GetRange(); r != nil {
+		return slices.ContainsFunc(values, func(v *qclient.Value) bool {
+			n, ok := numberValue(v)
+			return ok && (r.Gt == nil || n > r.GetGt()) && (r.Gte == nil || n >= r.GetGte()) &&
+				(r.Lt == nil || n < r.GetLt()) && (r.Lte == nil || n <= r.GetLte())
+		})
+	}
+	switch m := fc.GetMatch().GetMatchValue().(type) {
+	case *qclient.Match_Keyword:
+		return slices.ContainsFunc(values, func(v *qclient.Value) bool { return isString(v, m.Keyword) })
+	case *qclient.Match_Keywords:
+		return slices.ContainsFunc(values, func(v *qclient.Value) bool {
+			return slices.ContainsFunc(m.Keywords.GetStrings(), func(s string) bool { return isString(v, s) })
+		})
+	case *qclient.Match_ExceptKeywords:
+		return len(values) > 0 && !slices.ContainsFunc(values, func(v *qclient.Value) bool {
+			return slices.ContainsFunc(m.ExceptKeywords.GetStrings(), func(s string) bool { return isString(v, s) })
+		})
+	case *qclient.Match_Integer:
+		return slices.ContainsFunc(values, func(v *qclient.Value) bool { return isInteger(v, m.Integer) })
+	case *qclient.Match_Integers:
+		return slices.ContainsFunc(values, func(v *qclient.Value) bool {
+			return slices.ContainsFunc(m.Integers.GetIntegers(), func(n int64) bool { return isInteger(v, n) })
+		})
+	case *qclient.Match_ExceptIntegers:
+		return len(values) > 0 && !slices.ContainsFunc(values, func(v *qclient.Value) bool {
+			return slices.ContainsFunc(m.ExceptIntegers.GetIntegers(), func(n int64) bool { return isInteger(v, n) })
+		})
+	case *qclient.Match_Boolean:
+		return slices.ContainsFunc(values, func(v *qclient.Value) bool {
+			b, ok := v.GetKind().(*qclient.Value_BoolValue)
+			return ok && b.BoolValue == m.Boolean
+		})
+	}
+	return false
+}
+
+// payloadValues returns the values at a dotted payload path. Lists along
+// the path and at its end are flattened, as Qdrant does.
+func payloadValues(payload map[string]*qclient.Value, key string) []*qclient.Value {
+	var values []*qclient.Value
+	var walk func(v *qclient.Value, path []string)
+	walk = func(v *qclient.Value, path []string) {
+		switch k := v.GetKind().(type) {
+		case *qclient.Value_ListValue:
+			for _, item := range k.ListValue.GetValues() {
+				walk(item, path)
+			}
+		case *qclient.Value_NullValue:
+		case *qclient.Value_StructValue:
+			if len(path) == 0 {
+				values = append(values, v)
+			} else if field, ok := k.StructValue.GetFields()[path[0]]; ok {
+				walk(field, path[1:])
+			}
+		default:
+			if len(path) == 0 && v != nil {
+				values = append(values, v)
+			}
+		}
+	}
+	path := strings.Split(key, ".")
+	if v, ok := payload[path[0]]; ok {
+		walk(v, path[1:])
+	}
+	return values
+}
+
+func isString(v *qclient.Value, s string) bool {
+	k, ok := v.GetKind().(*qclient.Value_StringValue)
+	return ok && k.StringValue == s
+}
+
+func isInteger(v *qclient.Value, n int64) bool {
+	k, ok := v.GetKind().(*qclient.Value_IntegerValue)
+	return ok && k.IntegerValue == n
+}
+
+func numberValue(v *qclient.Value) (float64, bool) {
+	switch k := v.GetKind().(type) {
+	case *qclient.Value_IntegerValue:
+		return float64(k.IntegerValue), true
+	case *qclient.Value_DoubleValue:
+		return k.DoubleValue, true
+	}
+	return 0, false
+}
@@ -0,0 +1,59 @@
+package qdrant
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestAnnotateMatches(t *testing.T) {
+	payload, err := qclient.TryValueMap(map[string]any{
+		"_metadata": map[string]any{
+			"lang": "en",
+			"tags": []any{"go", "db"},
+			"year": 2021,
+			"pub":  true,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	should := []*qclient.Condition{
+		qclient.NewMatchKeyword("_metadata.lang", "en"),
+		qclient.NewMatchKeyword("_metadata.lang", "fr"),
+		qclient.NewMatchKeywords("_metadata.tags", "db", "ml"),
+		qclient.NewRange("_metadata.year", &qclient.Range{Gte: qclient.PtrOf(2020.0)}),
+		qclient.NewMatchBool("_metadata.pub", false),
+		qclient.NewIsEmpty("_metadata.author"),
+		qclient.NewMatchText("_metadata.lang", "en"),
+		qclient.NewMatchExcept("_metadata.tags", "ml"),
+	}
+	r := &result{doc: ai.DocumentFromText("text", nil), payload: payload}
+	annotateMatches([]*result{r}, should)
+	want := []int{0, 2, 3, 5, 7}
+	if got := r.doc.Metadata[MatchedConditionsKey]; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// The indexes survive a JSON round trip.
+	b, err := json.Marshal(&ai.RetrieverResponse{Documents: []*ai.Document{r.doc}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp ai.RetrieverResponse
+	if err := json.Unmarshal(b, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if got := ResultsFromResponse(&resp)[0].MatchedConditions; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v after JSON, want %v", got, want)
+	}
+
+	plain := &result{doc: ai.DocumentFromText("text", nil), payload: payload}
+	annotateMatches([]*result{plain}, nil)
+	if _, ok := plain.doc.Metadata[MatchedConditionsKey]; ok {
+		t.Error("annotated without should conditions")
+	}
+}
@@ -0,0 +1,97 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// MatrixOptions configures a distance matrix request.
+type MatrixOptions struct {
+	// Filter restricts the points that are sampled.
+	Filter *qclient.Filter
+	// Sample is the number of points to sample. Defaults to 10.
+	Sample int
+	// Limit is the number of neighbours computed per sampled point.
+	// Defaults to 3.
+	Limit int
+}
+
+// MatrixPair is the similarity score between two points.
+type MatrixPair struct {
+	A, B  string
+	Score float32
+}
+
+// MatrixOffsets is a sparse distance matrix. Entry i has the score
+// Scores[i] between IDs[Rows[i]] and IDs[Cols[i]].
+type MatrixOffsets struct {
+	IDs    []string
+	Rows   []uint64
+	Cols   []uint64
+	Scores []float32
+}
+
+// DistancePairs samples points from the collection and returns the
+// similarity between each sampled point and its nearest sampled neighbours.
+func (ds *DocStore) DistancePairs(ctx context.Context, opts *MatrixOptions) ([]MatrixPair, error) {
+	if err := ds.require(ctx, FeatureMatrix); err != nil {
+		return nil, err
+	}
+	resp, err := ds.client.SearchMatrixPairs(ctx, ds.matrixRequest(opts))
+	if err != nil {
+		return nil, fmt.Errorf("qdrant distance matrix failed: %v", err)
+	}
+	pairs := make([]MatrixPair, 0, len(resp.GetPairs()))
+	for _, p := range resp.GetPairs() {
+		pairs = append(pairs, MatrixPair{
+			A:     pointIDString(p.GetA()),
+			B:     pointIDString(p.GetB()),
+			Score: p.GetScore(),
+		})
+	}
+	return pairs, nil
+}
+
+// DistanceOffsets is like [DocStore.DistancePairs] but returns the
+// matrix in the compact offsets form.
+func (ds *DocStore) DistanceOffsets(ctx context.Context, opts *MatrixOptions) (*MatrixOffsets, error) {
+	if err := ds.require(ctx, FeatureMatrix); err != nil {
+		return nil, err
+	}
+	resp, err := ds.client.SearchMatrixOffsets(ctx, ds.matrixRequest(opts))
+	if err != nil {
+		return nil, fmt.Errorf("qdrant distance matrix failed: %v", err)
+	}
+	ids := make([]string, 0, len(resp.GetIds()))
+	for _, id := range resp.GetIds() {
+		ids = append(ids, pointIDString(id))
+	}
+	return &MatrixOffsets{
+		IDs:    ids,
+		Rows:   resp.GetOffsetsRow(),
+		Cols:   resp.GetOffsetsCol(),
+		Scores: resp.GetScores(),
+	}, nil
+}
+
+func (ds *DocStore) matrixRequest(opts *MatrixOptions) *qclient.SearchMatrixPoints {
+	req := &qclient.SearchMatrixPoints{
+		CollectionName: ds.collectionName,
+	}
+	if name := ds.vectorName(); name != "" {
+		req.Using = &name
+	}
+	req.Filter = ds.scopeFilter(ds.envFilter(nil, &RetrieverOptions{}))
+	if opts != nil {
+		req.Filter = ds.scopeFilter(ds.envFilter(opts.Filter, &RetrieverOptions{}))
+		if opts.Sample > 0 {
+			req.Sample = qclient.PtrOf(uint64(opts.Sample))
+		}
+		if opts.Limit > 0 {
+			req.Limit = qclient.PtrOf(uint64(opts.Limit))
+		}
+	}
+	return req
+}
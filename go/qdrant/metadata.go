@@ -0,0 +1,65 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// UpdateMetadata sets metadata keys of the document stored in the point
+// with the given ID, as found under [PointIDKey] in retrieved documents,
+// without re-embedding it. Keys not in metadata are kept. The point keeps
+// its ID, which was derived from the document as first indexed.
+func (ds *DocStore) UpdateMetadata(ctx context.Context, pointID string, metadata map[string]any) error {
+	values, err := ds.metadataValues(pointID, metadata)
+	if err != nil {
+		return err
+	}
+	return ds.setPayload(ctx, pointID, values, &ds.metadataPayloadKey)
+}
+
+// ReplaceMetadata is like [DocStore.UpdateMetadata] but replaces the whole
+// metadata of the document, dropping keys not in metadata.
+func (ds *DocStore) ReplaceMetadata(ctx context.Context, pointID string, metadata map[string]any) error {
+	values, err := ds.metadataValues(pointID, metadata)
+	if err != nil {
+		return err
+	}
+	payload := map[string]*qclient.Value{
+		ds.metadataPayloadKey: qclient.NewValueStruct(&qclient.Struct{Fields: values}),
+	}
+	return ds.setPayload(ctx, pointID, payload, nil)
+}
+
+func (ds *DocStore) metadataValues(pointID string, metadata map[string]any) (map[string]*qclient.Value, error) {
+	if pointID == "" {
+		return nil, errors.New("qdrant: empty point ID")
+	}
+	values, err := payloadConverter{ds.payloadPolicy}.valueMap(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("qdrant: invalid metadata: %v", err)
+	}
+	return values, nil
+}
+
+// setPayload sets payload values of a point, under key if it is not nil.
+func (ds *DocStore) setPayload(ctx context.Context, pointID string, payload map[string]*qclient.Value, key *string) error {
+	if err := ds.writable("metadata update"); err != nil {
+		return err
+	}
+	_, err := ds.client.SetPayload(ctx, &qclient.SetPayloadPoints{
+		CollectionName: ds.collectionName,
+		Wait:           qclient.PtrOf(true),
+		Payload:        payload,
+		PointsSelector: ds.pointsSelector(pointID),
+		Key:            key,
+	})
+	if err != nil {
+		return fmt.Errorf("qdrant metadata update failed: %v", err)
+	}
+	// Filters may match differently now.
+	ds.negative.clear()
+	return nil
+}
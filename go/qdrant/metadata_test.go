@@ -0,0 +1,24 @@
+package qdrant
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestMetadataValues(t *testing.T) {
+	ds := &DocStore{}
+	values, err := ds.metadataValues("id", map[string]any{"tags": []string{"a", "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(values["tags"].GetListValue().GetValues()); got != 2 {
+		t.Errorf("got %d tags, want 2", got)
+	}
+	if _, err := ds.metadataValues("", nil); err == nil {
+		t.Error("expected an error for an empty point ID")
+	}
+	if err := ds.UpdateMetadata(context.Background(), "id", map[string]any{"n": math.NaN()}); err == nil {
+		t.Error("expected an error for metadata rejected by the payload policy")
+	}
+}
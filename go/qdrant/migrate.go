@@ -0,0 +1,66 @@
+package qdrant
+
+import (
+	"errors"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// DualWriteConfig configures the transition period of an embedder
+// migration: the indexer writes each document both with the current
+// embedder and with the new one, either into another named vector of the
+// collection or into another collection. Retrieval keeps using the
+// current embedder and vector. To switch it, make the new embedder and
+// vector current and the old ones the dual-write target: the old vector
+// stays up to date, so the switch can be rolled back the same way.
+type DualWriteConfig struct {
+	// Embedder is the embedder being migrated to.
+	Embedder        ai.Embedder
+	EmbedderOptions any
+	// VectorName is the named vector receiving the new embeddings. In
+	// the same collection, Config.VectorName must then name the current
+	// vector, since Qdrant cannot mix the unnamed vector with named ones.
+	// Empty with Collection uses the unnamed vector of that collection.
+	VectorName string
+	// Collection, if set, receives points with the new embeddings and the
+	// same IDs and payloads, instead of the indexed collection. Points are
+	// written to it without shard keys.
+	Collection string
+}
+
+// validate checks the configuration against the physical name of the
+// current vector, which the new vector must not overwrite.
+func (dw *DualWriteConfig) validate(current string) error {
+	switch {
+	case dw == nil:
+		return nil
+	case dw.Embedder == nil:
+		return errors.New("qdrant: DualWrite.Embedder is required")
+	case dw.Collection != "":
+		return nil
+	case dw.VectorName == "" || current == "":
+		return errors.New("qdrant: dual writes within a collection need Config.VectorName and DualWrite.VectorName")
+	case dw.VectorName == current:
+		return errors.New("qdrant: DualWrite.VectorName must differ from the current vector")
+	}
+	return nil
+}
+
+// add adds the new vector of a document to its point, or returns the
+// point written to the other collection. The current vector may have been
+// realiased since Init, so it is checked again.
+func (dw *DualWriteConfig) add(point *qclient.PointStruct, current string, vector []float32) (*qclient.PointStruct, error) {
+	if dw.Collection != "" {
+		return &qclient.PointStruct{
+			Id:      point.Id,
+			Vectors: pointVectors(dw.VectorName, vector),
+			Payload: point.Payload,
+		}, nil
+	}
+	if err := dw.validate(current); err != nil {
+		return nil, err
+	}
+	setVector(point, dw.VectorName, qclient.NewVector(vector...))
+	return nil, nil
+}
@@ -0,0 +1,50 @@
+package qdrant
+
+import (
+	"testing"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestDualWriteValidate(t *testing.T) {
+	embedder := failingEmbedder{}
+	for _, tc := range []struct {
+		dw      *DualWriteConfig
+		current string
+		ok      bool
+	}{
+		{nil, "", true},
+		{&DualWriteConfig{VectorName: "v2"}, "v1", false},
+		{&DualWriteConfig{Embedder: embedder, VectorName: "v2"}, "v1", true},
+		{&DualWriteConfig{Embedder: embedder, VectorName: "v2"}, "", false},
+		{&DualWriteConfig{Embedder: embedder, VectorName: "v1"}, "v1", false},
+		{&DualWriteConfig{Embedder: embedder, Collection: "docs-v2"}, "", true},
+	} {
+		if err := tc.dw.validate(tc.current); (err == nil) != tc.ok {
+			t.Errorf("validate(%+v, %q) = %v, want ok %v", tc.dw, tc.current, err, tc.ok)
+		}
+	}
+}
+
+func TestDualWriteAdd(t *testing.T) {
+	point := &qclient.PointStruct{Id: qclient.NewIDNum(1), Vectors: pointVectors("v1", []float32{1})}
+	dw := &DualWriteConfig{Embedder: failingEmbedder{}, VectorName: "v2"}
+	if mirror, err := dw.add(point, "v1", []float32{2, 3}); err != nil || mirror != nil {
+		t.Fatalf("add = %v, %v", mirror, err)
+	}
+	if got := len(outputVector(point.Vectors, "v2").GetData()); got != 2 {
+		t.Errorf("new vector has %d values, want 2", got)
+	}
+	if outputVector(point.Vectors, "v1") == nil {
+		t.Error("current vector was dropped")
+	}
+
+	dw.Collection = "docs-v2"
+	mirror, err := dw.add(point, "v1", []float32{4})
+	if err != nil || mirror == nil {
+		t.Fatalf("add = %v, %v", mirror, err)
+	}
+	if mirror.Id != point.Id || outputVector(mirror.Vectors, "v2") == nil {
+		t.Errorf("unexpected mirror point %v", mirror)
+	}
+}
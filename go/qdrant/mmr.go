@@ -0,0 +1,103 @@
+package qdrant
+
+import (
+	"errors"
+	"math"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// defaultQueryLimit is the number of results Qdrant returns when
+// RetrieverOptions.K is zero.
+const defaultQueryLimit = 10
+
+// MMR configures maximal marginal relevance diversification: results are
+// picked one at a time, trading the relevance of each candidate against
+// its similarity to the results already picked, so that near-duplicates
+// of the top hit give way to varied results.
+//
+// The Qdrant client in use has no diversified search, so the candidates
+// are fetched with their dense vectors and reordered by the retriever.
+type MMR struct {
+	// Lambda weighs relevance against diversity, from 0 (only
+	// diversity) to 1 (only relevance, as without MMR).
+	Lambda float32 `json:"lambda"`
+	// Candidates is the number of results diversified. Defaults to four
+	// times K, and at least 20.
+	Candidates int `json:"candidates,omitempty"`
+}
+
+func (m *MMR) validate() error {
+	if math.IsNaN(float64(m.Lambda)) || m.Lambda < 0 || m.Lambda > 1 {
+		return errors.New("mmr.lambda must be between 0 and 1")
+	}
+	if m.Candidates < 0 {
+		return errors.New("mmr.candidates must not be negative")
+	}
+	return nil
+}
+
+// candidates returns the size of the candidate pool for k results.
+func (m *MMR) candidates(k int) int {
+	if m.Candidates > 0 {
+		return max(m.Candidates, k)
+	}
+	return max(4*k, 20)
+}
+
+// diversify returns up to k of points in maximal marginal relevance order,
+// comparing the vectors stored under name with cosine similarity. Points
+// without that vector are only ranked by relevance.
+func diversify(points []*qclient.ScoredPoint, name string, lambda float32, k int) []*qclient.ScoredPoint {
+	if k > len(points) {
+		k = len(points)
+	}
+	vectors := make([][]float32, len(points))
+	for i, p := range points {
+		vectors[i] = outputVector(p.GetVectors(), name).GetData()
+	}
+	// redundancy[i] is the highest similarity of candidate i to a
+	// picked point.
+	redundancy := make([]float32, len(points))
+	picked := make([]bool, len(points))
+	out := make([]*qclient.ScoredPoint, 0, k)
+	for len(out) < k {
+		best := -1
+		var bestScore float32
+		for i, p := range points {
+			if picked[i] {
+				continue
+			}
+			score := lambda*p.GetScore() - (1-lambda)*redundancy[i]
+			if best < 0 || score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		picked[best] = true
+		out = append(out, points[best])
+		for i := range points {
+			if !picked[i] {
+				redundancy[i] = max(redundancy[i], cosine(vectors[i], vectors[best]))
+			}
+		}
+	}
+	return out
+}
+
+// cosine returns the cosine similarity of two vectors, or zero if either
+// is missing or their sizes differ.
+func cosine(a, b []float32) float32 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return float32(dot / math.Sqrt(na*nb))
+}
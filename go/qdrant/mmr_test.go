@@ -0,0 +1,44 @@
+package qdrant
+
+import (
+	"testing"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestDiversify(t *testing.T) {
+	point := func(id uint64, score float32, v ...float32) *qclient.ScoredPoint {
+		return &qclient.ScoredPoint{Id: qclient.NewIDNum(id), Score: score, Vectors: qclient.NewVectors(v...)}
+	}
+	points := []*qclient.ScoredPoint{
+		point(1, 0.9, 1, 0),
+		point(2, 0.89, 1, 0.01), // near-duplicate of 1
+		point(3, 0.7, 0, 1),
+	}
+
+	got := diversify(points, "", 0.5, 2)
+	if len(got) != 2 || got[0].Id.GetNum() != 1 || got[1].Id.GetNum() != 3 {
+		t.Errorf("got %v, want points 1 and 3", got)
+	}
+	got = diversify(points, "", 1, 5)
+	if len(got) != 3 || got[1].Id.GetNum() != 2 {
+		t.Errorf("got %v, want relevance order with lambda 1", got)
+	}
+}
+
+func TestMMRCandidates(t *testing.T) {
+	for _, tc := range []struct {
+		mmr  MMR
+		k    int
+		want int
+	}{
+		{MMR{}, 3, 20},
+		{MMR{}, 10, 40},
+		{MMR{Candidates: 50}, 10, 50},
+		{MMR{Candidates: 5}, 10, 10},
+	} {
+		if got := tc.mmr.candidates(tc.k); got != tc.want {
+			t.Errorf("%+v.candidates(%d) = %d, want %d", tc.mmr, tc.k, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/protobuf/proto"
+)
+
+// MultiVectorEmbedder computes late interaction embeddings, such as those
+// of ColBERT: one vector per token of the text. Documents and queries are
+// encoded separately since such models usually augment them differently.
+type MultiVectorEmbedder interface {
+	// EmbedDocuments returns the token vectors of each text.
+	EmbedDocuments(ctx context.Context, texts []string) ([][][]float32, error)
+	// EmbedQuery returns the token vectors of a query.
+	EmbedQuery(ctx context.Context, text string) ([][]float32, error)
+}
+
+// MultiVectorConfig enables late interaction retrieval: documents are
+// indexed with a multivector compared with MaxSim, i.e. the sum over query
+// tokens of their best match among the document tokens.
+type MultiVectorConfig struct {
+	// Name is the name of the multivector. Defaults to "colbert".
+	Name     string
+	Embedder MultiVectorEmbedder
+	// Rerank keeps the dense or hybrid search to find candidates and only
+	// uses the multivector to order them, which is much cheaper than
+	// searching the multivector directly. Without it, the multivector is
+	// the only vector queried.
+	Rerank bool
+	// RerankLimit is the number of candidates reranked. Defaults to four
+	// times K, and at least 20.
+	RerankLimit int
+}
+
+func newMultiVector(cfg MultiVectorConfig, main string) (*MultiVectorConfig, error) {
+	if cfg.Embedder == nil {
+		return nil, errors.New("qdrant: Config.MultiVector requires an Embedder")
+	}
+	if main == "" {
+		return nil, errors.New("qdrant: Config.MultiVector requires Config.VectorName, since Qdrant cannot mix the unnamed vector with named ones")
+	}
+	if cfg.Name == "" {
+		cfg.Name = "colbert"
+	}
+	if cfg.Name == main {
+		return nil, fmt.Errorf("qdrant: multivector name %q is the name of the dense vector", cfg.Name)
+	}
+	return &cfg, nil
+}
+
+// encode returns the token vectors of document texts.
+func (m *MultiVectorConfig) encode(ctx context.Context, texts []string) ([][][]float32, error) {
+	if m == nil {
+		return nil, nil
+	}
+	vectors, err := m.Embedder.EmbedDocuments(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("qdrant multivector embedding failed: %v", err)
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("qdrant multivector embedding failed: embedder returned %d multivectors for %d texts", len(vectors), len(texts))
+	}
+	return vectors, nil
+}
+
+// add checks the token vectors of document i and adds them to its point.
+func (m *MultiVectorConfig) add(point *qclient.PointStruct, tokens [][]float32, i int, policy VectorPolicy) error {
+	if len(tokens) == 0 {
+		return fmt.Errorf("document %d has no token vectors", i)
+	}
+	checked := make([][]float32, len(tokens))
+	for j, t := range tokens {
+		v, err := checkVector(t, i, policy)
+		if err != nil {
+			return err
+		}
+		checked[j] = v
+	}
+	setVector(point, m.Name, qclient.NewVectorMulti(checked))
+	return nil
+}
+
+// vectorParams returns the configuration of the multivector of an
+// auto-created collection, probing the size of the token vectors.
+func (m *MultiVectorConfig) vectorParams(ctx context.Context, distance qclient.Distance) (*qclient.VectorParams, error) {
+	vectors, err := m.Embedder.EmbedDocuments(ctx, []string{probeText})
+	if err != nil {
+		return nil, fmt.Errorf("qdrant probe multivector embedding failed: %v", err)
+	}
+	if len(vectors) != 1 || len(vectors[0]) == 0 || len(vectors[0][0]) == 0 {
+		return nil, errors.New("qdrant probe multivector embedding failed: embedder returned no vector")
+	}
+	return &qclient.VectorParams{
+		Size:     uint64(len(vectors[0][0])),
+		Distance: distance,
+		MultivectorConfig: &qclient.MultiVectorConfig{
+			Comparator: qclient.MultiVectorComparator_MaxSim,
+		},
+	}, nil
+}
+
+// embedQuery returns the token vectors of the query document, or nil
+// without multivector retrieval.
+func (m *MultiVectorConfig) embedQuery(ctx context.Context, doc *ai.Document) ([][]float32, error) {
+	if m == nil {
+		return nil, nil
+	}
+	tokens, err := m.Embedder.EmbedQuery(ctx, documentText(doc))
+	if err != nil {
+		return nil, fmt.Errorf("qdrant multivector embedding failed: %v", err)
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("qdrant multivector embedding failed: embedder returned no vector")
+	}
+	return tokens, nil
+}
+
+// primary reports whether the multivector replaces the dense and sparse
+// searches.
+func (m *MultiVectorConfig) primary() bool {
+	return m != nil && !m.Rerank
+}
+
+// multiQuery returns a copy of query that searches the multivector, or
+// that reranks the results of query with it in rerank mode. It returns
+// query itself if tokens is nil.
+func (m *MultiVectorConfig) multiQuery(query *qclient.QueryPoints, tokens [][]float32) *qclient.QueryPoints {
+	if m == nil || tokens == nil {
+		return query
+	}
+	q := proto.Clone(query).(*qclient.QueryPoints)
+	if m.Rerank && q.Query != nil {
+		limit := uint64(m.RerankLimit)
+		if limit == 0 {
+			limit = max(4*(query.GetOffset()+query.GetLimit()), 20)
+		}
+		q.Prefetch = []*qclient.PrefetchQuery{{
+			Prefetch:       q.Prefetch,
+			Query:          q.Query,
+			Using:          q.Using,
+			Filter:         q.Filter,
+			Params:         q.Params,
+			ScoreThreshold: q.ScoreThreshold,
+			Limit:          &limit,
+		}}
+		q.Params, q.ScoreThreshold = nil, nil
+	}
+	q.Query = qclient.NewQueryMulti(tokens)
+	q.Using = &m.Name
+	return q
+}
+
+// queryVectors holds the query vectors besides the dense one.
+type queryVectors struct {
+	fields []fieldQuery
+	sparse *SparseVector
+	tokens [][]float32
+}
+
+// searchQuery returns the final form of a dense query, adding the
+// per-vector, sparse and multivector searches.
+func (ds *DocStore) searchQuery(query *qclient.QueryPoints, qv queryVectors) *qclient.QueryPoints {
+	query = fusedFieldQuery(query, qv.fields)
+	return ds.multiVector.multiQuery(ds.hybrid.hybridQuery(query, qv.sparse), qv.tokens)
+}
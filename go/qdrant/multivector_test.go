@@ -0,0 +1,106 @@
+package qdrant
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// tokenEmbedder embeds each word of a text as a 1-dim vector of its length.
+type tokenEmbedder struct{}
+
+func (tokenEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][][]float32, error) {
+	vectors := make([][][]float32, len(texts))
+	for i, t := range texts {
+		vectors[i], _ = tokenEmbedder{}.EmbedQuery(ctx, t)
+	}
+	return vectors, nil
+}
+
+func (tokenEmbedder) EmbedQuery(ctx context.Context, text string) ([][]float32, error) {
+	var tokens [][]float32
+	for _, w := range strings.Fields(text) {
+		tokens = append(tokens, []float32{float32(len(w))})
+	}
+	return tokens, nil
+}
+
+func TestNewMultiVector(t *testing.T) {
+	m, err := newMultiVector(MultiVectorConfig{Embedder: tokenEmbedder{}}, "dense")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "colbert" {
+		t.Errorf("got name %q, want colbert", m.Name)
+	}
+	for _, main := range []string{"", "colbert"} {
+		if _, err := newMultiVector(MultiVectorConfig{Embedder: tokenEmbedder{}}, main); err == nil {
+			t.Errorf("expected an error with Config.VectorName %q", main)
+		}
+	}
+	if _, err := newMultiVector(MultiVectorConfig{}, "dense"); err == nil {
+		t.Error("expected an error without an embedder")
+	}
+}
+
+func TestMultiQuery(t *testing.T) {
+	tokens := [][]float32{{1}, {2}}
+	query := &qclient.QueryPoints{
+		CollectionName: "docs",
+		Query:          qclient.NewQuery(1, 2),
+		Using:          qclient.PtrOf("dense"),
+		Limit:          qclient.PtrOf(uint64(3)),
+		ScoreThreshold: qclient.PtrOf(float32(0.5)),
+	}
+
+	var none *MultiVectorConfig
+	if got := none.multiQuery(query, tokens); got != query {
+		t.Error("query changed without multivector retrieval")
+	}
+
+	primary := &MultiVectorConfig{Name: "colbert"}
+	q := primary.multiQuery(&qclient.QueryPoints{CollectionName: "docs"}, tokens)
+	if q.GetUsing() != "colbert" || len(q.GetQuery().GetNearest().GetMultiDense().GetVectors()) != 2 || q.Prefetch != nil {
+		t.Errorf("got primary query %v", q)
+	}
+
+	rerank := &MultiVectorConfig{Name: "colbert", Rerank: true}
+	q = rerank.multiQuery(query, tokens)
+	if q.GetUsing() != "colbert" || len(q.Prefetch) != 1 || q.ScoreThreshold != nil {
+		t.Fatalf("got rerank query %v", q)
+	}
+	if p := q.Prefetch[0]; p.GetUsing() != "dense" || p.GetLimit() != 20 || p.GetScoreThreshold() != 0.5 {
+		t.Errorf("got prefetch %v", p)
+	}
+	if query.Prefetch != nil || query.GetUsing() != "dense" {
+		t.Error("original query was modified")
+	}
+}
+
+func TestMultiVectorAdd(t *testing.T) {
+	m := &MultiVectorConfig{Name: "colbert"}
+	point := &qclient.PointStruct{Vectors: pointVectors("dense", []float32{1})}
+	if err := m.add(point, [][]float32{{1}, {2}}, 0, VectorError); err != nil {
+		t.Fatal(err)
+	}
+	named := point.GetVectors().GetVectors().GetVectors()
+	if named["dense"] == nil || named["colbert"] == nil {
+		t.Errorf("got vectors %v", named)
+	}
+	if err := m.add(point, nil, 0, VectorError); err == nil {
+		t.Error("expected an error without token vectors")
+	}
+}
+
+func TestMultiVectorParams(t *testing.T) {
+	m := &MultiVectorConfig{Name: "colbert", Embedder: tokenEmbedder{}}
+	params, err := m.vectorParams(context.Background(), qclient.Distance_Cosine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.GetSize() != 1 || params.GetMultivectorConfig().GetComparator() != qclient.MultiVectorComparator_MaxSim {
+		t.Errorf("got %v", params)
+	}
+}
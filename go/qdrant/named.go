@@ -0,0 +1,189 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/protobuf/proto"
+)
+
+// namedEmbedder is an additional named vector of the collection and the
+// embedder filling it.
+type namedEmbedder struct {
+	name     string // logical name
+	embedder ai.Embedder
+}
+
+// newNamedEmbedders returns the embedders of Config.VectorEmbedders in
+// name order.
+func newNamedEmbedders(embedders map[string]ai.Embedder, main string) ([]namedEmbedder, error) {
+	if len(embedders) == 0 {
+		return nil, nil
+	}
+	if main == "" {
+		return nil, errors.New("qdrant: Config.VectorEmbedders requires Config.VectorName, since Qdrant cannot mix the unnamed vector with named ones")
+	}
+	named := make([]namedEmbedder, 0, len(embedders))
+	for name, e := range embedders {
+		if name == "" || name == main {
+			return nil, fmt.Errorf("qdrant: invalid name %q in Config.VectorEmbedders", name)
+		}
+		if e == nil {
+			return nil, fmt.Errorf("qdrant: no embedder for vector %q", name)
+		}
+		named = append(named, namedEmbedder{name, e})
+	}
+	sort.Slice(named, func(i, j int) bool { return named[i].name < named[j].name })
+	return named, nil
+}
+
+// queryEmbedder returns the embedder and options for the logical vector
+// selected by RetrieverOptions.Vector.
+func (ds *DocStore) queryEmbedder(logical string) (ai.Embedder, any, error) {
+	if logical == "" || logical == ds.logicalVector {
+		return ds.embedder, ds.embedderOptions, nil
+	}
+	for _, ne := range ds.namedEmbedders {
+		if ne.name == logical {
+			return ne.embedder, nil, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("qdrant: unknown vector %q", logical)
+}
+
+// queryVector returns the physical vector queried for RetrieverOptions.Vector.
+func (ds *DocStore) queryVector(logical string) string {
+	if logical == "" {
+		return ds.vectorName()
+	}
+	return ds.physicalVector(logical)
+}
+
+// embedNamed embeds documents with each additional embedder. The result
+// holds one vector per document for each embedder, nil for documents that
+// were quarantined.
+func (ds *DocStore) embedNamed(ctx context.Context, docs []*ai.Document, iopt *IndexerOptions) ([][][]float32, error) {
+	all := make([][][]float32, len(ds.namedEmbedders))
+	for i, ne := range ds.namedEmbedders {
+		vectors, err := ds.embedWith(ctx, ne.embedder, nil, docs, iopt)
+		if err != nil {
+			return nil, fmt.Errorf("vector %q: %v", ne.name, err)
+		}
+		all[i] = vectors
+	}
+	return all, nil
+}
+
+// namedVectorsConfig returns the configuration of the additional vectors
+// of an auto-created collection, probing the size of each embedder.
+func (ds *DocStore) namedVectorsConfig(ctx context.Context, params map[string]*qclient.VectorParams) error {
+	for _, ne := range ds.namedEmbedders {
+		n, err := probeSize(ctx, ne.embedder, nil)
+		if err != nil {
+			return fmt.Errorf("vector %q: %v", ne.name, err)
+		}
+		params[ds.physicalVector(ne.name)] = &qclient.VectorParams{Size: uint64(n), Distance: ds.distance}
+	}
+	return nil
+}
+
+// missingVector reports whether document i has no vector for one of the
+// additional embedders.
+func missingVector(named [][][]float32, i int) bool {
+	for _, vectors := range named {
+		if vectors[i] == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// addNamed adds the additional vectors of document i to its point.
+func (ds *DocStore) addNamed(point *qclient.PointStruct, named [][][]float32, i int) error {
+	for j, ne := range ds.namedEmbedders {
+		vector, err := checkVector(named[j][i], i, ds.vectorPolicy)
+		if err != nil {
+			return err
+		}
+		setVector(point, ds.physicalVector(ne.name), qclient.NewVector(vector...))
+	}
+	return nil
+}
+
+// fieldQuery is the search of one vector of RetrieverOptions.VectorQueries.
+type fieldQuery struct {
+	using string // physical name
+	query *qclient.Query
+}
+
+// fieldQueries embeds the text of each entry of RetrieverOptions.VectorQueries
+// with the embedder of its vector, in name order.
+func (ds *DocStore) fieldQueries(ctx context.Context, ropt *RetrieverOptions) ([]fieldQuery, error) {
+	names := make([]string, 0, len(ropt.VectorQueries))
+	for name := range ropt.VectorQueries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fields := make([]fieldQuery, len(names))
+	for i, name := range names {
+		doc := ai.DocumentFromText(ropt.VectorQueries[name], nil)
+		query, err := ds.embedVectorQuery(ctx, doc, name, ropt.Tenant)
+		if err != nil {
+			return nil, fmt.Errorf("vector %q: %v", name, err)
+		}
+		fields[i] = fieldQuery{ds.queryVector(name), query}
+	}
+	return fields, nil
+}
+
+// fusedFieldQuery returns a copy of query that runs one search per field
+// query and fuses them with reciprocal rank fusion. The filter, score
+// threshold and search params apply to each search. It returns query
+// itself if there are no field queries.
+func fusedFieldQuery(query *qclient.QueryPoints, fields []fieldQuery) *qclient.QueryPoints {
+	if len(fields) == 0 {
+		return query
+	}
+	limit := max(4*(query.GetOffset()+query.GetLimit()), 20)
+	q := proto.Clone(query).(*qclient.QueryPoints)
+	q.Prefetch = make([]*qclient.PrefetchQuery, len(fields))
+	for i, f := range fields {
+		q.Prefetch[i] = &qclient.PrefetchQuery{
+			Query:          f.query,
+			Filter:         q.Filter,
+			Params:         q.Params,
+			ScoreThreshold: q.ScoreThreshold,
+			Limit:          &limit,
+		}
+		if f.using != "" {
+			q.Prefetch[i].Using = &f.using
+		}
+	}
+	q.Query = qclient.NewQueryFusion(qclient.Fusion_RRF)
+	q.Using, q.Params, q.ScoreThreshold = nil, nil, nil
+	return q
+}
+
+// keyText returns the text identifying a query for the negative cache,
+// including the texts of RetrieverOptions.VectorQueries.
+func keyText(text string, vectorQueries map[string]string) string {
+	if len(vectorQueries) == 0 {
+		return text
+	}
+	names := make([]string, 0, len(vectorQueries))
+	for name := range vectorQueries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	sb.WriteString(text)
+	for _, name := range names {
+		fmt.Fprintf(&sb, "\x00%s\x00%s", name, vectorQueries[name])
+	}
+	return sb.String()
+}
@@ -0,0 +1,95 @@
+package qdrant
+
+import (
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestNewNamedEmbedders(t *testing.T) {
+	embedders := map[string]ai.Embedder{"title": failingEmbedder{}, "summary": failingEmbedder{}}
+	named, err := newNamedEmbedders(embedders, "body")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(named) != 2 || named[0].name != "summary" || named[1].name != "title" {
+		t.Errorf("got %v, want summary and title in order", named)
+	}
+	for _, main := range []string{"", "title"} {
+		if _, err := newNamedEmbedders(embedders, main); err == nil {
+			t.Errorf("expected an error with Config.VectorName %q", main)
+		}
+	}
+}
+
+func TestQueryEmbedder(t *testing.T) {
+	ds := &DocStore{
+		logicalVector:  "body",
+		vectorAliases:  map[string]string{"title": "title-v2"},
+		namedEmbedders: []namedEmbedder{{"title", failingEmbedder{}}},
+	}
+	for _, logical := range []string{"", "body", "title"} {
+		if _, _, err := ds.queryEmbedder(logical); err != nil {
+			t.Errorf("queryEmbedder(%q): %v", logical, err)
+		}
+	}
+	if _, _, err := ds.queryEmbedder("image"); err == nil {
+		t.Error("expected an error for an unknown vector")
+	}
+	if got := ds.queryVector("title"); got != "title-v2" {
+		t.Errorf("got %q, want the aliased title-v2", got)
+	}
+	if got := ds.queryVector(""); got != "body" {
+		t.Errorf("got %q, want the main vector", got)
+	}
+
+	point := &qclient.PointStruct{Vectors: pointVectors("body", []float32{1})}
+	named := [][][]float32{{{1, 2}, nil}}
+	if missingVector(named, 0) || !missingVector(named, 1) {
+		t.Error("missingVector misreports documents")
+	}
+	if err := ds.addNamed(point, named, 0); err != nil {
+		t.Fatal(err)
+	}
+	if outputVector(point.Vectors, "title-v2") == nil || outputVector(point.Vectors, "body") == nil {
+		t.Errorf("got vectors %v", point.Vectors)
+	}
+}
+
+func TestFusedFieldQuery(t *testing.T) {
+	query := &qclient.QueryPoints{
+		CollectionName: "docs",
+		Using:          qclient.PtrOf("body"),
+		Limit:          qclient.PtrOf(uint64(3)),
+		ScoreThreshold: qclient.PtrOf(float32(0.5)),
+	}
+	if got := fusedFieldQuery(query, nil); got != query {
+		t.Error("query changed without field queries")
+	}
+
+	q := fusedFieldQuery(query, []fieldQuery{
+		{"body", qclient.NewQuery(1)},
+		{"title-v2", qclient.NewQuery(2)},
+	})
+	if len(q.Prefetch) != 2 || q.GetQuery().GetFusion() != qclient.Fusion_RRF || q.Using != nil || q.ScoreThreshold != nil {
+		t.Fatalf("got %v", q)
+	}
+	if p := q.Prefetch[1]; p.GetUsing() != "title-v2" || p.GetLimit() != 20 || p.GetScoreThreshold() != 0.5 {
+		t.Errorf("got prefetch %v", p)
+	}
+	if query.Prefetch != nil || query.GetUsing() != "body" {
+		t.Error("original query was modified")
+	}
+}
+
+func TestKeyText(t *testing.T) {
+	if got := keyText("q", nil); got != "q" {
+		t.Errorf("got %q, want the query text", got)
+	}
+	a := keyText("q", map[string]string{"title": "a", "body": "b"})
+	b := keyText("q", map[string]string{"title": "b", "body": "a"})
+	if a == b || a == "q" {
+		t.Errorf("vector queries are not part of the key: %q, %q", a, b)
+	}
+}
@@ -0,0 +1,76 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/google/uuid"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// namespacePayloadKey is the payload field holding Config.Namespace.
+const namespacePayloadKey = "_namespace"
+
+// scopeFilter restricts filter to the points of the namespace of the
+// store. It returns filter itself without a namespace.
+func (ds *DocStore) scopeFilter(filter *qclient.Filter) *qclient.Filter {
+	if ds.namespace == "" {
+		return filter
+	}
+	must := []*qclient.Condition{qclient.NewMatchKeyword(namespacePayloadKey, ds.namespace)}
+	if filter != nil {
+		must = append(must, qclient.NewFilterAsCondition(filter))
+	}
+	return &qclient.Filter{Must: must}
+}
+
+// pointsSelector selects a point by ID within the namespace of the store.
+func (ds *DocStore) pointsSelector(pointID string) *qclient.PointsSelector {
+	if ds.namespace == "" {
+		return qclient.NewPointsSelector(pointIDOf(pointID))
+	}
+	return qclient.NewPointsSelectorFilter(ds.scopeFilter(&qclient.Filter{
+		Must: []*qclient.Condition{qclient.NewHasID(pointIDOf(pointID))},
+	}))
+}
+
+// pointID returns the ID of the point of a document. The namespace,
+// environment and payload tenant are part of it, so that the namespaces,
+// environments and tenants sharing a collection can hold the same
+// document.
+func (ds *DocStore) pointID(doc *ai.Document, tenant string) (string, error) {
+	id, err := documentID(doc, ds.idMetadataKeys)
+	if err != nil {
+		return "", err
+	}
+	scope := ds.namespace
+	if ds.environment != "" {
+		scope += "\x00env=" + ds.environment
+	}
+	if ds.tenantKey != "" {
+		scope += "\x00tenant=" + tenant
+	}
+	if scope == "" {
+		return id, nil
+	}
+	return uuid.NewSHA1(uuid.NameSpaceDNS, []byte(scope+"\x00"+id)).String(), nil
+}
+
+// createNamespaceIndex creates the tenant index on the namespace field,
+// which lets Qdrant co-locate the points of each namespace.
+func (ds *DocStore) createNamespaceIndex(ctx context.Context) error {
+	_, err := ds.client.CreateFieldIndex(ctx, &qclient.CreateFieldIndexCollection{
+		CollectionName: ds.collectionName,
+		FieldName:      namespacePayloadKey,
+		FieldType:      qclient.FieldType_FieldTypeKeyword.Enum(),
+		FieldIndexParams: qclient.NewPayloadIndexParamsKeyword(&qclient.KeywordIndexParams{
+			IsTenant: qclient.PtrOf(true),
+		}),
+		Wait: qclient.PtrOf(true),
+	})
+	if err != nil {
+		return fmt.Errorf("qdrant failed to create namespace index: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package qdrant
+
+import (
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestScopeFilter(t *testing.T) {
+	filter := &qclient.Filter{Must: []*qclient.Condition{qclient.NewMatchKeyword("lang", "en")}}
+	ds := &DocStore{}
+	if got := ds.scopeFilter(filter); got != filter {
+		t.Error("filter changed without a namespace")
+	}
+
+	ds.namespace = "app"
+	got := ds.scopeFilter(filter)
+	if len(got.GetMust()) != 2 || got.Must[0].GetField().GetKey() != namespacePayloadKey ||
+		got.Must[0].GetField().GetMatch().GetKeyword() != "app" || got.Must[1].GetFilter() != filter {
+		t.Errorf("got %v", got)
+	}
+	if got := ds.scopeFilter(nil); len(got.GetMust()) != 1 {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestNamespacePointID(t *testing.T) {
+	doc := ai.DocumentFromText("hello", nil)
+	plain, err := generatePointId(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := func(namespace string) string {
+		id, err := (&DocStore{namespace: namespace}).pointID(doc, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	if got := id(""); got != plain {
+		t.Errorf("got ID %s without a namespace, want %s", got, plain)
+	}
+	if a, b := id("a"), id("b"); a == plain || a == b {
+		t.Errorf("got IDs %s and %s, want distinct IDs per namespace", a, b)
+	}
+}
+
+func TestPointsSelectorNumericID(t *testing.T) {
+	ds := &DocStore{}
+	if got := ds.pointsSelector("42").GetPoints().GetIds()[0]; got.GetNum() != 42 {
+		t.Errorf("got %v, want the numeric ID 42", got)
+	}
+	const id = "5c56c793-69f3-4fbf-87e6-c4bf54c28c26"
+	if got := ds.pointsSelector(id).GetPoints().GetIds()[0]; got.GetUuid() != id {
+		t.Errorf("got %v, want the UUID %s", got, id)
+	}
+	ds.namespace = "ns"
+	cond := ds.pointsSelector("42").GetFilter().GetMust()[1].GetFilter().GetMust()[0]
+	if got := cond.GetHasId().GetHasId()[0]; got.GetNum() != 42 || pointIDString(got) != "42" {
+		t.Errorf("got %v, want the numeric ID 42", got)
+	}
+}
@@ -0,0 +1,102 @@
+package qdrant
+
+import (
+	"crypto/sha256"
+	"errors"
+	"sync"
+	"time"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrInsufficientContext is returned by the retriever, without embedding
+// the query, when an identical query recently returned no documents. See
+// Config.NegativeCache.
+var ErrInsufficientContext = errors.New("qdrant: insufficient context, the query recently matched no documents")
+
+// NegativeCacheConfig configures the cache of queries that returned no
+// documents, e.g. because no document scored above the threshold. It
+// saves embedder calls when a chatbot keeps getting questions the
+// collection cannot answer.
+type NegativeCacheConfig struct {
+	// TTL is how long a query is known to return nothing. Defaults to
+	// 10 minutes. Indexing documents clears the cache.
+	TTL time.Duration
+	// MaxEntries limits the number of cached queries. Defaults to 10000.
+	MaxEntries int
+}
+
+// negativeCache records when queries returned no documents, keyed by a
+// hash of the query text and the Qdrant request.
+type negativeCache struct {
+	cfg NegativeCacheConfig
+
+	mu      sync.Mutex
+	expires map[[sha256.Size]byte]time.Time
+}
+
+func newNegativeCache(cfg NegativeCacheConfig) *negativeCache {
+	if cfg.TTL <= 0 {
+		cfg.TTL = 10 * time.Minute
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 10000
+	}
+	return &negativeCache{cfg: cfg, expires: make(map[[sha256.Size]byte]time.Time)}
+}
+
+// negativeKey identifies a query. query must not hold the query vector
+// yet, so that the key is known before embedding.
+func negativeKey(text string, query *qclient.QueryPoints) ([sha256.Size]byte, error) {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(query)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(append([]byte(text+"\x00"), b...)), nil
+}
+
+// known reports whether the query returned nothing within the TTL.
+func (c *negativeCache) known(key [sha256.Size]byte, now time.Time) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	exp, ok := c.expires[key]
+	if ok && !now.Before(exp) {
+		delete(c.expires, key)
+		return false
+	}
+	return ok
+}
+
+// add records that the query returned nothing.
+func (c *negativeCache) add(key [sha256.Size]byte, now time.Time) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.expires) >= c.cfg.MaxEntries {
+		for k, exp := range c.expires {
+			if !now.Before(exp) {
+				delete(c.expires, k)
+			}
+		}
+		if len(c.expires) >= c.cfg.MaxEntries {
+			return
+		}
+	}
+	c.expires[key] = now.Add(c.cfg.TTL)
+}
+
+// clear forgets all queries, since new documents may answer them.
+func (c *negativeCache) clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.expires)
+}
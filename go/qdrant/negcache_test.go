@@ -0,0 +1,46 @@
+package qdrant
+
+import (
+	"testing"
+	"time"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestNegativeCache(t *testing.T) {
+	c := newNegativeCache(NegativeCacheConfig{TTL: time.Minute, MaxEntries: 1})
+	now := time.Now()
+	query := &qclient.QueryPoints{CollectionName: "docs", Limit: qclient.PtrOf(uint64(3))}
+	key, err := negativeKey("who won in 1850?", query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := negativeKey("who won in 1850?", &qclient.QueryPoints{CollectionName: "docs", Limit: qclient.PtrOf(uint64(5))})
+	if key == other {
+		t.Error("queries with different options share a key")
+	}
+
+	c.add(key, now)
+	if !c.known(key, now.Add(30*time.Second)) {
+		t.Error("query not known within the TTL")
+	}
+	c.add(other, now)
+	if c.known(other, now) {
+		t.Error("query cached beyond MaxEntries")
+	}
+	if c.known(key, now.Add(time.Minute)) {
+		t.Error("query still known after the TTL")
+	}
+
+	c.add(key, now)
+	c.clear()
+	if c.known(key, now) {
+		t.Error("query known after clear")
+	}
+
+	var disabled *negativeCache
+	disabled.add(key, now)
+	if disabled.known(key, now) {
+		t.Error("disabled cache knows a query")
+	}
+}
@@ -0,0 +1,181 @@
+package qdrant
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Genkit v0.2.1 does not let a plugin attach an options schema to its
+// indexer and retriever actions, so options are validated here, before
+// any embedding or Qdrant call is made.
+
+// parseIndexerOptions converts and validates the options of an index request.
+func parseIndexerOptions(opts any) (*IndexerOptions, error) {
+	iopt := &IndexerOptions{}
+	switch o := opts.(type) {
+	case nil:
+	case *IndexerOptions:
+		iopt = o
+	case map[string]any, json.RawMessage:
+		if err := decodeOptions(o, iopt); err != nil {
+			return nil, fmt.Errorf("qdrant.Index invalid options: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("qdrant.Index options have type %T, want %T", opts, &IndexerOptions{})
+	}
+	if err := iopt.validate(); err != nil {
+		return nil, fmt.Errorf("qdrant.Index invalid options: %v", err)
+	}
+	return iopt, nil
+}
+
+// parseRetrieverOptions converts and validates the options of a retrieve
+// request.
+func parseRetrieverOptions(opts any) (*RetrieverOptions, error) {
+	ropt := &RetrieverOptions{}
+	switch o := opts.(type) {
+	case nil:
+	case *RetrieverOptions:
+		ropt = o
+	case map[string]any, json.RawMessage:
+		// The filter is a protobuf message and needs its own decoder.
+		var wire struct {
+			*RetrieverOptions
+			Filter json.RawMessage `json:"filter,omitempty"`
+		}
+		wire.RetrieverOptions = ropt
+		if err := decodeOptions(o, &wire); err != nil {
+			return nil, fmt.Errorf("qdrant.Retrieve invalid options: %v", err)
+		}
+		if len(wire.Filter) > 0 {
+			if err := protojson.Unmarshal(wire.Filter, &ropt.Filter); err != nil {
+				return nil, fmt.Errorf("qdrant.Retrieve invalid options: filter: %v", err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("qdrant.Retrieve options have type %T, want %T", opts, &RetrieverOptions{})
+	}
+	if err := ropt.validate(); err != nil {
+		return nil, fmt.Errorf("qdrant.Retrieve invalid options: %v", err)
+	}
+	return ropt, nil
+}
+
+// validate reports option values that Qdrant would reject.
+func (iopt *IndexerOptions) validate() error {
+	if _, ok := writeOrderings[iopt.Ordering]; iopt.Ordering != "" && !ok {
+		return fmt.Errorf("ordering must be weak, medium or strong, got %q", iopt.Ordering)
+	}
+	return nil
+}
+
+// validate reports option values that Qdrant would reject or that would
+// silently produce meaningless results.
+func (ropt *RetrieverOptions) validate() error {
+	if ropt.K < 0 {
+		return fmt.Errorf("k must not be negative, got %d", ropt.K)
+	}
+	if math.IsNaN(float64(ropt.FeedbackBoost)) || math.IsInf(float64(ropt.FeedbackBoost), 0) {
+		return errors.New("feedbackBoost must be a finite number")
+	}
+	if math.IsNaN(float64(ropt.PreferBoost)) || math.IsInf(float64(ropt.PreferBoost), 0) {
+		return errors.New("preferBoost must be a finite number")
+	}
+	if math.IsNaN(float64(ropt.ScoreThreshold)) || math.IsInf(float64(ropt.ScoreThreshold), 0) {
+		return errors.New("scoreThreshold must be a finite number")
+	}
+	if math.IsNaN(float64(ropt.SessionBoost)) || math.IsInf(float64(ropt.SessionBoost), 0) {
+		return errors.New("sessionBoost must be a finite number")
+	}
+	if math.IsNaN(float64(ropt.ConfidenceThreshold)) || math.IsInf(float64(ropt.ConfidenceThreshold), 0) {
+		return errors.New("confidenceThreshold must be a finite number")
+	}
+	if ropt.Offset < 0 {
+		return fmt.Errorf("offset must not be negative, got %d", ropt.Offset)
+	}
+	if name := reorderingOption(ropt); ropt.Offset > 0 && name != "" {
+		return fmt.Errorf("offset cannot be combined with %s", name)
+	}
+	if ropt.GroupBy != nil {
+		if err := ropt.GroupBy.validate(); err != nil {
+			return err
+		}
+		if ropt.Offset > 0 {
+			return errors.New("groupBy cannot be combined with offset")
+		}
+		if name := reorderingOption(ropt); name != "" {
+			return fmt.Errorf("groupBy cannot be combined with %s", name)
+		}
+	}
+	if ropt.Recommend != nil {
+		if err := ropt.Recommend.validate(); err != nil {
+			return err
+		}
+		if len(ropt.VectorQueries) > 0 {
+			return errors.New("recommend cannot be combined with vectorQueries")
+		}
+		if ropt.Search == SearchSparse {
+			return errors.New("recommend cannot be combined with a sparse search")
+		}
+	}
+	if ropt.Discover != nil {
+		if err := ropt.Discover.validate(); err != nil {
+			return err
+		}
+		if ropt.Recommend != nil {
+			return errors.New("discover cannot be combined with recommend")
+		}
+		if len(ropt.VectorQueries) > 0 {
+			return errors.New("discover cannot be combined with vectorQueries")
+		}
+		if ropt.Search == SearchSparse {
+			return errors.New("discover cannot be combined with a sparse search")
+		}
+	}
+	if ropt.MMR != nil {
+		if err := ropt.MMR.validate(); err != nil {
+			return err
+		}
+	}
+	if ropt.SearchParams != nil {
+		if err := ropt.SearchParams.validate(); err != nil {
+			return err
+		}
+		if ropt.SearchParams.Exact && ropt.IndexedOnly {
+			return errors.New("indexedOnly cannot be combined with exact search")
+		}
+	}
+	if len(ropt.ShardKeys) > 0 && ropt.Shards != nil {
+		return errors.New("shardKeys cannot be combined with Shards")
+	}
+	if _, err := readConsistency(ropt.Consistency); err != nil {
+		return err
+	}
+	for name, text := range ropt.VectorQueries {
+		if strings.TrimSpace(text) == "" {
+			return fmt.Errorf("vectorQueries: empty query for vector %q", name)
+		}
+	}
+	return nil
+}
+
+// decodeOptions decodes JSON options into v, rejecting unknown fields so
+// that misspelt options are reported rather than ignored.
+func decodeOptions(opts, v any) error {
+	b, ok := opts.(json.RawMessage)
+	if !ok {
+		var err error
+		if b, err = json.Marshal(opts); err != nil {
+			return err
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
+}
@@ -0,0 +1,208 @@
+package qdrant
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/protobuf/proto"
+)
+
+// RetrieveOptionsBuilder builds [RetrieverOptions] fluently, checking at
+// Build the values and combinations the retriever would reject or ignore:
+//
+//	opts, err := qdrant.NewRetrieveOptions().K(5).Threshold(0.7).Where("lang", "en").Build()
+//
+// The options returned by Build are those of the builder, which should not
+// be used afterwards.
+type RetrieveOptionsBuilder struct {
+	opts *RetrieverOptions
+}
+
+// NewRetrieveOptions returns a builder of default retriever options.
+func NewRetrieveOptions() *RetrieveOptionsBuilder {
+	return &RetrieveOptionsBuilder{opts: &RetrieverOptions{}}
+}
+
+// K sets the maximum number of documents retrieved.
+func (b *RetrieveOptionsBuilder) K(k int) *RetrieveOptionsBuilder {
+	b.opts.K = k
+	return b
+}
+
+// Threshold sets RetrieverOptions.ScoreThreshold.
+func (b *RetrieveOptionsBuilder) Threshold(score float32) *RetrieveOptionsBuilder {
+	b.opts.ScoreThreshold = score
+	return b
+}
+
+// Filter sets the Qdrant filter, replacing any previous one. The filter is
+// copied.
+func (b *RetrieveOptionsBuilder) Filter(filter *qclient.Filter) *RetrieveOptionsBuilder {
+	proto.Reset(&b.opts.Filter)
+	proto.Merge(&b.opts.Filter, filter)
+	return b
+}
+
+// Where adds a metadata equality condition. See RetrieverOptions.Where.
+func (b *RetrieveOptionsBuilder) Where(key string, value any) *RetrieveOptionsBuilder {
+	if b.opts.Where == nil {
+		b.opts.Where = make(map[string]any)
+	}
+	b.opts.Where[key] = value
+	return b
+}
+
+// Prefer adds a metadata preference. See RetrieverOptions.Prefer.
+func (b *RetrieveOptionsBuilder) Prefer(key string, value any) *RetrieveOptionsBuilder {
+	if b.opts.Prefer == nil {
+		b.opts.Prefer = make(map[string]any)
+	}
+	b.opts.Prefer[key] = value
+	return b
+}
+
+// PreferBoost sets the score added per matching preference.
+func (b *RetrieveOptionsBuilder) PreferBoost(boost float32) *RetrieveOptionsBuilder {
+	b.opts.PreferBoost = boost
+	return b
+}
+
+// Tenant sets the tenant searched.
+func (b *RetrieveOptionsBuilder) Tenant(tenant string) *RetrieveOptionsBuilder {
+	b.opts.Tenant = tenant
+	return b
+}
+
+// Shards restricts the search to a subset of the shards.
+func (b *RetrieveOptionsBuilder) Shards(shards *ShardSelector) *RetrieveOptionsBuilder {
+	b.opts.Shards = shards
+	return b
+}
+
+// Recommend retrieves documents like and unlike the given examples.
+func (b *RetrieveOptionsBuilder) Recommend(r Recommend) *RetrieveOptionsBuilder {
+	b.opts.Recommend = &r
+	return b
+}
+
+// Discover searches around a target constrained by context pairs.
+func (b *RetrieveOptionsBuilder) Discover(d Discover) *RetrieveOptionsBuilder {
+	b.opts.Discover = &d
+	return b
+}
+
+// GroupBy returns the top size hits of the K best groups of results
+// sharing the value of a metadata field.
+func (b *RetrieveOptionsBuilder) GroupBy(field string, size int) *RetrieveOptionsBuilder {
+	b.opts.GroupBy = &GroupBy{Field: field, Size: size}
+	return b
+}
+
+// Offset skips the first n results.
+func (b *RetrieveOptionsBuilder) Offset(n int) *RetrieveOptionsBuilder {
+	b.opts.Offset = n
+	return b
+}
+
+// SearchParams sets the tuning of the vector search.
+func (b *RetrieveOptionsBuilder) SearchParams(p SearchParams) *RetrieveOptionsBuilder {
+	b.opts.SearchParams = &p
+	return b
+}
+
+// IndexedOnly skips segments that are not indexed yet.
+func (b *RetrieveOptionsBuilder) IndexedOnly() *RetrieveOptionsBuilder {
+	b.opts.IndexedOnly = true
+	return b
+}
+
+// Vector sets the logical name of the vector queried.
+func (b *RetrieveOptionsBuilder) Vector(name string) *RetrieveOptionsBuilder {
+	b.opts.Vector = name
+	return b
+}
+
+// VectorQuery adds the text searched in a logical vector. See
+// RetrieverOptions.VectorQueries.
+func (b *RetrieveOptionsBuilder) VectorQuery(name, text string) *RetrieveOptionsBuilder {
+	if b.opts.VectorQueries == nil {
+		b.opts.VectorQueries = make(map[string]string)
+	}
+	b.opts.VectorQueries[name] = text
+	return b
+}
+
+// Search selects the vectors searched when Config.Hybrid is set.
+func (b *RetrieveOptionsBuilder) Search(mode SearchMode) *RetrieveOptionsBuilder {
+	b.opts.Search = mode
+	return b
+}
+
+// MMR diversifies the results with maximal marginal relevance.
+func (b *RetrieveOptionsBuilder) MMR(lambda float32) *RetrieveOptionsBuilder {
+	b.opts.MMR = &MMR{Lambda: lambda}
+	return b
+}
+
+// History sets the conversation the query is condensed with.
+func (b *RetrieveOptionsBuilder) History(history ...*ai.Message) *RetrieveOptionsBuilder {
+	b.opts.History = history
+	return b
+}
+
+// Consistency sets the read consistency of the queries.
+func (b *RetrieveOptionsBuilder) Consistency(c string) *RetrieveOptionsBuilder {
+	b.opts.Consistency = c
+	return b
+}
+
+// CountAvailable counts the points matching the filter.
+func (b *RetrieveOptionsBuilder) CountAvailable() *RetrieveOptionsBuilder {
+	b.opts.CountAvailable = true
+	return b
+}
+
+// SessionBoost sets the score added to documents already retrieved in the
+// session.
+func (b *RetrieveOptionsBuilder) SessionBoost(boost float32) *RetrieveOptionsBuilder {
+	b.opts.SessionBoost = boost
+	return b
+}
+
+// SessionExclude skips documents already retrieved in the session.
+func (b *RetrieveOptionsBuilder) SessionExclude() *RetrieveOptionsBuilder {
+	b.opts.SessionExclude = true
+	return b
+}
+
+// FeedbackBoost sets the score added per net positive feedback.
+func (b *RetrieveOptionsBuilder) FeedbackBoost(boost float32) *RetrieveOptionsBuilder {
+	b.opts.FeedbackBoost = boost
+	return b
+}
+
+// Build returns the options, or an error for invalid values or
+// combinations.
+func (b *RetrieveOptionsBuilder) Build() (*RetrieverOptions, error) {
+	if err := b.opts.validate(); err != nil {
+		return nil, fmt.Errorf("qdrant: invalid retriever options: %v", err)
+	}
+	if err := b.opts.validateCombinations(); err != nil {
+		return nil, fmt.Errorf("qdrant: invalid retriever options: %v", err)
+	}
+	return b.opts, nil
+}
+
+// validateCombinations reports options that are ignored or contradict each
+// other.
+func (ropt *RetrieverOptions) validateCombinations() error {
+	if ropt.PreferBoost != 0 && len(ropt.Prefer) == 0 {
+		return errors.New("preferBoost requires prefer")
+	}
+	if ropt.SessionBoost != 0 && ropt.SessionExclude {
+		return errors.New("sessionBoost has no effect with sessionExclude")
+	}
+	return nil
+}
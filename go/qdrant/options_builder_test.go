@@ -0,0 +1,37 @@
+package qdrant
+
+import (
+	"testing"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestRetrieveOptionsBuilder(t *testing.T) {
+	filter := &qclient.Filter{Must: []*qclient.Condition{qclient.NewMatchKeyword("_metadata.lang", "en")}}
+	ropt, err := NewRetrieveOptions().K(5).Threshold(0.7).Filter(filter).Where("year", 2024).VectorQuery("title", "go").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ropt.K != 5 || ropt.ScoreThreshold != 0.7 || ropt.Where["year"] != 2024 || ropt.VectorQueries["title"] != "go" {
+		t.Errorf("got %+v", ropt)
+	}
+	if got := ropt.Filter.GetMust()[0].GetField().GetMatch().GetKeyword(); got != "en" {
+		t.Errorf("got filter keyword %q, want en", got)
+	}
+	filter.Must = nil
+	if len(ropt.Filter.GetMust()) != 1 {
+		t.Error("the filter was not copied")
+	}
+
+	for name, b := range map[string]*RetrieveOptionsBuilder{
+		"negative k":             NewRetrieveOptions().K(-1),
+		"mmr lambda":             NewRetrieveOptions().MMR(2),
+		"consistency":            NewRetrieveOptions().Consistency("strong"),
+		"boost without prefer":   NewRetrieveOptions().PreferBoost(0.2),
+		"boost of excluded docs": NewRetrieveOptions().SessionBoost(0.1).SessionExclude(),
+	} {
+		if _, err := b.Build(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package qdrant
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestParseRetrieverOptions(t *testing.T) {
+	opts := map[string]any{
+		"k":              5,
+		"indexedOnly":    true,
+		"scoreThreshold": 0.75,
+		"filter": map[string]any{
+			"must": []any{map[string]any{"field": map[string]any{"key": "_metadata.lang", "match": map[string]any{"keyword": "en"}}}},
+		},
+	}
+	ropt, err := parseRetrieverOptions(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ropt.K != 5 || !ropt.IndexedOnly || ropt.ScoreThreshold != 0.75 {
+		t.Errorf("got %+v", ropt)
+	}
+	if got := ropt.Filter.GetMust()[0].GetField().GetMatch().GetKeyword(); got != "en" {
+		t.Errorf("got filter keyword %q, want %q", got, "en")
+	}
+
+	for _, bad := range []any{
+		json.RawMessage(`{"limit": 5}`),
+		map[string]any{"k": -1},
+		map[string]any{"filter": map[string]any{"must": "x"}},
+		&RetrieverOptions{K: -2},
+		&RetrieverOptions{ScoreThreshold: float32(math.NaN())},
+		map[string]any{"vectorQueries": map[string]any{"title": " "}},
+		map[string]any{"mmr": map[string]any{"lambda": 1.5}},
+		map[string]any{"consistency": "strong"},
+		map[string]any{"offset": -1},
+		map[string]any{"offset": 10, "mmr": map[string]any{"lambda": 0.5}},
+		map[string]any{"searchParams": map[string]any{"exact": true, "hnswEf": 128}},
+		"k=5",
+	} {
+		if _, err := parseRetrieverOptions(bad); err == nil {
+			t.Errorf("%v: expected an error", bad)
+		}
+	}
+}
+
+func TestParseIndexerOptions(t *testing.T) {
+	iopt, err := parseIndexerOptions(json.RawMessage(`{"runId": "r1", "continueOnError": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if iopt.RunID != "r1" || !iopt.ContinueOnError {
+		t.Errorf("got %+v", iopt)
+	}
+	if _, err := parseIndexerOptions(map[string]any{"run": "r1"}); err == nil {
+		t.Error("expected an error for an unknown option")
+	}
+	if _, err := parseIndexerOptions(map[string]any{"ordering": "eventual"}); err == nil {
+		t.Error("expected an error for an unknown write ordering")
+	}
+}
@@ -0,0 +1,22 @@
+package qdrant
+
+import qclient "github.com/qdrant/go-client/qdrant"
+
+// writeOrderings maps the values of IndexerOptions.Ordering to Qdrant
+// write orderings.
+var writeOrderings = map[string]qclient.WriteOrderingType{
+	"weak":   qclient.WriteOrderingType_Weak,
+	"medium": qclient.WriteOrderingType_Medium,
+	"strong": qclient.WriteOrderingType_Strong,
+}
+
+// applyWriteOptions sets the wait flag and write ordering of the indexer
+// options on an upsert.
+func (iopt *IndexerOptions) applyWriteOptions(req *qclient.UpsertPoints) {
+	if iopt.Wait {
+		req.Wait = qclient.PtrOf(true)
+	}
+	if iopt.Ordering != "" {
+		req.Ordering = &qclient.WriteOrdering{Type: writeOrderings[iopt.Ordering]}
+	}
+}
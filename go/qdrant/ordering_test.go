@@ -0,0 +1,20 @@
+package qdrant
+
+import (
+	"testing"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestApplyWriteOptions(t *testing.T) {
+	req := &qclient.UpsertPoints{}
+	(&IndexerOptions{}).applyWriteOptions(req)
+	if req.Wait != nil || req.Ordering != nil {
+		t.Errorf("got %v without write options", req)
+	}
+
+	(&IndexerOptions{Wait: true, Ordering: "strong"}).applyWriteOptions(req)
+	if !req.GetWait() || req.GetOrdering().GetType() != qclient.WriteOrderingType_Strong {
+		t.Errorf("got %v, want a strong waited upsert", req)
+	}
+}
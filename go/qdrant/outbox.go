@@ -0,0 +1,227 @@
+package qdrant
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// OutboxEntry is a document waiting in an [Outbox] to be indexed.
+type OutboxEntry struct {
+	ID       string
+	Document *ai.Document
+}
+
+// Outbox is a queue of documents to index, typically written in the same
+// transaction as the application data they derive from.
+//
+// Entries must stay in the outbox until they are acknowledged; an entry
+// fetched but not acknowledged is fetched again later. Combined with
+// point IDs derived from the document content, this gives at-least-once
+// delivery without duplicates in the collection.
+type Outbox interface {
+	// Fetch returns up to n pending entries, oldest first. It may return
+	// no entries if none are pending.
+	Fetch(ctx context.Context, n int) ([]OutboxEntry, error)
+	// Ack removes delivered entries from the outbox.
+	Ack(ctx context.Context, ids []string) error
+}
+
+// OutboxWorker moves documents from an [Outbox] to an indexer, keeping
+// embedding and Qdrant writes out of the request path of the application.
+type OutboxWorker struct {
+	Outbox  Outbox
+	Indexer ai.Indexer
+	// Options are passed to every index request.
+	Options *IndexerOptions
+	// BatchSize is the number of entries indexed per request. Defaults to 64.
+	BatchSize int
+	// PollInterval is the wait when the outbox is empty or a batch fails.
+	// Defaults to 1s.
+	PollInterval time.Duration
+}
+
+// Run delivers entries until ctx is done, and then returns ctx.Err().
+// Failed batches are retried after PollInterval.
+func (w *OutboxWorker) Run(ctx context.Context) error {
+	for {
+		n, err := w.RunOnce(ctx)
+		if err != nil {
+			slog.WarnContext(ctx, "qdrant outbox delivery failed", "error", err)
+		}
+		if err != nil || n == 0 {
+			wait := w.PollInterval
+			if wait <= 0 {
+				wait = time.Second
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		} else if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// RunOnce delivers a single batch and returns the number of entries
+// delivered.
+func (w *OutboxWorker) RunOnce(ctx context.Context) (int, error) {
+	size := w.BatchSize
+	if size <= 0 {
+		size = 64
+	}
+	entries, err := w.Outbox.Fetch(ctx, size)
+	if err != nil {
+		return 0, fmt.Errorf("qdrant outbox fetch failed: %v", err)
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+	docs := make([]*ai.Document, len(entries))
+	ids := make([]string, len(entries))
+	for i, e := range entries {
+		docs[i] = e.Document
+		ids[i] = e.ID
+	}
+	req := &ai.IndexerRequest{Documents: docs}
+	if w.Options != nil {
+		req.Options = w.Options
+	}
+	if err := w.Indexer.Index(ctx, req); err != nil {
+		return 0, err
+	}
+	if err := w.Outbox.Ack(ctx, ids); err != nil {
+		// The entries will be delivered again, which is harmless since
+		// indexing is idempotent.
+		return 0, fmt.Errorf("qdrant outbox ack failed: %v", err)
+	}
+	return len(entries), nil
+}
+
+// ChannelOutbox is an in-process [Outbox] fed by a channel. Entries read
+// from the channel are kept until acknowledged, so a failed batch is
+// delivered again. It does not survive a restart of the process.
+type ChannelOutbox struct {
+	C <-chan OutboxEntry
+
+	mu      sync.Mutex
+	pending []OutboxEntry
+}
+
+// Fetch returns the unacknowledged entries, completed with entries
+// available on the channel without blocking.
+func (o *ChannelOutbox) Fetch(ctx context.Context, n int) ([]OutboxEntry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+fill:
+	for len(o.pending) < n {
+		select {
+		case e, ok := <-o.C:
+			if !ok {
+				break fill
+			}
+			o.pending = append(o.pending, e)
+		default:
+			break fill
+		}
+	}
+	return append([]OutboxEntry(nil), o.pending[:min(n, len(o.pending))]...), nil
+}
+
+// Ack forgets the given entries.
+func (o *ChannelOutbox) Ack(ctx context.Context, ids []string) error {
+	acked := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		acked[id] = true
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	kept := o.pending[:0]
+	for _, e := range o.pending {
+		if !acked[e.ID] {
+			kept = append(kept, e)
+		}
+	}
+	o.pending = kept
+	return nil
+}
+
+// SQLOutbox is an [Outbox] stored in a SQL table with an id column and a
+// document column holding the JSON encoding of an [ai.Document]:
+//
+//	CREATE TABLE qdrant_outbox (id BIGSERIAL PRIMARY KEY, document TEXT NOT NULL)
+//
+// Entries are fetched in id order and deleted when acknowledged.
+type SQLOutbox struct {
+	DB    *sql.DB
+	Table string
+	// Placeholder returns the bind parameter for the i-th argument,
+	// starting at 1. Defaults to "?"; use "$1", "$2", ... for PostgreSQL.
+	Placeholder func(i int) string
+}
+
+// Enqueue adds a document to the outbox within tx, so that it is only
+// indexed if tx commits.
+func (o *SQLOutbox) Enqueue(ctx context.Context, tx *sql.Tx, doc *ai.Document) error {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (document) VALUES (%s)", o.Table, o.placeholder(1)), string(b))
+	return err
+}
+
+// Fetch implements [Outbox.Fetch].
+func (o *SQLOutbox) Fetch(ctx context.Context, n int) ([]OutboxEntry, error) {
+	rows, err := o.DB.QueryContext(ctx, fmt.Sprintf("SELECT id, document FROM %s ORDER BY id LIMIT %d", o.Table, n))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []OutboxEntry
+	for rows.Next() {
+		var id, doc string
+		if err := rows.Scan(&id, &doc); err != nil {
+			return nil, err
+		}
+		d := &ai.Document{}
+		if err := json.Unmarshal([]byte(doc), d); err != nil {
+			return nil, fmt.Errorf("outbox entry %s is not a valid document: %v", id, err)
+		}
+		entries = append(entries, OutboxEntry{ID: id, Document: d})
+	}
+	return entries, rows.Err()
+}
+
+// Ack implements [Outbox.Ack].
+func (o *SQLOutbox) Ack(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	params := make([]string, len(ids))
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		params[i] = o.placeholder(i + 1)
+		args[i] = id
+	}
+	_, err := o.DB.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id IN (%s)", o.Table, strings.Join(params, ", ")), args...)
+	return err
+}
+
+func (o *SQLOutbox) placeholder(i int) string {
+	if o.Placeholder == nil {
+		return "?"
+	}
+	return o.Placeholder(i)
+}
@@ -0,0 +1,53 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// fakeIndexer records indexed documents and fails while err is set.
+type fakeIndexer struct {
+	err     error
+	indexed []*ai.Document
+}
+
+func (f *fakeIndexer) Name() string { return "fake" }
+
+func (f *fakeIndexer) Index(ctx context.Context, req *ai.IndexerRequest) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.indexed = append(f.indexed, req.Documents...)
+	return nil
+}
+
+func TestOutboxWorkerRedelivers(t *testing.T) {
+	ctx := context.Background()
+	c := make(chan OutboxEntry, 3)
+	for _, id := range []string{"1", "2", "3"} {
+		c <- OutboxEntry{ID: id, Document: ai.DocumentFromText("doc "+id, nil)}
+	}
+	outbox := &ChannelOutbox{C: c}
+	indexer := &fakeIndexer{err: errors.New("qdrant down")}
+	w := &OutboxWorker{Outbox: outbox, Indexer: indexer, BatchSize: 2}
+
+	if _, err := w.RunOnce(ctx); err == nil {
+		t.Fatal("expected the first delivery to fail")
+	}
+	indexer.err = nil
+	for _, want := range []int{2, 1, 0} {
+		n, err := w.RunOnce(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != want {
+			t.Errorf("delivered %d entries, want %d", n, want)
+		}
+	}
+	if len(indexer.indexed) != 3 || documentText(indexer.indexed[0]) != "doc 1" {
+		t.Errorf("indexed %d documents, want the 3 entries in order", len(indexer.indexed))
+	}
+}
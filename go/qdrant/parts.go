@@ -0,0 +1,86 @@
+package qdrant
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// PartProjection turns a metadata field of retrieved documents into an
+// additional text part, so that prompts can show e.g. the title of a
+// document before its content without post-processing the results.
+type PartProjection struct {
+	// Field is the metadata key of the value.
+	Field string
+	// Format is the fmt format of the part, applied to the value as a
+	// string, e.g. "Title: %s\n". Defaults to "%s\n" before the content
+	// and "\n%s" after it.
+	Format string
+	// After places the part after the content instead of before it.
+	After bool
+}
+
+// projectParts adds the parts of the projections to a retrieved document.
+// Projections of missing or empty fields add no part.
+func projectParts(doc *ai.Document, projections []PartProjection) {
+	if len(projections) == 0 {
+		return
+	}
+	var before, after []*ai.Part
+	for _, p := range projections {
+		v, ok := doc.Metadata[p.Field].(*qclient.Value)
+		if !ok {
+			continue
+		}
+		text := partText(v)
+		if text == "" {
+			continue
+		}
+		format := p.Format
+		switch {
+		case format != "":
+		case p.After:
+			format = "\n%s"
+		default:
+			format = "%s\n"
+		}
+		part := ai.NewTextPart(fmt.Sprintf(format, text))
+		if p.After {
+			after = append(after, part)
+		} else {
+			before = append(before, part)
+		}
+	}
+	content := make([]*ai.Part, 0, len(before)+len(doc.Content)+len(after))
+	content = append(content, before...)
+	content = append(content, doc.Content...)
+	doc.Content = append(content, after...)
+}
+
+// partText returns the text of a scalar payload value, or of the scalars
+// of a list separated by commas. Other values have no text.
+func partText(v *qclient.Value) string {
+	switch k := v.GetKind().(type) {
+	case *qclient.Value_StringValue:
+		return k.StringValue
+	case *qclient.Value_IntegerValue:
+		return strconv.FormatInt(k.IntegerValue, 10)
+	case *qclient.Value_DoubleValue:
+		return strconv.FormatFloat(k.DoubleValue, 'g', -1, 64)
+	case *qclient.Value_BoolValue:
+		return strconv.FormatBool(k.BoolValue)
+	case *qclient.Value_ListValue:
+		var items []string
+		for _, item := range k.ListValue.GetValues() {
+			if s := partText(item); s != "" {
+				items = append(items, s)
+			}
+		}
+		return strings.Join(items, ", ")
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,35 @@
+package qdrant
+
+import (
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestProjectParts(t *testing.T) {
+	doc := ai.DocumentFromText("body", map[string]any{
+		"title": qclient.NewValueString("Intro"),
+		"tags":  qclient.NewValueList(&qclient.ListValue{Values: []*qclient.Value{qclient.NewValueString("go"), qclient.NewValueInt(2)}}),
+		"empty": qclient.NewValueString(""),
+	})
+	projectParts(doc, []PartProjection{
+		{Field: "title", Format: "# %s\n"},
+		{Field: "tags", After: true},
+		{Field: "empty"},
+		{Field: "missing"},
+	})
+	var got []string
+	for _, p := range doc.Content {
+		got = append(got, p.Text)
+	}
+	want := []string{"# Intro\n", "body", "\ngo, 2"}
+	if len(got) != len(want) {
+		t.Fatalf("got parts %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("part %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+}
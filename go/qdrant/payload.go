@@ -0,0 +1,342 @@
+package qdrant
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// NonFinitePolicy selects how NaN and infinite numbers in document
+// metadata are stored, since Qdrant payloads cannot hold them.
+type NonFinitePolicy int
+
+const (
+	// NonFiniteError fails the conversion of the document.
+	NonFiniteError NonFinitePolicy = iota
+	// NonFiniteNull stores the number as null.
+	NonFiniteNull
+	// NonFiniteString stores the number as "NaN", "+Inf" or "-Inf".
+	NonFiniteString
+)
+
+// LimitPolicy selects what the conversion does with metadata exceeding
+// the limits of a PayloadPolicy.
+type LimitPolicy int
+
+const (
+	// LimitError fails the conversion of the document.
+	LimitError LimitPolicy = iota
+	// LimitTruncate stores what fits: values nested too deeply as null,
+	// the first keys of larger maps in sorted order, and the start of
+	// longer strings.
+	LimitTruncate
+)
+
+// defaultMaxDepth is the default nesting limit of payload values.
+const defaultMaxDepth = 32
+
+// PayloadPolicy configures how document content and metadata are
+// converted to a Qdrant payload. The zero value rejects invalid UTF-8,
+// NaN and infinite numbers, and values nested deeper than 32 levels.
+type PayloadPolicy struct {
+	// ReplaceInvalidUTF8 replaces invalid UTF-8 sequences in strings
+	// with U+FFFD instead of failing.
+	ReplaceInvalidUTF8 bool
+	NonFinite          NonFinitePolicy
+	// MaxDepth limits the nesting of maps and slices. Defaults to 32.
+	MaxDepth int
+	// MaxKeys limits the number of keys of each map, and MaxStringLength
+	// the length in bytes of each string, so that hostile or accidental
+	// metadata cannot slow ingestion down. Zero means no limit. Only the
+	// metadata is limited, not the content of documents.
+	MaxKeys         int
+	MaxStringLength int
+	// Overflow selects what happens to metadata exceeding MaxDepth,
+	// MaxKeys or MaxStringLength. Defaults to LimitError.
+	Overflow LimitPolicy
+}
+
+// payloadConverter converts Go values to payload values following a
+// policy. Besides the types accepted by [qclient.NewValue], it accepts
+// *qclient.Value, as found in retrieved documents, time.Time, json.Number
+// and slices and maps of any element type.
+type payloadConverter struct {
+	policy PayloadPolicy
+}
+
+// unlimited returns the converter without the size limits of its policy,
+// for the content and the fields set by the store.
+func (c payloadConverter) unlimited() payloadConverter {
+	c.policy.MaxKeys, c.policy.MaxStringLength = 0, 0
+	return c
+}
+
+// valueMap converts a payload.
+func (c payloadConverter) valueMap(payload map[string]any) (map[string]*qclient.Value, error) {
+	values := make(map[string]*qclient.Value, len(payload))
+	if err := c.fill(values, payload); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// fill converts a payload into values, e.g. the reused payload map of a
+// point buffer.
+func (c payloadConverter) fill(values map[string]*qclient.Value, payload map[string]any) error {
+	for k, v := range payload {
+		value, err := c.value(v, 1)
+		if err != nil {
+			return fmt.Errorf("%s: %v", k, err)
+		}
+		values[k] = value
+	}
+	return nil
+}
+
+func (c payloadConverter) value(v any, depth int) (*qclient.Value, error) {
+	maxDepth := c.policy.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = defaultMaxDepth
+	}
+	if depth > maxDepth {
+		if c.policy.Overflow == LimitTruncate {
+			return qclient.NewValueNull(), nil
+		}
+		return nil, fmt.Errorf("value nested deeper than %d levels", maxDepth)
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return qclient.NewValueNull(), nil
+	case *qclient.Value:
+		if v == nil {
+			return qclient.NewValueNull(), nil
+		}
+		return v, nil
+	case bool:
+		return qclient.NewValueBool(v), nil
+	case int:
+		return qclient.NewValueInt(int64(v)), nil
+	case int8:
+		return qclient.NewValueInt(int64(v)), nil
+	case int16:
+		return qclient.NewValueInt(int64(v)), nil
+	case int32:
+		return qclient.NewValueInt(int64(v)), nil
+	case int64:
+		return qclient.NewValueInt(v), nil
+	case uint:
+		return c.uint(uint64(v))
+	case uint8:
+		return qclient.NewValueInt(int64(v)), nil
+	case uint16:
+		return qclient.NewValueInt(int64(v)), nil
+	case uint32:
+		return qclient.NewValueInt(int64(v)), nil
+	case uint64:
+		return c.uint(v)
+	case float32:
+		return c.float(float64(v))
+	case float64:
+		return c.float(v)
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return qclient.NewValueInt(n), nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", v)
+		}
+		return c.float(f)
+	case string:
+		return c.string(v)
+	case []byte:
+		return qclient.NewValueString(base64.StdEncoding.EncodeToString(v)), nil
+	case time.Time:
+		return qclient.NewValueString(v.Format(time.RFC3339Nano)), nil
+	// The most common metadata types skip reflection, which boxes every
+	// element again.
+	case map[string]any:
+		keys, err := c.mapKeys(len(v), func() []string {
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			return keys
+		})
+		if err != nil {
+			return nil, err
+		}
+		fields := make(map[string]*qclient.Value, len(v))
+		for k, e := range v {
+			if keys != nil && !keys[k] {
+				continue
+			}
+			value, err := c.value(e, depth+1)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", k, err)
+			}
+			if k, err = c.validString(k); err != nil {
+				return nil, err
+			}
+			fields[k] = value
+		}
+		return qclient.NewValueStruct(&qclient.Struct{Fields: fields}), nil
+	case []any:
+		values := make([]*qclient.Value, len(v))
+		for i, e := range v {
+			value, err := c.value(e, depth+1)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %v", i, err)
+			}
+			values[i] = value
+		}
+		return qclient.NewValueList(&qclient.ListValue{Values: values}), nil
+	case []string:
+		values := make([]*qclient.Value, len(v))
+		for i, s := range v {
+			value, err := c.string(s)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %v", i, err)
+			}
+			values[i] = value
+		}
+		return qclient.NewValueList(&qclient.ListValue{Values: values}), nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", rv.Type().Key())
+		}
+		keys, err := c.mapKeys(rv.Len(), func() []string {
+			keys := make([]string, 0, rv.Len())
+			for _, k := range rv.MapKeys() {
+				keys = append(keys, k.String())
+			}
+			return keys
+		})
+		if err != nil {
+			return nil, err
+		}
+		fields := make(map[string]*qclient.Value, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			k := iter.Key().String()
+			if keys != nil && !keys[k] {
+				continue
+			}
+			value, err := c.value(iter.Value().Interface(), depth+1)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", k, err)
+			}
+			if k, err = c.validString(k); err != nil {
+				return nil, err
+			}
+			fields[k] = value
+		}
+		return qclient.NewValueStruct(&qclient.Struct{Fields: fields}), nil
+	case reflect.Slice, reflect.Array:
+		values := make([]*qclient.Value, rv.Len())
+		for i := range rv.Len() {
+			value, err := c.value(rv.Index(i).Interface(), depth+1)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %v", i, err)
+			}
+			values[i] = value
+		}
+		return qclient.NewValueList(&qclient.ListValue{Values: values}), nil
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return qclient.NewValueNull(), nil
+		}
+		return c.value(rv.Elem().Interface(), depth)
+	}
+	return nil, fmt.Errorf("unsupported type %T", v)
+}
+
+func (c payloadConverter) uint(v uint64) (*qclient.Value, error) {
+	if v > math.MaxInt64 {
+		return c.float(float64(v))
+	}
+	return qclient.NewValueInt(int64(v)), nil
+}
+
+func (c payloadConverter) float(f float64) (*qclient.Value, error) {
+	if !math.IsNaN(f) && !math.IsInf(f, 0) {
+		return qclient.NewValueDouble(f), nil
+	}
+	switch c.policy.NonFinite {
+	case NonFiniteNull:
+		return qclient.NewValueNull(), nil
+	case NonFiniteString:
+		switch {
+		case math.IsNaN(f):
+			return qclient.NewValueString("NaN"), nil
+		case f > 0:
+			return qclient.NewValueString("+Inf"), nil
+		default:
+			return qclient.NewValueString("-Inf"), nil
+		}
+	default:
+		return nil, fmt.Errorf("non-finite number %v", f)
+	}
+}
+
+func (c payloadConverter) string(s string) (*qclient.Value, error) {
+	s, err := c.validString(s)
+	if err != nil {
+		return nil, err
+	}
+	if limit := c.policy.MaxStringLength; limit > 0 && len(s) > limit {
+		if c.policy.Overflow != LimitTruncate {
+			return nil, fmt.Errorf("string of %d bytes, longer than %d", len(s), limit)
+		}
+		for limit > 0 && !utf8.RuneStart(s[limit]) {
+			limit--
+		}
+		s = s[:limit]
+	}
+	return qclient.NewValueString(s), nil
+}
+
+// mapKeys checks the number n of keys of a map against MaxKeys. If the
+// map is too large and the policy truncates, it returns the keys to keep,
+// the first of those listed by keys in sorted order; otherwise it returns
+// nil.
+func (c payloadConverter) mapKeys(n int, keys func() []string) (map[string]bool, error) {
+	limit := c.policy.MaxKeys
+	if limit <= 0 || n <= limit {
+		return nil, nil
+	}
+	if c.policy.Overflow != LimitTruncate {
+		return nil, fmt.Errorf("map of %d keys, more than %d", n, limit)
+	}
+	all := keys()
+	sort.Strings(all)
+	keep := make(map[string]bool, limit)
+	for _, k := range all[:limit] {
+		keep[k] = true
+	}
+	return keep, nil
+}
+
+// validString validates a string, replacing invalid UTF-8 if the policy allows.
+func (c payloadConverter) validString(s string) (string, error) {
+	if utf8.ValidString(s) {
+		return s, nil
+	}
+	if !c.policy.ReplaceInvalidUTF8 {
+		return "", fmt.Errorf("invalid UTF-8 in string %q", s)
+	}
+	return strings.ToValidUTF8(s, "\uFFFD"), nil
+}
@@ -0,0 +1,134 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// PayloadIndexType is the type of a payload index.
+type PayloadIndexType string
+
+const (
+	PayloadIndexKeyword  PayloadIndexType = "keyword"
+	PayloadIndexInteger  PayloadIndexType = "integer"
+	PayloadIndexFloat    PayloadIndexType = "float"
+	PayloadIndexDatetime PayloadIndexType = "datetime"
+	PayloadIndexUUID     PayloadIndexType = "uuid"
+	PayloadIndexBool     PayloadIndexType = "bool"
+)
+
+// PayloadIndexSpec describes a payload index on a document metadata field.
+type PayloadIndexSpec struct {
+	// Field is the metadata key to index. It is resolved relative to
+	// the metadata payload key, e.g. "source" indexes "_metadata.source".
+	Field string
+	Type  PayloadIndexType
+	// OnDisk stores the index on disk instead of in memory.
+	OnDisk bool
+	// IsTenant marks a keyword or UUID field as a tenant key so Qdrant can
+	// co-locate the points of each tenant.
+	IsTenant bool
+	// IsPrincipal marks a numeric or datetime field as the key used by
+	// most filtered requests so Qdrant organizes storage around it.
+	IsPrincipal bool
+	// Lookup and Range select which filters an integer index supports:
+	// exact matches and range conditions respectively. A nil value keeps
+	// the server default, which enables both.
+	Lookup *bool
+	Range  *bool
+}
+
+// metadataField returns the payload path of a metadata key.
+func (ds *DocStore) metadataField(key string) string {
+	return ds.metadataPayloadKey + "." + key
+}
+
+// createPayloadIndexes creates the given payload indexes on the collection.
+func (ds *DocStore) createPayloadIndexes(ctx context.Context, specs []PayloadIndexSpec) error {
+	for _, spec := range specs {
+		fieldType, params, err := spec.indexParams()
+		if err != nil {
+			return err
+		}
+		_, err = ds.client.CreateFieldIndex(ctx, &qclient.CreateFieldIndexCollection{
+			CollectionName:   ds.collectionName,
+			FieldName:        ds.metadataField(spec.Field),
+			FieldType:        fieldType.Enum(),
+			FieldIndexParams: params,
+			Wait:             qclient.PtrOf(true),
+		})
+		if err != nil {
+			return fmt.Errorf("qdrant failed to create payload index on %q: %v", spec.Field, err)
+		}
+		ds.schema.addIndex(ds.metadataField(spec.Field), spec.Type)
+	}
+	return nil
+}
+
+// indexParams converts the spec into the Qdrant field type and index params.
+func (spec PayloadIndexSpec) indexParams() (qclient.FieldType, *qclient.PayloadIndexParams, error) {
+	if spec.Field == "" {
+		return 0, nil, fmt.Errorf("qdrant payload index has no field")
+	}
+	if spec.IsTenant && spec.Type != PayloadIndexKeyword && spec.Type != PayloadIndexUUID {
+		return 0, nil, fmt.Errorf("qdrant payload index on %q: IsTenant is not supported for %s indexes", spec.Field, spec.Type)
+	}
+	if spec.IsPrincipal && (spec.Type == PayloadIndexKeyword || spec.Type == PayloadIndexUUID || spec.Type == PayloadIndexBool) {
+		return 0, nil, fmt.Errorf("qdrant payload index on %q: IsPrincipal is not supported for %s indexes", spec.Field, spec.Type)
+	}
+	if (spec.Lookup != nil || spec.Range != nil) && spec.Type != PayloadIndexInteger {
+		return 0, nil, fmt.Errorf("qdrant payload index on %q: Lookup and Range are only supported for integer indexes", spec.Field)
+	}
+	if spec.Lookup != nil && spec.Range != nil && !*spec.Lookup && !*spec.Range {
+		return 0, nil, fmt.Errorf("qdrant payload index on %q: at least one of Lookup and Range must be enabled", spec.Field)
+	}
+	if spec.OnDisk && spec.Type == PayloadIndexBool {
+		return 0, nil, fmt.Errorf("qdrant payload index on %q: OnDisk is not supported for %s indexes", spec.Field, spec.Type)
+	}
+
+	onDisk := optionalBool(spec.OnDisk)
+	switch spec.Type {
+	case PayloadIndexKeyword:
+		return qclient.FieldType_FieldTypeKeyword, qclient.NewPayloadIndexParamsKeyword(&qclient.KeywordIndexParams{
+			IsTenant: optionalBool(spec.IsTenant),
+			OnDisk:   onDisk,
+		}), nil
+	case PayloadIndexInteger:
+		return qclient.FieldType_FieldTypeInteger, qclient.NewPayloadIndexParamsInt(&qclient.IntegerIndexParams{
+			Lookup:      spec.Lookup,
+			Range:       spec.Range,
+			IsPrincipal: optionalBool(spec.IsPrincipal),
+			OnDisk:      onDisk,
+		}), nil
+	case PayloadIndexFloat:
+		return qclient.FieldType_FieldTypeFloat, qclient.NewPayloadIndexParamsFloat(&qclient.FloatIndexParams{
+			IsPrincipal: optionalBool(spec.IsPrincipal),
+			OnDisk:      onDisk,
+		}), nil
+	case PayloadIndexDatetime:
+		return qclient.FieldType_FieldTypeDatetime, qclient.NewPayloadIndexParamsDatetime(&qclient.DatetimeIndexParams{
+			IsPrincipal: optionalBool(spec.IsPrincipal),
+			OnDisk:      onDisk,
+		}), nil
+	case PayloadIndexUUID:
+		return qclient.FieldType_FieldTypeUuid, qclient.NewPayloadIndexParamsUUID(&qclient.UuidIndexParams{
+			IsTenant: optionalBool(spec.IsTenant),
+			OnDisk:   onDisk,
+		}), nil
+	case PayloadIndexBool:
+		return qclient.FieldType_FieldTypeBool, qclient.NewPayloadIndexParamsBool(&qclient.BoolIndexParams{}), nil
+	default:
+		return 0, nil, fmt.Errorf("qdrant payload index on %q has unsupported type %q", spec.Field, spec.Type)
+	}
+}
+
+// optionalBool returns a pointer to b when it is set, so that unset
+// flags are left to the server defaults.
+func optionalBool(b bool) *bool {
+	if !b {
+		return nil
+	}
+	return &b
+}
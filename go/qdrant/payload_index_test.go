@@ -0,0 +1,56 @@
+package qdrant
+
+import (
+	"testing"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestPayloadIndexParams(t *testing.T) {
+	fieldType, params, err := PayloadIndexSpec{Field: "tenant", Type: PayloadIndexKeyword, IsTenant: true, OnDisk: true}.indexParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fieldType != qclient.FieldType_FieldTypeKeyword {
+		t.Errorf("got field type %v, want keyword", fieldType)
+	}
+	kw := params.GetKeywordIndexParams()
+	if !kw.GetIsTenant() || !kw.GetOnDisk() {
+		t.Errorf("keyword params %v do not carry the tenant and on-disk hints", kw)
+	}
+
+	_, params, err = PayloadIndexSpec{Field: "created", Type: PayloadIndexDatetime, IsPrincipal: true}.indexParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dt := params.GetDatetimeIndexParams()
+	if !dt.GetIsPrincipal() || dt.OnDisk != nil {
+		t.Errorf("datetime params %v: want principal with default on-disk", dt)
+	}
+
+	_, params, err = PayloadIndexSpec{Field: "count", Type: PayloadIndexInteger, Range: qclient.PtrOf(false)}.indexParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := params.GetIntegerIndexParams()
+	if in.Lookup != nil || in.Range == nil || *in.Range {
+		t.Errorf("integer params %v: want default lookup and range disabled", in)
+	}
+}
+
+func TestPayloadIndexParamsInvalid(t *testing.T) {
+	specs := []PayloadIndexSpec{
+		{Type: PayloadIndexKeyword},
+		{Field: "n", Type: PayloadIndexInteger, IsTenant: true},
+		{Field: "k", Type: PayloadIndexKeyword, IsPrincipal: true},
+		{Field: "x", Type: "geo"},
+		{Field: "f", Type: PayloadIndexFloat, Lookup: qclient.PtrOf(true)},
+		{Field: "n", Type: PayloadIndexInteger, Lookup: qclient.PtrOf(false), Range: qclient.PtrOf(false)},
+		{Field: "b", Type: PayloadIndexBool, OnDisk: true},
+	}
+	for _, spec := range specs {
+		if _, _, err := spec.indexParams(); err == nil {
+			t.Errorf("%+v: expected an error", spec)
+		}
+	}
+}
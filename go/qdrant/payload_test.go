@@ -0,0 +1,147 @@
+package qdrant
+
+import (
+	"context"
+	"math"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestPayloadConverterPolicies(t *testing.T) {
+	strict := payloadConverter{}
+	if _, err := strict.value("bad \xff", 1); err == nil {
+		t.Error("expected an error for invalid UTF-8")
+	}
+	if _, err := strict.value(math.NaN(), 1); err == nil {
+		t.Error("expected an error for NaN")
+	}
+
+	lenient := payloadConverter{PayloadPolicy{ReplaceInvalidUTF8: true, NonFinite: NonFiniteString}}
+	v, err := lenient.value(map[string]any{"s": "bad \xff", "f": []float64{math.Inf(-1)}}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := v.GetStructValue().GetFields()
+	if got := fields["s"].GetStringValue(); got != "bad �" {
+		t.Errorf("got %q, want the invalid byte replaced", got)
+	}
+	if got := fields["f"].GetListValue().GetValues()[0].GetStringValue(); got != "-Inf" {
+		t.Errorf("got %q, want -Inf", got)
+	}
+
+	if v, _ := (payloadConverter{PayloadPolicy{NonFinite: NonFiniteNull}}).value(float32(math.Inf(1)), 1); v.GetKind() == nil || v.GetNullValue() != qclient.NullValue_NULL_VALUE {
+		t.Errorf("got %v, want null", v)
+	}
+}
+
+func TestPayloadConverterTypes(t *testing.T) {
+	c := payloadConverter{}
+	v, err := c.value(map[string]any{
+		"tags":      []string{"a", "b"},
+		"counts":    map[string]int{"x": 1},
+		"retrieved": qclient.NewValueString("kept"),
+		"big":       uint64(math.MaxUint64),
+	}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := v.GetStructValue().GetFields()
+	if len(fields["tags"].GetListValue().GetValues()) != 2 ||
+		fields["counts"].GetStructValue().GetFields()["x"].GetIntegerValue() != 1 ||
+		fields["retrieved"].GetStringValue() != "kept" ||
+		fields["big"].GetDoubleValue() == 0 {
+		t.Errorf("unexpected conversion %v", v)
+	}
+
+	if _, err := c.value(map[int]string{1: "a"}, 1); err == nil {
+		t.Error("expected an error for a non-string map key")
+	}
+	var deep any = "leaf"
+	for range defaultMaxDepth + 1 {
+		deep = []any{deep}
+	}
+	if _, err := c.value(deep, 1); err == nil {
+		t.Error("expected an error for a value nested too deeply")
+	}
+}
+
+func FuzzPayloadConverter(f *testing.F) {
+	f.Add("plain", 1.5, 3)
+	f.Add("bad \xff\xfe", math.NaN(), 40)
+	f.Add("", math.Inf(1), 0)
+	f.Fuzz(func(t *testing.T, s string, x float64, depth int) {
+		var v any = map[string]any{s: s, "x": x}
+		for range min(max(depth, 0), 64) {
+			v = []any{v}
+		}
+		c := payloadConverter{PayloadPolicy{ReplaceInvalidUTF8: true, NonFinite: NonFiniteNull, MaxDepth: 100}}
+		got, err := c.value(v, 1)
+		if err != nil {
+			t.Fatalf("lenient policy failed: %v", err)
+		}
+		for got.GetListValue() != nil {
+			got = got.GetListValue().GetValues()[0]
+		}
+		for k, fv := range got.GetStructValue().GetFields() {
+			if !utf8.ValidString(k) || !utf8.ValidString(fv.GetStringValue()) {
+				t.Errorf("invalid UTF-8 left in %q", k)
+			}
+		}
+		// The strict policy may fail but must not panic.
+		payloadConverter{}.value(v, 1)
+	})
+}
+
+func TestPayloadConverterLimits(t *testing.T) {
+	metadata := map[string]any{
+		"c": "héllo",
+		"a": map[string]int{"x": 1, "y": 2, "z": 3},
+		"b": []any{[]any{"deep"}},
+	}
+	strict := payloadConverter{PayloadPolicy{MaxKeys: 2}}
+	if _, err := strict.value(metadata, 1); err == nil {
+		t.Error("expected an error for too many keys")
+	}
+	if _, err := (payloadConverter{PayloadPolicy{MaxStringLength: 3}}).value("héllo", 1); err == nil {
+		t.Error("expected an error for a long string")
+	}
+
+	truncate := payloadConverter{PayloadPolicy{MaxDepth: 3, MaxKeys: 2, MaxStringLength: 2, Overflow: LimitTruncate}}
+	v, err := truncate.value(metadata, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := v.GetStructValue().GetFields()
+	if len(fields) != 2 || fields["c"] != nil {
+		t.Fatalf("got fields %v, want a and b", fields)
+	}
+	if a := fields["a"].GetStructValue().GetFields(); len(a) != 2 || a["z"] != nil {
+		t.Errorf("got %v, want x and y", a)
+	}
+	if deep := fields["b"].GetListValue().GetValues()[0].GetListValue().GetValues()[0]; deep.GetNullValue() != qclient.NullValue_NULL_VALUE || deep.GetKind() == nil {
+		t.Errorf("got %v, want null past the depth limit", deep)
+	}
+	// Strings are cut at a rune boundary.
+	if s, _ := truncate.value("héllo", 1); s.GetStringValue() != "h" {
+		t.Errorf("got %q, want %q", s.GetStringValue(), "h")
+	}
+}
+
+func TestPointContentExemptFromLimits(t *testing.T) {
+	ds := &DocStore{
+		contentPayloadKey:  "content",
+		metadataPayloadKey: "metadata",
+		payloadPolicy:      PayloadPolicy{MaxStringLength: 4},
+	}
+	doc := ai.DocumentFromText("a long document text", map[string]any{"lang": "en"})
+	point, err := ds.point(context.Background(), nil, doc, documentText(doc), []float32{1}, &IndexerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := point.Payload["content"].GetStringValue(); got != "a long document text" {
+		t.Errorf("got content %q", got)
+	}
+}
@@ -0,0 +1,109 @@
+package qdrant
+
+import (
+	"sync"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// pointBuffers holds the messages of the points of a batch, allocated in
+// slabs instead of one by one, along with their payload maps. Large
+// upserts otherwise spend much of their time allocating and collecting
+// these small messages.
+//
+// Buffers are reused across batches through pointBufferPool. They must
+// only be released once the upserts of their batch returned, since the
+// gRPC client marshals requests before returning and no longer
+// references them afterwards.
+type pointBuffers struct {
+	n        int
+	points   []qclient.PointStruct
+	ids      []qclient.PointId
+	uuids    []qclient.PointId_Uuid
+	vectors  []qclient.Vectors
+	dense    []qclient.Vectors_Vector
+	vector   []qclient.Vector
+	payloads []map[string]*qclient.Value
+	scratch  map[string]any
+}
+
+var pointBufferPool = sync.Pool{New: func() any { return new(pointBuffers) }}
+
+// getPointBuffers returns buffers for up to n points.
+func getPointBuffers(n int) *pointBuffers {
+	b := pointBufferPool.Get().(*pointBuffers)
+	if len(b.points) < n {
+		b.points = make([]qclient.PointStruct, n)
+		b.ids = make([]qclient.PointId, n)
+		b.uuids = make([]qclient.PointId_Uuid, n)
+		b.vectors = make([]qclient.Vectors, n)
+		b.dense = make([]qclient.Vectors_Vector, n)
+		b.vector = make([]qclient.Vector, n)
+		b.payloads = make([]map[string]*qclient.Value, n)
+	}
+	return b
+}
+
+// release resets the points handed out and returns the buffers to the
+// pool. The points must no longer be used.
+func (b *pointBuffers) release() {
+	if b == nil {
+		return
+	}
+	for i := range b.n {
+		b.points[i].Reset()
+		b.ids[i].Reset()
+		b.uuids[i] = qclient.PointId_Uuid{}
+		b.vectors[i].Reset()
+		b.dense[i] = qclient.Vectors_Vector{}
+		b.vector[i].Reset()
+		clear(b.payloads[i])
+	}
+	b.n = 0
+	pointBufferPool.Put(b)
+}
+
+// payloadScratch returns an empty map to assemble the payload of a point
+// in. It is only valid until the next call.
+func (b *pointBuffers) payloadScratch() map[string]any {
+	if b == nil {
+		return make(map[string]any)
+	}
+	if b.scratch == nil {
+		b.scratch = make(map[string]any)
+	}
+	clear(b.scratch)
+	return b.scratch
+}
+
+// point returns a new point with the given UUID, holding vector under
+// name and an empty payload. Without buffers, or once they are used up,
+// the point is allocated as usual.
+func (b *pointBuffers) point(id, name string, vector []float32, payloadSize int) *qclient.PointStruct {
+	if b == nil || b.n == len(b.points) {
+		return &qclient.PointStruct{
+			Id:      qclient.NewID(id),
+			Vectors: pointVectors(name, vector),
+			Payload: make(map[string]*qclient.Value, payloadSize),
+		}
+	}
+	i := b.n
+	b.n++
+	b.uuids[i].Uuid = id
+	b.ids[i].PointIdOptions = &b.uuids[i]
+	b.vector[i].Data = vector
+	if name == "" {
+		b.dense[i].Vector = &b.vector[i]
+		b.vectors[i].VectorsOptions = &b.dense[i]
+	} else {
+		b.vectors[i].VectorsOptions = &qclient.Vectors_Vectors{
+			Vectors: &qclient.NamedVectors{Vectors: map[string]*qclient.Vector{name: &b.vector[i]}},
+		}
+	}
+	if b.payloads[i] == nil {
+		b.payloads[i] = make(map[string]*qclient.Value, payloadSize)
+	}
+	p := &b.points[i]
+	p.Id, p.Vectors, p.Payload = &b.ids[i], &b.vectors[i], b.payloads[i]
+	return p
+}
@@ -0,0 +1,42 @@
+package qdrant
+
+import (
+	"testing"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPointBuffers(t *testing.T) {
+	const id = "5c56c793-69f3-4fbf-87e6-c4bf54c28c26"
+	for _, name := range []string{"", "dense"} {
+		want := &qclient.PointStruct{
+			Id:      qclient.NewID(id),
+			Vectors: pointVectors(name, []float32{1, 2}),
+			Payload: map[string]*qclient.Value{},
+		}
+		buf := getPointBuffers(1)
+		for range 2 {
+			// The second point no longer fits and is allocated.
+			if got := buf.point(id, name, []float32{1, 2}, 0); !proto.Equal(got, want) {
+				t.Errorf("vector %q: got %v, want %v", name, got, want)
+			}
+		}
+		var none *pointBuffers
+		if got := none.point(id, name, []float32{1, 2}, 0); !proto.Equal(got, want) {
+			t.Errorf("vector %q without buffers: got %v", name, got)
+		}
+		buf.release()
+	}
+}
+
+func TestPointBuffersRelease(t *testing.T) {
+	buf := getPointBuffers(2)
+	p := buf.point("5c56c793-69f3-4fbf-87e6-c4bf54c28c26", "", []float32{1}, 1)
+	p.Payload["k"] = qclient.NewValueString("v")
+	payload := buf.payloads[0]
+	buf.release()
+	if p.Id != nil || p.Vectors != nil || len(payload) != 0 || buf.n != 0 {
+		t.Errorf("released point not reset: %v, payload %v", p, payload)
+	}
+}
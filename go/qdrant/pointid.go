@@ -0,0 +1,138 @@
+package qdrant
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"unicode/utf8"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/google/uuid"
+)
+
+// idTextChunk is the size of the pieces of text escaped at a time when
+// hashing a document into its point ID.
+const idTextChunk = 32 << 10
+
+// documentID returns the ID of a document: the version 5 UUID of its JSON
+// encoding, as uuid.NewSHA1 returns for json.Marshal(doc). The encoding is
+// hashed as it is produced, escaping long texts piece by piece, so that
+// large documents are not copied into a buffer first.
+//
+// If keys is not nil, only the metadata under those keys is hashed.
+func documentID(doc *ai.Document, keys []string) (string, error) {
+	h := sha1.New()
+	h.Write(uuid.NameSpaceDNS[:])
+	if err := hashDocument(h, doc, keys); err != nil {
+		return "", fmt.Errorf("qdrant: error marshaling document: %v", err)
+	}
+	var id uuid.UUID
+	copy(id[:], h.Sum(nil))
+	id[6] = (id[6] & 0x0f) | 0x50 // version 5
+	id[8] = (id[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return id.String(), nil
+}
+
+// hashDocument writes the JSON encoding of a document to h.
+func hashDocument(h hash.Hash, doc *ai.Document, keys []string) error {
+	if doc == nil {
+		io.WriteString(h, "null")
+		return nil
+	}
+	io.WriteString(h, "{")
+	if len(doc.Content) > 0 {
+		io.WriteString(h, `"content":[`)
+		for i, p := range doc.Content {
+			if i > 0 {
+				io.WriteString(h, ",")
+			}
+			if err := hashPart(h, p); err != nil {
+				return err
+			}
+		}
+		io.WriteString(h, "]")
+	}
+	metadata := doc.Metadata
+	if keys != nil {
+		metadata = make(map[string]any, len(keys))
+		for _, k := range keys {
+			if v, ok := doc.Metadata[k]; ok {
+				metadata[k] = v
+			}
+		}
+	}
+	if len(metadata) > 0 {
+		b, err := json.Marshal(metadata)
+		if err != nil {
+			return err
+		}
+		if len(doc.Content) > 0 {
+			io.WriteString(h, ",")
+		}
+		io.WriteString(h, `"metadata":`)
+		h.Write(b)
+	}
+	io.WriteString(h, "}")
+	return nil
+}
+
+// hashPart writes the JSON encoding of a part to h, following
+// ai.Part.MarshalJSON. Tool parts, which are small, are encoded at once.
+func hashPart(h hash.Hash, p *ai.Part) error {
+	switch {
+	case p == nil:
+		io.WriteString(h, "null")
+	case p.Kind == ai.PartText:
+		if p.Text == "" {
+			io.WriteString(h, "{}")
+			break
+		}
+		io.WriteString(h, `{"text":`)
+		hashString(h, p.Text)
+		io.WriteString(h, "}")
+	case p.Kind == ai.PartData:
+		io.WriteString(h, `{"data":`)
+		hashString(h, p.Text)
+		io.WriteString(h, "}")
+	case p.Kind == ai.PartMedia:
+		io.WriteString(h, `{"media":{`)
+		if p.ContentType != "" {
+			io.WriteString(h, `"contentType":`)
+			hashString(h, p.ContentType)
+			if p.Text != "" {
+				io.WriteString(h, ",")
+			}
+		}
+		if p.Text != "" {
+			io.WriteString(h, `"url":`)
+			hashString(h, p.Text)
+		}
+		io.WriteString(h, "}}")
+	default:
+		b, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+		h.Write(b)
+	}
+	return nil
+}
+
+// hashString writes s to h as a JSON string, escaping it with
+// encoding/json in pieces cut at rune boundaries so that the output is the
+// same as for s as a whole.
+func hashString(h hash.Hash, s string) {
+	io.WriteString(h, `"`)
+	for s != "" {
+		n := min(idTextChunk, len(s))
+		for n < len(s) && !utf8.RuneStart(s[n]) {
+			n++
+		}
+		b, _ := json.Marshal(s[:n])
+		h.Write(b[1 : len(b)-1])
+		s = s[n:]
+	}
+	io.WriteString(h, `"`)
+}
@@ -0,0 +1,84 @@
+package qdrant
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/google/uuid"
+)
+
+// marshaledID is the point ID of a document as computed from its full JSON
+// encoding, which documentID must keep.
+func marshaledID(t testing.TB, doc *ai.Document) string {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return uuid.NewSHA1(uuid.NameSpaceDNS, b).String()
+}
+
+func TestDocumentIDMatchesJSON(t *testing.T) {
+	long := strings.Repeat("é<tag> &  ", idTextChunk/3) + "\xff\xe2\x82"
+	docs := []*ai.Document{
+		nil,
+		{},
+		ai.DocumentFromText("", nil),
+		ai.DocumentFromText("hello \"world\"\n\t\x01", map[string]any{"lang": "en", "n": 3}),
+		ai.DocumentFromText(long, map[string]any{"html": "<b>"}),
+		{Metadata: map[string]any{"only": true}},
+		{Content: []*ai.Part{
+			ai.NewTextPart("a"),
+			nil,
+			ai.NewDataPart(""),
+			ai.NewMediaPart("image/png", "data:image/png;base64,AAAA"),
+			ai.NewMediaPart("", ""),
+			ai.NewToolRequestPart(&ai.ToolRequest{Name: "search", Input: map[string]any{"q": "x"}}),
+		}},
+	}
+	for i, doc := range docs {
+		got, err := documentID(doc, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := marshaledID(t, doc); got != want {
+			t.Errorf("document %d: got ID %s, want %s", i, got, want)
+		}
+	}
+}
+
+func TestDocumentIDKeys(t *testing.T) {
+	a := ai.DocumentFromText("same", map[string]any{"source": "a.md", "fetched": "monday"})
+	b := ai.DocumentFromText("same", map[string]any{"source": "a.md", "fetched": "tuesday"})
+	idA, _ := documentID(a, []string{"source"})
+	idB, _ := documentID(b, []string{"source"})
+	if idA != idB {
+		t.Error("IDs differ on metadata outside the keys")
+	}
+	if all, _ := documentID(a, nil); all == idA {
+		t.Error("restricting the keys did not change the ID")
+	}
+	content, _ := documentID(a, []string{})
+	if want := marshaledID(t, ai.DocumentFromText("same", nil)); content != want {
+		t.Errorf("got %s with no keys, want the ID of the content alone %s", content, want)
+	}
+}
+
+func FuzzDocumentID(f *testing.F) {
+	f.Add("plain", "v")
+	f.Add("bad \xff\xfe <&>", " ")
+	f.Fuzz(func(t *testing.T, text, value string) {
+		doc := &ai.Document{
+			Content:  []*ai.Part{ai.NewTextPart(text), ai.NewDataPart(value)},
+			Metadata: map[string]any{value: text},
+		}
+		got, err := documentID(doc, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := marshaledID(t, doc); got != want {
+			t.Errorf("got ID %s, want %s", got, want)
+		}
+	})
+}
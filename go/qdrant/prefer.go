@@ -0,0 +1,111 @@
+package qdrant
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// defaultPreferBoost is the score added per matching preference when
+// RetrieverOptions.PreferBoost is not set.
+const defaultPreferBoost = 0.1
+
+// queryPreferred runs query restricted to points matching at least one of
+// the preferences, so that preferred documents ranking just outside the
+// plain results can still be promoted.
+func (ds *DocStore) queryPreferred(ctx context.Context, prefer map[string]any, query *qclient.QueryPoints, qv queryVectors) ([]*qclient.ScoredPoint, error) {
+	conds, err := ds.equalityFilter(prefer)
+	if err != nil {
+		return nil, err
+	}
+	preferred := &qclient.QueryPoints{
+		CollectionName:   query.CollectionName,
+		Query:            query.Query,
+		Using:            query.Using,
+		Limit:            query.Limit,
+		WithPayload:      query.WithPayload,
+		Params:           query.Params,
+		ScoreThreshold:   query.ScoreThreshold,
+		ShardKeySelector: query.ShardKeySelector,
+		ReadConsistency:  query.ReadConsistency,
+		Filter: &qclient.Filter{
+			Must:   []*qclient.Condition{qclient.NewFilterAsCondition(query.Filter)},
+			Should: conds.GetMust(),
+		},
+	}
+	return ds.query(ctx, ds.searchQuery(preferred, qv))
+}
+
+// applyPreference adds boost to the score of each result once per
+// preference its metadata matches, sorts the results by descending score
+// and keeps at most limit of them. A limit of zero keeps all.
+func applyPreference(results []*result, prefer map[string]any, boost float32, limit int) []*result {
+	for _, r := range results {
+		for k, want := range prefer {
+			if metadataMatches(r.doc, k, want) {
+				r.score += boost
+			}
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// metadataMatches reports whether the metadata value of doc under key
+// equals want, or contains it if the value is a list, following the
+// matching rules of Qdrant.
+func metadataMatches(doc *ai.Document, key string, want any) bool {
+	v, ok := doc.Metadata[key].(*qclient.Value)
+	if !ok {
+		return false
+	}
+	if list := v.GetListValue(); list != nil {
+		for _, item := range list.GetValues() {
+			if valueEquals(item, want) {
+				return true
+			}
+		}
+		return false
+	}
+	return valueEquals(v, want)
+}
+
+// valueEquals compares a payload value with a value accepted by
+// equalityFilter.
+func valueEquals(v *qclient.Value, want any) bool {
+	switch w := want.(type) {
+	case string:
+		s, ok := v.GetKind().(*qclient.Value_StringValue)
+		return ok && s.StringValue == w
+	case bool:
+		b, ok := v.GetKind().(*qclient.Value_BoolValue)
+		return ok && b.BoolValue == w
+	case int:
+		return integerEquals(v, float64(w))
+	case int64:
+		return integerEquals(v, float64(w))
+	case float64:
+		return w == math.Trunc(w) && integerEquals(v, w)
+	default:
+		return false
+	}
+}
+
+func integerEquals(v *qclient.Value, want float64) bool {
+	switch n := v.GetKind().(type) {
+	case *qclient.Value_IntegerValue:
+		return float64(n.IntegerValue) == want
+	case *qclient.Value_DoubleValue:
+		return n.DoubleValue == want
+	default:
+		return false
+	}
+}
@@ -0,0 +1,32 @@
+package qdrant
+
+import (
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestApplyPreference(t *testing.T) {
+	doc := func(md map[string]any) *ai.Document {
+		d := ai.DocumentFromText("", nil)
+		d.Metadata = make(map[string]any)
+		for k, v := range qclient.NewValueMap(md) {
+			d.Metadata[k] = v
+		}
+		return d
+	}
+	results := []*result{
+		{id: "a", score: 0.9, doc: doc(map[string]any{"lang": "de"})},
+		{id: "b", score: 0.85, doc: doc(map[string]any{"lang": "en", "year": 2024})},
+		{id: "c", score: 0.8, doc: doc(map[string]any{"tags": []any{"go", "en"}})},
+	}
+	got := applyPreference(results, map[string]any{"lang": "en", "year": float64(2024)}, 0.1, 2)
+	if len(got) != 2 || got[0].id != "b" || got[1].id != "a" {
+		t.Errorf("got order %v, %v", got[0].id, got[1].id)
+	}
+
+	if !metadataMatches(results[2].doc, "tags", "go") {
+		t.Error("expected a list value to match one of its items")
+	}
+}
@@ -0,0 +1,50 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ClusterEndpoint is where a provisioned cluster serves gRPC requests.
+type ClusterEndpoint struct {
+	Host   string
+	Port   int
+	APIKey string
+	UseTLS bool
+}
+
+// Provisioner creates or resumes the cluster a store connects to, e.g.
+// through the Qdrant Cloud management API with [CloudProvisioner], so
+// that ephemeral environments such as preview deployments or CI can bring
+// up their own vector store. See Config.Provisioner.
+type Provisioner interface {
+	// Provision returns the endpoint of a running cluster, creating or
+	// resuming it if needed.
+	Provision(ctx context.Context) (*ClusterEndpoint, error)
+}
+
+// ProvisionerFunc adapts a function to the [Provisioner] interface.
+type ProvisionerFunc func(ctx context.Context) (*ClusterEndpoint, error)
+
+// Provision implements [Provisioner].
+func (f ProvisionerFunc) Provision(ctx context.Context) (*ClusterEndpoint, error) {
+	return f(ctx)
+}
+
+// provision replaces the connection settings of cfg with the endpoint of
+// its provisioner, if any.
+func provision(ctx context.Context, cfg *Config) error {
+	if cfg.Provisioner == nil {
+		return nil
+	}
+	ep, err := cfg.Provisioner.Provision(ctx)
+	if err != nil {
+		return fmt.Errorf("qdrant cluster provisioning failed: %v", err)
+	}
+	if ep == nil || ep.Host == "" {
+		return errors.New("qdrant cluster provisioning failed: no endpoint returned")
+	}
+	cfg.GrpcHost, cfg.Port, cfg.ApiKey, cfg.UseTls = ep.Host, ep.Port, ep.APIKey, ep.UseTLS
+	return nil
+}
@@ -0,0 +1,29 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestProvision(t *testing.T) {
+	cfg := Config{GrpcHost: "localhost", Port: 6334}
+	cfg.Provisioner = ProvisionerFunc(func(context.Context) (*ClusterEndpoint, error) {
+		return &ClusterEndpoint{Host: "abc.cloud.qdrant.io", Port: 6334, APIKey: "key", UseTLS: true}, nil
+	})
+	if err := provision(context.Background(), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.GrpcHost != "abc.cloud.qdrant.io" || cfg.ApiKey != "key" || !cfg.UseTls {
+		t.Errorf("got %+v", cfg)
+	}
+
+	for _, p := range []ProvisionerFunc{
+		func(context.Context) (*ClusterEndpoint, error) { return nil, errors.New("quota exceeded") },
+		func(context.Context) (*ClusterEndpoint, error) { return &ClusterEndpoint{}, nil },
+	} {
+		if err := provision(context.Background(), &Config{Provisioner: p}); err == nil {
+			t.Error("expected an error")
+		}
+	}
+}
@@ -2,21 +2,32 @@ package qdrant
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/sha256"
 	"errors"
 	"fmt"
-	"strings"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/firebase/genkit/go/ai"
-	"github.com/google/uuid"
 	qclient "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/grpc"
 )
 
 const provider = "qdrant"
 const contentPayloadKey = "_content"
 const metadataPayloadKey = "_metadata"
 
-// New returns an [ai.DocumentStore] that uses Qdrant.
+var (
+	mu     sync.Mutex
+	stores = make(map[string]*DocStore)
+)
+
+// Config configures the indexer and retriever registered by [Init].
 type Config struct {
 	CollectionName  string
 	GrpcHost        string
@@ -27,174 +38,1162 @@ type Config struct {
 	MetadataKey     string
 	Embedder        ai.Embedder
 	EmbedderOptions any
+	// Url, if set, replaces GrpcHost, Port and UseTls, e.g.
+	// "https://xyz.cloud.qdrant.io:6333". The REST port 6333 is mapped to
+	// the gRPC port 6334, which is also the default.
+	Url string
+	// PayloadIndexes are created on the collection at Init.
+	PayloadIndexes []PayloadIndexSpec
+	// Capture, if set, receives every retrieval with its scores so it
+	// can later be labeled as an evaluation dataset.
+	Capture CaptureSink
+	// FeedbackCollection is an auxiliary collection storing relevance
+	// feedback recorded with [DocStore.RecordFeedback]. It is created at
+	// Init if it does not exist.
+	FeedbackCollection string
+	// EntityModel, if set, extracts named entities from each document at
+	// index time. They are stored as an indexed keyword payload field.
+	EntityModel ai.Model
+	// Quarantine receives the documents skipped because of
+	// [IndexerOptions.ContinueOnError].
+	Quarantine QuarantineSink
+	// ShardPerTenant stores each tenant under its own shard key. The
+	// collection must use custom sharding. Index requests must name their
+	// tenant, whose shard key is created the first time it is used.
+	ShardPerTenant bool
+	// TenantKey, if set, is the payload field holding the tenant of each
+	// point, so that one collection can serve many tenants without custom
+	// sharding. Index and retrieve requests must then name their tenant,
+	// which is stored in and filtered by that field.
+	TenantKey string
+	// Throttle, if set, retries requests throttled by Qdrant after the
+	// delay hinted by the server. See [DocStore.ThrottleStats].
+	Throttle *ThrottleConfig
+	// Retry, if set, retries upserts and queries failing with a transient
+	// error such as Unavailable, with exponential backoff.
+	Retry *RetryConfig
+	// Connection sizes the gRPC connections, optionally tuning them to
+	// the latency measured at Init.
+	Connection ConnectionConfig
+	// SpoolDir, if set, is a directory where upserts are stored while
+	// Qdrant is unreachable. They are replayed before the next upsert, in
+	// the background once Qdrant is reachable again, or by
+	// [DocStore.DrainSpool].
+	SpoolDir string
+	// DimensionFix selects what the indexer does when the embedded vectors
+	// do not match the vector size of the collection.
+	DimensionFix DimensionFix
+	// Payload configures how document content and metadata that Qdrant
+	// cannot store as is, such as invalid UTF-8, are handled.
+	Payload PayloadPolicy
+	// Vectors selects what the indexer does with embeddings holding NaN
+	// or infinite values. Query embeddings holding them always fail the
+	// retrieval unless the policy is VectorClamp.
+	Vectors VectorPolicy
+	// AutoCreate creates the collection at Init if it does not exist,
+	// with VectorSize and Distance. Init fails if it cannot be created.
+	// Index requests create it again after it is deleted through
+	// [Collections].
+	AutoCreate bool
+	// VectorSize is the vector size of an auto-created collection. If
+	// zero, it is the size of a probe embedding made at Init.
+	VectorSize int
+	// Distance is the distance of an auto-created collection. Defaults
+	// to cosine.
+	Distance qclient.Distance
+	// HNSW tunes the vector index of an auto-created collection.
+	HNSW *HNSWConfig
+	// VectorName is the logical name of the vector documents are indexed
+	// into and queried with. Empty uses the unnamed default vector.
+	VectorName string
+	// VectorAliases maps logical vector names to physical named vectors
+	// of the collection, e.g. "text" to "text-v2", so that an embedder
+	// upgrade can switch vectors without changing the code using the
+	// store. See [DocStore.SetVectorAlias].
+	VectorAliases map[string]string
+	// NegativeCache, if set, makes the retriever fail fast with
+	// [ErrInsufficientContext] on queries that recently returned no
+	// documents.
+	NegativeCache *NegativeCacheConfig
+	// CondenseModel rewrites follow-up questions into standalone queries
+	// using RetrieverOptions.History.
+	CondenseModel ai.Model
+	// QueryGuard, if set, skips retrieval for empty or trivially short
+	// queries.
+	QueryGuard *QueryGuardConfig
+	// Safety, if set, classifies retrieved documents and drops or flags
+	// the unsafe ones before they reach the prompt.
+	Safety *SafetyConfig
+	// IndexTimeout and RetrieveTimeout, if set, bound the duration of an
+	// index and a retrieve request, including embedding, so that a hung
+	// Qdrant node cannot block a flow indefinitely.
+	IndexTimeout    time.Duration
+	RetrieveTimeout time.Duration
+	// Hybrid, if set, indexes and queries a sparse vector besides the
+	// dense one. See [HybridConfig].
+	Hybrid *HybridConfig
+	// ClientFusion fuses hybrid and per-vector searches with reciprocal
+	// rank fusion on the client, running them as parallel searches,
+	// instead of in a Qdrant fusion query. It is also used as a fallback
+	// against servers without the Query API.
+	ClientFusion bool
+	// VectorEmbedders maps the logical names of additional vectors to the
+	// embedders filling them, so that a collection can hold e.g. a title
+	// vector besides the body vector of Embedder. Embedders receive whole
+	// documents and may embed their metadata. Requires VectorName, which
+	// names the vector of Embedder; RetrieverOptions.Vector selects the
+	// vector queried.
+	VectorEmbedders map[string]ai.Embedder
+	// DualWrite, if set, also indexes documents with a second embedder
+	// while migrating to it. See [DualWriteConfig].
+	DualWrite *DualWriteConfig
+	// MultiVector, if set, also indexes late interaction multivectors,
+	// queried directly or to rerank the dense results. Requires
+	// VectorName. See [MultiVectorConfig].
+	MultiVector *MultiVectorConfig
+	// Parts turns metadata fields of retrieved documents into additional
+	// text parts around their content. See [PartProjection].
+	Parts []PartProjection
+	// IndexBatchSize splits index requests into batches of this many
+	// documents, each embedded and upserted on its own. Zero indexes a
+	// request in a single batch.
+	IndexBatchSize int
+	// IndexWorkers is the number of batches indexed concurrently.
+	// Defaults to 1.
+	IndexWorkers int
+	// AutoCreateFilterIndexes creates the payload index a query misses
+	// when the collection requires indexes for filtered fields, with the
+	// type inferred from the filter condition, and retries the query once.
+	AutoCreateFilterIndexes bool
+	// SchemaCacheTTL is how long the payload indexes of the collection
+	// are cached for [DocStore.ValidateFilter] and
+	// AutoCreateFilterIndexes. Defaults to one minute; a negative value
+	// disables the cache.
+	SchemaCacheTTL time.Duration
+	// Provisioner, if set, is called at Init to create or resume the
+	// cluster. Its endpoint replaces GrpcHost, Port, ApiKey and UseTls.
+	Provisioner Provisioner
+	// Namespace, if set, is stored with every point and restricts all
+	// queries, scrolls, counts and deletes to the points of the
+	// namespace, so that several applications or environments can share
+	// a collection. Documents get distinct IDs in each namespace.
+	Namespace string
+	// Environment, if set, is stored as the "env" field of every point,
+	// e.g. "staging" or "production", and retrievals only return points
+	// of the same environment unless RetrieverOptions.AnyEnvironment is
+	// set, so that staging data cannot leak into production prompts.
+	Environment string
+	// IDMetadataKeys, if set, restricts the metadata hashed into the
+	// point ID of a document to the given keys, so that changes of other
+	// metadata, such as a fetch timestamp, update the point of the
+	// document instead of adding a new one. By default all the metadata
+	// is hashed, and an empty list hashes the content alone. Changing it
+	// changes the IDs of indexed documents.
+	IDMetadataKeys []string
+	// ReadOnly registers only the retriever and rejects every write to the
+	// collection with an error wrapping [ErrReadOnly], for services that
+	// must never mutate a shared index. Init then creates neither the
+	// collection nor its payload indexes. Feedback is still recorded in
+	// Config.FeedbackCollection, which must then exist.
+	ReadOnly bool
+	// DevLocal starts a local Qdrant server in a Docker container at Init
+	// if none listens on GrpcHost and Port, which default to
+	// localhost:6334, so that local development needs no setup. Stop it
+	// with [StopDevLocal].
+	DevLocal bool
 }
 
 func Init(ctx context.Context, cfg Config) (err error) {
-	client, err := qclient.NewClient(&qclient.Config{
-		Host:   cfg.GrpcHost,
-		Port:   cfg.Port,
-		APIKey: cfg.ApiKey,
-		UseTLS: cfg.UseTls,
-	})
+	if err := applyURL(&cfg); err != nil {
+		return err
+	}
+	if err := provision(ctx, &cfg); err != nil {
+		return err
+	}
+	if cfg.DevLocal {
+		if err := startDevLocal(ctx, &cfg); err != nil {
+			return err
+		}
+	}
+	var throttle *throttler
+	var grpcOptions []grpc.DialOption
+	if cfg.Throttle != nil {
+		throttle = newThrottler(*cfg.Throttle)
+		grpcOptions = append(grpcOptions, grpc.WithChainUnaryInterceptor(throttle.unaryInterceptor))
+	}
+	if cfg.Retry != nil {
+		grpcOptions = append(grpcOptions, grpc.WithChainUnaryInterceptor(newRetrier(*cfg.Retry).unaryInterceptor))
+	}
+	pool, err := connect(ctx, &qclient.Config{
+		Host:        cfg.GrpcHost,
+		Port:        cfg.Port,
+		APIKey:      cfg.ApiKey,
+		UseTLS:      cfg.UseTls,
+		GrpcOptions: grpcOptions,
+	}, cfg.Connection)
 
 	if err != nil {
 		return fmt.Errorf("failed to instantiate Qdrant client: %w", err)
 	}
-	store := &docStore{
-		client: client,
+	client := pool[0]
+	store := &DocStore{
+		collectionName:     cfg.CollectionName,
+		client:             client,
+		pool:               pool,
+		embedder:           cfg.Embedder,
+		embedderOptions:    cfg.EmbedderOptions,
+		contentPayloadKey:  cfg.ContentKey,
+		metadataPayloadKey: cfg.MetadataKey,
+		capture:            cfg.Capture,
+		feedbackCollection: cfg.FeedbackCollection,
+		entityModel:        cfg.EntityModel,
+		quarantineSink:     cfg.Quarantine,
+		shardPerTenant:     cfg.ShardPerTenant,
+		tenantKey:          cfg.TenantKey,
+		throttler:          throttle,
+		payloadIndexes:     cfg.PayloadIndexes,
+		dimensionFix:       cfg.DimensionFix,
+		payloadPolicy:      cfg.Payload,
+		vectorPolicy:       cfg.Vectors,
+		autoCreate:         cfg.AutoCreate,
+		vectorSize:         cfg.VectorSize,
+		distance:           cfg.Distance,
+		logicalVector:      cfg.VectorName,
+		vectorAliases:      maps.Clone(cfg.VectorAliases),
+		dualWrite:          cfg.DualWrite,
+		condenseModel:      cfg.CondenseModel,
+		parts:              slices.Clone(cfg.Parts),
+		indexBatchSize:     cfg.IndexBatchSize,
+		indexWorkers:       cfg.IndexWorkers,
+		autoFilterIndexes:  cfg.AutoCreateFilterIndexes,
+		schema:             newSchemaCache(cfg.SchemaCacheTTL),
+		namespace:          cfg.Namespace,
+		environment:        cfg.Environment,
+		idMetadataKeys:     slices.Clone(cfg.IDMetadataKeys),
+		readOnly:           cfg.ReadOnly,
+		indexTimeout:       cfg.IndexTimeout,
+		retrieveTimeout:    cfg.RetrieveTimeout,
+		clientFusion:       cfg.ClientFusion,
+	}
+	if cfg.QueryGuard != nil {
+		store.guard = newQueryGuard(*cfg.QueryGuard)
+	}
+	if cfg.Hybrid != nil {
+		store.hybrid = newHybrid(*cfg.Hybrid)
+	}
+	if cfg.NegativeCache != nil {
+		store.negative = newNegativeCache(*cfg.NegativeCache)
+	}
+	if store.contentPayloadKey == "" {
+		store.contentPayloadKey = contentPayloadKey
+	}
+	if store.metadataPayloadKey == "" {
+		store.metadataPayloadKey = metadataPayloadKey
+	}
+	if store.tenantKey != "" && (store.tenantKey == store.contentPayloadKey || store.tenantKey == store.metadataPayloadKey) {
+		return fmt.Errorf("qdrant: Config.TenantKey %q is the payload key of the content or metadata", store.tenantKey)
+	}
+	if err := store.checkDimensionFix(); err != nil {
+		return err
+	}
+	if store.distance == qclient.Distance_UnknownDistance {
+		store.distance = qclient.Distance_Cosine
+	}
+	if err := store.dualWrite.validate(store.vectorName()); err != nil {
+		return err
+	}
+	if store.namedEmbedders, err = newNamedEmbedders(cfg.VectorEmbedders, cfg.VectorName); err != nil {
+		return err
+	}
+	if cfg.MultiVector != nil {
+		if store.multiVector, err = newMultiVector(*cfg.MultiVector, store.vectorName()); err != nil {
+			return err
+		}
+	}
+	if cfg.HNSW != nil {
+		if err := cfg.HNSW.validate(); err != nil {
+			return err
+		}
+		hnsw := *cfg.HNSW
+		store.hnsw = &hnsw
+	}
+	if cfg.Safety != nil {
+		if store.safety, err = newSafetyFilter(*cfg.Safety); err != nil {
+			return err
+		}
+	}
+	if cfg.SpoolDir != "" {
+		if store.spool, err = openSpool(cfg.SpoolDir); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case cfg.ReadOnly:
+	case cfg.AutoCreate:
+		if err := store.ensureCollection(ctx, store.probeVectorSize); err != nil {
+			return err
+		}
+	default:
+		if err := store.createIndexes(ctx); err != nil {
+			return err
+		}
+	}
+	if store.feedbackCollection != "" && !cfg.ReadOnly {
+		if err := store.ensureFeedbackCollection(ctx); err != nil {
+			return err
+		}
 	}
 
 	name := cfg.CollectionName
-	ai.DefineIndexer(provider, name, store.Index)
+	if !cfg.ReadOnly {
+		ai.DefineIndexer(provider, name, store.Index)
+	}
 	ai.DefineRetriever(provider, name, store.Retrieve)
+	if store.spool != nil && !cfg.ReadOnly {
+		// The store lives as long as the process, not as the context of
+		// Init.
+		go store.drainSpoolLoop(context.WithoutCancel(ctx), spoolDrainInterval)
+	}
+
+	mu.Lock()
+	stores[name] = store
+	mu.Unlock()
 	return nil
 }
 
-// Indexer returns the indexer with the given collection name.
+// Indexer returns the indexer with the given collection name, or nil if
+// there is none, e.g. because the collection was initialized with
+// Config.ReadOnly.
 func Indexer(name string) ai.Indexer {
-	return ai.LookupIndexer(provider, name)
+	// Genkit returns a nil action inside a non-nil interface.
+	if idx := ai.LookupIndexer(provider, name); !reflect.ValueOf(idx).IsNil() {
+		return idx
+	}
+	return nil
 }
 
-// Retriever returns the retriever with the given collection name.
+// Retriever returns the retriever with the given collection name, or nil
+// if there is none.
 func Retriever(name string) ai.Retriever {
-	return ai.LookupRetriever(provider, name)
+	if r := ai.LookupRetriever(provider, name); !reflect.ValueOf(r).IsNil() {
+		return r
+	}
+	return nil
+}
+
+// Store returns the document store with the given collection name,
+// or nil if [Init] was not called for it.
+func Store(name string) *DocStore {
+	mu.Lock()
+	defer mu.Unlock()
+	return stores[name]
 }
 
-type IndexerOptions struct{}
+// IndexerOptions are the options accepted by the indexer. They may be
+// passed as an *IndexerOptions or, from remote clients, as a JSON object
+// using the field names in the json tags.
+type IndexerOptions struct {
+	// RunID tags every indexed point with the ingestion run it belongs
+	// to, so that a bad run can be undone with [DocStore.RollbackRun].
+	RunID string `json:"runId,omitempty"`
+	// ContinueOnError skips documents that fail to embed or convert
+	// instead of failing the whole request. Skipped documents are passed
+	// to Config.Quarantine.
+	ContinueOnError bool `json:"continueOnError,omitempty"`
+	// Tenant is the tenant owning the documents. See Config.ShardPerTenant
+	// and Config.TenantKey.
+	Tenant string `json:"tenant,omitempty"`
+	// BatchSize and Workers override Config.IndexBatchSize and
+	// Config.IndexWorkers for this request.
+	BatchSize int `json:"batchSize,omitempty"`
+	Workers   int `json:"workers,omitempty"`
+	// Wait makes each upsert return only once its points are applied and
+	// searchable, so that a flow can retrieve them right after indexing.
+	// Without it, upserts return as soon as Qdrant received them.
+	Wait bool `json:"wait,omitempty"`
+	// ShardKey is the shard key the documents are written to, on
+	// collections using custom sharding. It is created the first time it
+	// is used. It cannot be combined with Config.ShardPerTenant.
+	ShardKey string `json:"shardKey,omitempty"`
+	// Ordering is the write ordering of the upserts on replicated
+	// collections: "weak" (the default, fastest), "medium" or "strong"
+	// (consistent, through the permanent shard leader).
+	Ordering string `json:"ordering,omitempty"`
+	// ReportDuplicates passes the documents dropped because a later
+	// document of the request has the same point ID to Config.Quarantine,
+	// with a *DuplicateDocumentError. Such documents are always dropped,
+	// as the later one would overwrite them.
+	ReportDuplicates bool `json:"reportDuplicates,omitempty"`
+}
 
+// RetrieverOptions are the options accepted by the retriever. They may be
+// passed as a *RetrieverOptions or, from remote clients, as a JSON object
+// using the field names in the json tags. In JSON, the filter uses the
+// protobuf JSON encoding of [qclient.Filter].
 type RetrieverOptions struct {
-	Filter qclient.Filter
-	K      int // maximum number of values to retrieve
+	Filter qclient.Filter `json:"-"`
+	K      int            `json:"k,omitempty"` // maximum number of values to retrieve
+	// Offset skips the first results, so that callers can page through
+	// them K at a time. It cannot be combined with the options reordering
+	// the results on the client: MMR, Prefer, UseEntities, FeedbackBoost
+	// and SessionBoost.
+	Offset int `json:"offset,omitempty"`
+	// IndexedOnly skips segments that are not indexed yet, trading
+	// completeness for latency while a large ingestion is running.
+	IndexedOnly bool `json:"indexedOnly,omitempty"`
+	// SearchParams tunes the vector search, e.g. for exact search in
+	// evaluation runs. See [SearchParams].
+	SearchParams *SearchParams `json:"searchParams,omitempty"`
+	// Shards restricts the search to a subset of the collection's shards.
+	Shards *ShardSelector `json:"-"`
+	// ShardKeys restricts the search to the shards of the given shard
+	// keys, like Shards.Include, for remote clients. It cannot be combined
+	// with Shards.
+	ShardKeys []string `json:"shardKeys,omitempty"`
+	// FeedbackBoost, if non-zero, is added to the score of each result
+	// once per net positive feedback recorded for it, and subtracted per
+	// net negative feedback. Requires Config.FeedbackCollection.
+	FeedbackBoost float32 `json:"feedbackBoost,omitempty"`
+	// UseEntities ranks documents sharing entities with the query first,
+	// followed by plain vector results. Requires Config.EntityModel.
+	UseEntities bool `json:"useEntities,omitempty"`
+	// Where restricts results to documents whose metadata holds the
+	// given values. Strings, bools and whole numbers are supported. The
+	// conditions are combined with Filter.
+	Where map[string]any `json:"where,omitempty"`
+	// Prefer ranks documents whose metadata holds the given values
+	// higher without excluding the others. Values follow the rules of
+	// Where. Each matching preference adds PreferBoost to the score of a
+	// document; if PreferBoost is zero, 0.1 is used.
+	Prefer      map[string]any `json:"prefer,omitempty"`
+	PreferBoost float32        `json:"preferBoost,omitempty"`
+	// Tenant restricts the search to the shard key of a tenant, or to its
+	// points under Config.TenantKey. See Config.ShardPerTenant. A shard
+	// key tenant cannot be combined with Shards.
+	Tenant string `json:"tenant,omitempty"`
+	// CountAvailable counts the points matching the filter and attaches
+	// the count to every result, see [Result.Available]. Retrieval never
+	// fails because fewer than K points are available; the response is
+	// just shorter. An empty response carries no count: use
+	// [WithAvailable] to receive it in any case.
+	CountAvailable bool `json:"countAvailable,omitempty"`
+	// ScoreThreshold, if non-zero, makes Qdrant drop results scoring
+	// below it. For distances where lower is better, such as Euclid,
+	// results scoring above it are dropped instead.
+	ScoreThreshold float32 `json:"scoreThreshold,omitempty"`
+	// SessionBoost, if non-zero, is added to the score of documents
+	// already retrieved in the session of the context, see
+	// [WithSession]. A negative boost demotes them instead.
+	SessionBoost float32 `json:"sessionBoost,omitempty"`
+	// SessionExclude skips documents already retrieved in the session of
+	// the context.
+	SessionExclude bool `json:"sessionExclude,omitempty"`
+	// ConfidenceThreshold is the score counted as a confident match by
+	// the confidence indicator attached to the results, see
+	// [ConfidenceFromResponse]. Defaults to ScoreThreshold.
+	ConfidenceThreshold float32 `json:"confidenceThreshold,omitempty"`
+	// Search selects the vectors searched when Config.Hybrid is set.
+	// Defaults to both.
+	Search SearchMode `json:"search,omitempty"`
+	// History holds the prior messages of a conversation. The query is
+	// then condensed with them into a standalone query before embedding.
+	// Requires Config.CondenseModel.
+	History []*ai.Message `json:"history,omitempty"`
+	// Vector is the logical name of the vector to query, among
+	// Config.VectorName and Config.VectorEmbedders. Defaults to
+	// Config.VectorName.
+	Vector string `json:"vector,omitempty"`
+	// VectorQueries maps logical vector names to the text searched in
+	// each, e.g. a short title query for a title vector and a longer one
+	// for the body vector. The searches are fused with reciprocal rank
+	// fusion and replace the dense search of the query document.
+	VectorQueries map[string]string `json:"vectorQueries,omitempty"`
+	// MMR, if set, diversifies the results with maximal marginal
+	// relevance. See [MMR].
+	MMR *MMR `json:"mmr,omitempty"`
+	// GroupBy, if set, returns the top hits of the K best groups of
+	// results sharing a metadata value. See [GroupBy]. It cannot be
+	// combined with Offset or the options reordering the results on the
+	// client.
+	GroupBy *GroupBy `json:"groupBy,omitempty"`
+	// Recommend, if set, retrieves documents like and unlike the given
+	// examples instead of searching for the query. See [Recommend]. It
+	// cannot be combined with VectorQueries or a sparse search.
+	Recommend *Recommend `json:"recommend,omitempty"`
+	// Discover, if set, searches around a target constrained by context
+	// pairs of examples instead of searching for the query alone. See
+	// [Discover]; its scores are not similarities. It cannot be combined
+	// with Recommend, VectorQueries or a sparse search.
+	Discover *Discover `json:"discover,omitempty"`
+	// AnyEnvironment returns points of every environment instead of only
+	// those of Config.Environment.
+	AnyEnvironment bool `json:"anyEnvironment,omitempty"`
+	// Consistency is the read consistency of the queries on replicated
+	// collections: "majority", "quorum", "all", or the number of replicas
+	// to query, e.g. "2". Defaults to a single replica.
+	Consistency string `json:"consistency,omitempty"`
 }
 
-// docStore implements the genkit [ai.DocumentStore] interface.
-type docStore struct {
+// DocStore implements the genkit [ai.DocumentStore] interface.
+// It also exposes collection-level helpers beyond indexing and retrieval.
+type DocStore struct {
 	collectionName     string
 	client             *qclient.Client
 	embedder           ai.Embedder
 	embedderOptions    any
 	contentPayloadKey  string
 	metadataPayloadKey string
+	capture            CaptureSink
+	feedbackCollection string
+	entityModel        ai.Model
+	quarantineSink     QuarantineSink
+	shardPerTenant     bool
+	tenantKey          string
+	throttler          *throttler
+	spool              *spool
+	payloadIndexes     []PayloadIndexSpec
+	dimensionFix       DimensionFix
+	payloadPolicy      PayloadPolicy
+	vectorPolicy       VectorPolicy
+	autoCreate         bool
+	vectorSize         int
+	distance           qclient.Distance
+	logicalVector      string
+	dualWrite          *DualWriteConfig
+	negative           *negativeCache
+	hybrid             *HybridConfig
+	guard              *queryGuard
+	condenseModel      ai.Model
+	namedEmbedders     []namedEmbedder
+	multiVector        *MultiVectorConfig
+	parts              []PartProjection
+	indexBatchSize     int
+	indexWorkers       int
+	autoFilterIndexes  bool
+	schema             *schemaCache
+	namespace          string
+	environment        string
+	idMetadataKeys     []string
+	readOnly           bool
+	indexTimeout       time.Duration
+	retrieveTimeout    time.Duration
+	safety             *safetyFilter
+	clientFusion       bool
+	hnsw               *HNSWConfig
+
+	versionMu      sync.Mutex
+	versionFetched bool
+	versionKnown   bool
+	version        version
+
+	aliasMu       sync.RWMutex
+	vectorAliases map[string]string
+
+	shardMu     sync.Mutex
+	knownShards map[string]bool // shard keys known to exist
+
+	sessions sessionMemory
+
+	usageMu sync.Mutex
+	usage   map[string]*Usage // by tenant
+
+	pool     []*qclient.Client // channels for queries and upserts
+	poolNext atomic.Uint64
+
+	createMu        sync.Mutex
+	collectionReady bool // the auto-created collection exists
 }
 
 // Index implements the genkit Retriever.Index method.
-func (ds *docStore) Index(ctx context.Context, req *ai.IndexerRequest) error {
+func (ds *DocStore) Index(ctx context.Context, req *ai.IndexerRequest) (err error) {
+	defer recoverPanic(ctx, &err, "index")
+	if err := ds.writable("Index"); err != nil {
+		return err
+	}
 	if len(req.Documents) == 0 {
 		return nil
 	}
-
-	ereq := &ai.EmbedRequest{
-		Documents: req.Documents,
-		Options:   ds.embedderOptions,
+	iopt, err := parseIndexerOptions(req.Options)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := withTimeout(ctx, "Index", ds.indexTimeout)
+	defer cancel()
+	shardKey, err := ds.indexShardKey(ctx, iopt)
+	if err != nil {
+		return timeoutError(ctx, err)
 	}
-	vals, err := ds.embedder.Embed(ctx, ereq)
+	docs := ds.dedupe(ctx, req.Documents, iopt)
+	return timeoutError(ctx, ds.indexBatches(ctx, docs, iopt, shardKey))
+}
+
+// indexBatch embeds and upserts one batch of documents.
+func (ds *DocStore) indexBatch(ctx context.Context, docs []*ai.Document, iopt *IndexerOptions, shardKey *qclient.ShardKeySelector) (err error) {
+	// Batches may run on their own goroutines, where a panic would not
+	// reach the recovery of Index.
+	defer recoverPanic(ctx, &err, "index batch")
 	// Use the embedder to convert each Document into a vector.
-	points := make([]*qclient.PointStruct, 0, len(req.Documents))
-	for i, doc := range req.Documents {
+	ds.recordEmbedding(iopt.Tenant, docs)
+	vectors, err := ds.embedDocuments(ctx, docs, iopt)
+	if err != nil {
+		return err
+	}
 
-		if err != nil {
-			return fmt.Errorf("qdrant index embedding failed: %v", err)
+	var migrated [][]float32
+	if ds.dualWrite != nil {
+		if migrated, err = ds.embedWith(ctx, ds.dualWrite.Embedder, ds.dualWrite.EmbedderOptions, docs, iopt); err != nil {
+			return err
 		}
+	}
 
-		id, err := generatePointId(doc)
-		if err != nil {
-			return err
+	for _, v := range vectors {
+		if v != nil {
+			size := func(context.Context) (int, error) { return len(v), nil }
+			if err := ds.ensureCollection(ctx, size); err != nil {
+				return err
+			}
+			break
 		}
+	}
 
-		var sb strings.Builder
-		for _, p := range doc.Content {
-			sb.WriteString(p.Text)
+	named, err := ds.embedNamed(ctx, docs, iopt)
+	if err != nil {
+		return err
+	}
+	texts := documentTexts(docs)
+	var sparse []SparseVector
+	if ds.hybrid != nil {
+		if sparse, err = ds.hybrid.encode(ctx, texts); err != nil {
+			return err
 		}
+	}
+	tokens, err := ds.multiVector.encode(ctx, texts)
+	if err != nil {
+		return err
+	}
 
-		point := &qclient.PointStruct{
-			Id:      qclient.NewID(id),
-			Vectors: qclient.NewVectors(vals.Embeddings[i].Embedding...),
-			Payload: qclient.NewValueMap(map[string]any{
-				contentPayloadKey:  sb.String(),
-				metadataPayloadKey: doc.Metadata,
-			}),
+	// The buffers are released once the upserts below returned.
+	buf := getPointBuffers(len(docs))
+	defer buf.release()
+	points := make([]*qclient.PointStruct, 0, len(docs))
+	var mirrored []*qclient.PointStruct
+	for i, doc := range docs {
+		if vectors[i] == nil || (migrated != nil && migrated[i] == nil) || missingVector(named, i) {
+			continue
+		}
+		vector, err := checkVector(vectors[i], i, ds.vectorPolicy)
+		var migratedVector []float32
+		if err == nil && migrated != nil {
+			migratedVector, err = checkVector(migrated[i], i, ds.vectorPolicy)
+		}
+		if err != nil {
+			if ds.vectorPolicy != VectorSkip {
+				return err
+			}
+			ds.quarantine(ctx, doc, err)
+			continue
+		}
+		point, err := ds.pointOf(ctx, buf, doc, texts[i], vector, iopt, i)
+		if err != nil {
+			if !iopt.ContinueOnError {
+				return err
+			}
+			ds.quarantine(ctx, doc, err)
+			continue
+		}
+		if migratedVector != nil {
+			mirror, err := ds.dualWrite.add(point, ds.vectorName(), migratedVector)
+			if err != nil {
+				return err
+			}
+			if mirror != nil {
+				mirrored = append(mirrored, mirror)
+			}
+		}
+		if err := ds.addNamed(point, named, i); err != nil {
+			if ds.vectorPolicy != VectorSkip {
+				return err
+			}
+			ds.quarantine(ctx, doc, err)
+			continue
+		}
+		if tokens != nil {
+			if err := ds.multiVector.add(point, tokens[i], i, ds.vectorPolicy); err != nil {
+				if ds.vectorPolicy != VectorSkip {
+					return err
+				}
+				ds.quarantine(ctx, doc, err)
+				continue
+			}
+		}
+		if sparse != nil {
+			ds.hybrid.add(point, sparse[i])
 		}
 		points = append(points, point)
 	}
+	if len(points) == 0 {
+		return nil
+	}
 
-	_, err = ds.client.Upsert(ctx, &qclient.UpsertPoints{
-		CollectionName: ds.collectionName,
-		Points:         points,
-	})
-
+	upsert := &qclient.UpsertPoints{
+		CollectionName:   ds.collectionName,
+		Points:           points,
+		ShardKeySelector: shardKey,
+	}
+	iopt.applyWriteOptions(upsert)
+	err = ds.upsert(ctx, upsert)
+	if dm := ds.dimensionError(err); dm != nil {
+		retry, ferr := ds.fixDimension(ctx, dm)
+		if ferr != nil {
+			return ferr
+		}
+		if !retry {
+			return dm
+		}
+		// Recreating the collection dropped the tenant shard keys.
+		if upsert.ShardKeySelector, err = ds.indexShardKey(ctx, iopt); err != nil {
+			return err
+		}
+		err = ds.upsert(ctx, upsert)
+	}
 	if err != nil {
 		return fmt.Errorf("qdrant index upsert failed: %v", err)
 	}
+	if len(mirrored) > 0 {
+		mirror := &qclient.UpsertPoints{
+			CollectionName: ds.dualWrite.Collection,
+			Points:         mirrored,
+		}
+		iopt.applyWriteOptions(mirror)
+		if err = ds.upsert(ctx, mirror); err != nil {
+			return fmt.Errorf("qdrant dual-write upsert failed: %v", err)
+		}
+	}
+	ds.negative.clear()
+	ds.recordUsage(iopt.Tenant, func(u *Usage) {
+		u.Upserts++
+		u.UpsertedPoints += int64(len(points))
+	})
 
 	return nil
 }
 
+// embedDocuments returns the vector of each document. With
+// ContinueOnError, documents that fail to embed are quarantined and
+// their vector is nil.
+func (ds *DocStore) embedDocuments(ctx context.Context, docs []*ai.Document, iopt *IndexerOptions) ([][]float32, error) {
+	return ds.embedWith(ctx, ds.embedder, ds.embedderOptions, docs, iopt)
+}
+
+// embedWith is embedDocuments with the given embedder.
+func (ds *DocStore) embedWith(ctx context.Context, embedder ai.Embedder, options any, docs []*ai.Document, iopt *IndexerOptions) ([][]float32, error) {
+	vals, err := embedder.Embed(ctx, &ai.EmbedRequest{
+		Documents: docs,
+		Options:   options,
+	})
+	if err == nil && len(vals.Embeddings) != len(docs) {
+		err = fmt.Errorf("embedder returned %d embeddings for %d documents", len(vals.Embeddings), len(docs))
+	}
+	if err == nil {
+		vectors := make([][]float32, len(docs))
+		for i, e := range vals.Embeddings {
+			vectors[i] = e.Embedding
+		}
+		return vectors, nil
+	}
+	if !iopt.ContinueOnError {
+		return nil, fmt.Errorf("qdrant index embedding failed: %v", err)
+	}
+
+	// Embed the documents one by one to isolate the failing ones.
+	vectors := make([][]float32, len(docs))
+	for i, doc := range docs {
+		vals, err := embedder.Embed(ctx, &ai.EmbedRequest{
+			Documents: []*ai.Document{doc},
+			Options:   options,
+		})
+		if err == nil && len(vals.Embeddings) != 1 {
+			err = fmt.Errorf("embedder returned %d embeddings for 1 document", len(vals.Embeddings))
+		}
+		if err != nil {
+			ds.quarantine(ctx, doc, fmt.Errorf("qdrant index embedding failed: %v", err))
+			continue
+		}
+		vectors[i] = vals.Embeddings[0].Embedding
+	}
+	return vectors, nil
+}
+
+// point builds the point stored for a document with the given text,
+// taking its messages from buf if it is not nil.
+func (ds *DocStore) point(ctx context.Context, buf *pointBuffers, doc *ai.Document, text string, vector []float32, iopt *IndexerOptions) (*qclient.PointStruct, error) {
+	id, err := ds.pointID(doc, iopt.Tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := buf.payloadScratch()
+	payload[ds.contentPayloadKey] = text
+	if ds.entityModel != nil {
+		entities, err := ds.extractEntities(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		payload[entitiesPayloadKey] = entities
+	}
+	if iopt.RunID != "" {
+		payload[runPayloadKey] = iopt.RunID
+	}
+	if ds.namespace != "" {
+		payload[namespacePayloadKey] = ds.namespace
+	}
+	if ds.environment != "" {
+		payload[envPayloadKey] = ds.environment
+	}
+	if ds.tenantKey != "" {
+		payload[ds.tenantKey] = iopt.Tenant
+	}
+	payload[indexedAtPayloadKey] = time.Now().UTC().Format(time.RFC3339Nano)
+	// Only the metadata is subject to the size limits of the policy.
+	c := payloadConverter{ds.payloadPolicy}
+	point := buf.point(id, ds.vectorName(), vector, len(payload)+1)
+	if err := c.unlimited().fill(point.Payload, payload); err != nil {
+		return nil, fmt.Errorf("qdrant: invalid document payload: %v", err)
+	}
+	metadata, err := c.value(doc.Metadata, 1)
+	if err != nil {
+		return nil, fmt.Errorf("qdrant: invalid document payload: %s: %v", ds.metadataPayloadKey, err)
+	}
+	point.Payload[ds.metadataPayloadKey] = metadata
+	return point, nil
+}
+
 // Retrieve implements the genkit Retriever.Retrieve method.
-func (ds *docStore) Retrieve(ctx context.Context, req *ai.RetrieverRequest) (*ai.RetrieverResponse, error) {
-	var (
-		filter *qclient.Filter
-		limit  int
-	)
-	if req.Options != nil {
-		ropt, ok := req.Options.(*RetrieverOptions)
-		if !ok {
-			return nil, fmt.Errorf("qdrant.Retrieve options have type %T, want %T", req.Options, &RetrieverOptions{})
+func (ds *DocStore) Retrieve(ctx context.Context, req *ai.RetrieverRequest) (resp *ai.RetrieverResponse, err error) {
+	defer recoverPanic(ctx, &err, "retrieve")
+	ropt, err := parseRetrieverOptions(req.Options)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := withTimeout(ctx, "Retrieve", ds.retrieveTimeout)
+	defer cancel()
+	resp, err = ds.retrieve(ctx, req, ropt)
+	return resp, timeoutError(ctx, err)
+}
+
+// retrieve runs a retrieve request with parsed options.
+func (ds *DocStore) retrieve(ctx context.Context, req *ai.RetrieverRequest, ropt *RetrieverOptions) (*ai.RetrieverResponse, error) {
+	// The query is condensed into a new document so that req is left
+	// unchanged.
+	qdoc, err := ds.condenseQuery(ctx, ropt.History, req.Document)
+	if err != nil {
+		return nil, err
+	}
+	// Searches by example need no query text.
+	if !ropt.byExample() && ds.guard.trivial(documentText(qdoc)) {
+		if ds.guard.policy == GuardError {
+			return nil, ErrTrivialQuery
 		}
-		filter = &ropt.Filter
-		limit = ropt.K
+		return &ai.RetrieverResponse{Documents: []*ai.Document{}}, nil
+	}
+
+	query, err := ds.queryPoints(ctx, ropt)
+	if err != nil {
+		return nil, err
+	}
+	// The examples of recommendations are not part of the cache key.
+	negative := ds.negative
+	if ropt.byExample() {
+		negative = nil
+	}
+	var negKey [sha256.Size]byte
+	if negative != nil {
+		if negKey, err = negativeKey(keyText(documentText(qdoc), ropt.VectorQueries), query); err != nil {
+			return nil, err
+		}
+		if negative.known(negKey, time.Now()) {
+			return nil, ErrInsufficientContext
+		}
+	}
+
+	var mmrK int
+	if ropt.MMR != nil {
+		if mmrK = ropt.K; mmrK == 0 {
+			mmrK = defaultQueryLimit
+		}
+		query.Limit = qclient.PtrOf(uint64(ropt.MMR.candidates(mmrK)))
+		query.WithVectors = withVector(ds.queryVector(ropt.Vector))
 	}
 
 	// Use the embedder to convert the document we want to
 	// retrieve into a vector.
+	start := time.Now()
+	qv, err := ds.embedQueryVectors(ctx, query, qdoc, ropt)
+	if err != nil {
+		return nil, err
+	}
+	embedded := time.Now()
+
+	var response []*qclient.ScoredPoint
+	var groups []string
+	switch {
+	case ropt.GroupBy != nil:
+		response, groups, err = ds.queryGroups(ctx, ds.searchQuery(query, qv), ropt.GroupBy)
+	case ropt.UseEntities && ds.entityModel != nil:
+		response, err = ds.queryWithEntities(ctx, ropt, query, qv, documentText(qdoc))
+	default:
+		response, err = ds.query(ctx, ds.searchQuery(query, qv))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ropt.MMR != nil {
+		response = diversify(response, ds.queryVector(ropt.Vector), ropt.MMR.Lambda, mmrK)
+	}
+	if len(ropt.Prefer) > 0 {
+		preferred, err := ds.queryPreferred(ctx, ropt.Prefer, query, qv)
+		if err != nil {
+			return nil, err
+		}
+		response = mergePoints(response, preferred, 0)
+	}
+	info := retrievalInfo{embed: embedded.Sub(start), query: time.Since(embedded), available: -1}
+	if ropt.CountAvailable {
+		if info.available, err = ds.countAvailable(ctx, query); err != nil {
+			return nil, err
+		}
+		reportAvailable(ctx, info.available)
+	}
+	ds.recordUsage(ropt.Tenant, func(u *Usage) {
+		u.Queries++
+		if len(ropt.Prefer) > 0 {
+			u.Queries++
+		}
+		if ropt.CountAvailable {
+			u.Queries++
+		}
+	})
+
+	results, err := ds.results(ctx, response)
+	if err != nil {
+		return nil, err
+	}
+	if groups != nil {
+		annotateGroups(results, groups)
+	}
+	if len(ropt.Prefer) > 0 {
+		boost := ropt.PreferBoost
+		if boost == 0 {
+			boost = defaultPreferBoost
+		}
+		results = applyPreference(results, ropt.Prefer, boost, ropt.K)
+	}
+	if ropt.FeedbackBoost != 0 {
+		if err := ds.applyFeedback(ctx, results, ropt.FeedbackBoost); err != nil {
+			return nil, err
+		}
+	}
+	if ropt.SessionBoost != 0 {
+		ds.applySession(ctx, results, ropt.SessionBoost)
+	}
+	if results, err = ds.safety.apply(ctx, results); err != nil {
+		return nil, err
+	}
+	annotateMatches(results, ropt.Filter.GetShould())
+	ds.rememberSession(ctx, results)
+	ds.captureRetrieval(ctx, qdoc, results)
+	threshold := ropt.ConfidenceThreshold
+	if threshold == 0 {
+		threshold = ropt.ScoreThreshold
+	}
+	info.confidence = confidence(results, threshold)
+
+	docs := make([]*ai.Document, 0, len(results))
+	for _, r := range results {
+		r.annotate(info)
+		docs = append(docs, r.doc)
+	}
+	if len(docs) == 0 {
+		negative.add(negKey, time.Now())
+	}
+	ds.recordUsage(ropt.Tenant, func(u *Usage) {
+		u.RetrievedDocuments += int64(len(docs))
+	})
+	ret := &ai.RetrieverResponse{
+		Documents: docs,
+	}
+	return ret, nil
+}
+
+// embedQueryVectors embeds the query document, setting the dense query of
+// query and returning the other query vectors. With
+// RetrieverOptions.Recommend or Discover, it sets the recommend or
+// discovery query instead.
+func (ds *DocStore) embedQueryVectors(ctx context.Context, query *qclient.QueryPoints, qdoc *ai.Document, ropt *RetrieverOptions) (queryVectors, error) {
+	var qv queryVectors
+	if ropt.Recommend != nil {
+		return qv, ds.recommendQuery(ctx, query, qdoc, ropt)
+	}
+	if ropt.Discover != nil {
+		return qv, ds.discoverQuery(ctx, query, qdoc, ropt)
+	}
+	var err error
+	if !ds.multiVector.primary() {
+		if len(ropt.VectorQueries) > 0 {
+			if qv.fields, err = ds.fieldQueries(ctx, ropt); err != nil {
+				return qv, err
+			}
+		} else if ropt.Search != SearchSparse {
+			if query.Query, err = ds.embedQuery(ctx, qdoc, ropt); err != nil {
+				return qv, err
+			}
+		}
+		if qv.sparse, err = ds.querySparse(ctx, qdoc, ropt.Search); err != nil {
+			return qv, err
+		}
+	}
+	qv.tokens, err = ds.multiVector.embedQuery(ctx, qdoc)
+	return qv, err
+}
+
+// embedQuery returns the dense query for a query document.
+func (ds *DocStore) embedQuery(ctx context.Context, doc *ai.Document, ropt *RetrieverOptions) (*qclient.Query, error) {
+	return ds.embedVectorQuery(ctx, doc, ropt.Vector, ropt.Tenant)
+}
+
+// embedVectorQuery returns the query for a logical vector.
+func (ds *DocStore) embedVectorQuery(ctx context.Context, doc *ai.Document, logical, tenant string) (*qclient.Query, error) {
+	embedder, options, err := ds.queryEmbedder(logical)
+	if err != nil {
+		return nil, err
+	}
+	ds.recordEmbedding(tenant, []*ai.Document{doc})
 	ereq := &ai.EmbedRequest{
-		Documents: []*ai.Document{req.Document},
-		Options:   ds.embedderOptions,
+		Documents: []*ai.Document{doc},
+		Options:   options,
 	}
-	vectors, err := ds.embedder.Embed(ctx, ereq)
+	vectors, err := embedder.Embed(ctx, ereq)
 	if err != nil {
 		return nil, fmt.Errorf("qdrant retrieve embedding failed: %v", err)
 	}
+	if len(vectors.Embeddings) != 1 {
+		return nil, fmt.Errorf("qdrant retrieve embedding failed: embedder returned %d embeddings for 1 document", len(vectors.Embeddings))
+	}
+	vector, err := checkVector(vectors.Embeddings[0].Embedding, -1, ds.vectorPolicy)
+	if err != nil {
+		return nil, err
+	}
+	return qclient.NewQuery(vector...), nil
+}
 
-	response, err := ds.client.Query(context.TODO(), &qclient.QueryPoints{
+// queryPoints builds the Qdrant query for the retriever options,
+// without the query vector.
+func (ds *DocStore) queryPoints(ctx context.Context, ropt *RetrieverOptions) (*qclient.QueryPoints, error) {
+	filter, err := ds.retrieverFilter(ropt)
+	if err != nil {
+		return nil, err
+	}
+	if filter, err = ds.tenantFilter(filter, ropt.Tenant); err != nil {
+		return nil, err
+	}
+	if ropt.SessionExclude {
+		if seen := ds.sessionFilter(ctx); seen != nil {
+			filter = &qclient.Filter{
+				Must:    []*qclient.Condition{qclient.NewFilterAsCondition(filter)},
+				MustNot: []*qclient.Condition{seen},
+			}
+		}
+	}
+	query := &qclient.QueryPoints{
 		CollectionName: ds.collectionName,
-		Query:          qclient.NewQuery(vectors.Embeddings[0].Embedding...),
-		Limit:          qclient.PtrOf(uint64(limit)),
+		Limit:          qclient.PtrOf(uint64(ropt.K)),
 		Filter:         filter,
 		WithPayload:    qclient.NewWithPayloadInclude(ds.contentPayloadKey, ds.metadataPayloadKey),
-	})
+	}
+	if ropt.Offset > 0 {
+		query.Offset = qclient.PtrOf(uint64(ropt.Offset))
+	}
+	if name := ds.queryVector(ropt.Vector); name != "" {
+		query.Using = &name
+	}
+	if ropt.ScoreThreshold != 0 {
+		query.ScoreThreshold = qclient.PtrOf(ropt.ScoreThreshold)
+	}
+	query.Params = ropt.searchParams()
+	if query.ReadConsistency, err = readConsistency(ropt.Consistency); err != nil {
+		return nil, err
+	}
+	shards, err := ds.shardKeySelector(ctx, ropt.shardSelector())
 	if err != nil {
 		return nil, err
 	}
-
-	var docs []*ai.Document
-	for _, result := range response {
-		content := result.Payload[ds.contentPayloadKey].GetStringValue()
-		if content == "" {
-			return nil, errors.New("qdrant retrieve failed to fetch original document text")
+	if ropt.Tenant != "" && (ds.shardPerTenant || ds.tenantKey == "") {
+		if shards != nil {
+			return nil, errors.New("qdrant: RetrieverOptions.Tenant and Shards cannot be combined")
+		}
+		if shards, err = ds.tenantShardKey(ropt.Tenant); err != nil {
+			return nil, err
 		}
+	}
+	query.ShardKeySelector = shards
+	return query, nil
+}
+
+// result is a retrieved point with its reconstructed document.
+type result struct {
+	id      string
+	score   float32
+	doc     *ai.Document
+	payload map[string]*qclient.Value
+}
 
-		metadata := make(map[string]any)
-		for k, v := range result.Payload[ds.metadataPayloadKey].GetStructValue().Fields {
-			metadata[k] = v
+// results converts the points returned by a query.
+func (ds *DocStore) results(ctx context.Context, points []*qclient.ScoredPoint) ([]*result, error) {
+	results := make([]*result, 0, len(points))
+	for _, p := range points {
+		r, err := ds.resultOf(ctx, p)
+		if err != nil {
+			return nil, err
 		}
+		results = append(results, r)
+	}
+	return results, nil
+}
 
-		d := ai.DocumentFromText(content, metadata)
-		docs = append(docs, d)
+// documentFromPayload reconstructs the original document from a point payload.
+func (ds *DocStore) documentFromPayload(payload map[string]*qclient.Value) (*ai.Document, error) {
+	content := payload[ds.contentPayloadKey].GetStringValue()
+	if content == "" {
+		return nil, errors.New("qdrant retrieve failed to fetch original document text")
 	}
 
-	ret := &ai.RetrieverResponse{
-		Documents: docs,
+	metadata := make(map[string]any)
+	for k, v := range payload[ds.metadataPayloadKey].GetStructValue().Fields {
+		metadata[k] = v
 	}
-	return ret, nil
+	return ai.DocumentFromText(content, metadata), nil
 }
 
 // Generates a deterministic UUID and returns the string representation.
 // Qdrant only allows UUIDs and positive integers as point IDs.
 func generatePointId(doc *ai.Document) (string, error) {
-	b, err := json.Marshal(doc)
-	if err != nil {
-		return "", fmt.Errorf("qdrant: error marshaling document: %v", err)
+	return documentID(doc, nil)
+}
+
+// pointIDOf parses the string representation of a point ID returned by
+// pointIDString: a decimal number or a UUID.
+func pointIDOf(id string) *qclient.PointId {
+	if n, err := strconv.ParseUint(id, 10, 64); err == nil {
+		return qclient.NewIDNum(n)
+	}
+	return qclient.NewID(id)
+}
+
+// pointIDString returns the string representation of a point ID.
+func pointIDString(id *qclient.PointId) string {
+	if n, ok := id.GetPointIdOptions().(*qclient.PointId_Num); ok {
+		return strconv.FormatUint(n.Num, 10)
 	}
-	uuid := uuid.NewSHA1(uuid.NameSpaceDNS, b)
-	return uuid.String(), nil
+	return id.GetUuid()
 }
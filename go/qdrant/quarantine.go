@@ -0,0 +1,66 @@
+package qdrant
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// QuarantineSink receives documents that were skipped while indexing
+// with [IndexerOptions.ContinueOnError], for later inspection and retry.
+type QuarantineSink interface {
+	Quarantine(ctx context.Context, doc *ai.Document, reason error) error
+}
+
+// QuarantineFunc adapts a function to a [QuarantineSink].
+type QuarantineFunc func(ctx context.Context, doc *ai.Document, reason error) error
+
+// Quarantine implements [QuarantineSink].
+func (f QuarantineFunc) Quarantine(ctx context.Context, doc *ai.Document, reason error) error {
+	return f(ctx, doc, reason)
+}
+
+// QuarantineRecord is a line written by [JSONLQuarantine].
+type QuarantineRecord struct {
+	Time     time.Time    `json:"time"`
+	Document *ai.Document `json:"document"`
+	Reason   string       `json:"reason"`
+}
+
+// JSONLQuarantine is a [QuarantineSink] that appends skipped documents
+// to a JSONL file.
+type JSONLQuarantine struct {
+	w *jsonlWriter
+}
+
+// NewJSONLQuarantine opens path for appending skipped documents.
+func NewJSONLQuarantine(path string) (*JSONLQuarantine, error) {
+	w, err := openJSONL(path)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONLQuarantine{w: w}, nil
+}
+
+// Quarantine implements [QuarantineSink].
+func (q *JSONLQuarantine) Quarantine(_ context.Context, doc *ai.Document, reason error) error {
+	return q.w.write(&QuarantineRecord{Time: time.Now(), Document: doc, Reason: reason.Error()})
+}
+
+// Close closes the underlying file.
+func (q *JSONLQuarantine) Close() error {
+	return q.w.close()
+}
+
+// quarantine reports a skipped document to the configured sink.
+func (ds *DocStore) quarantine(ctx context.Context, doc *ai.Document, reason error) {
+	if ds.quarantineSink == nil {
+		slog.Warn("qdrant skipped document", "collection", ds.collectionName, "err", reason)
+		return
+	}
+	if err := ds.quarantineSink.Quarantine(ctx, doc, reason); err != nil {
+		slog.Warn("qdrant quarantine failed", "collection", ds.collectionName, "reason", reason, "err", err)
+	}
+}
@@ -0,0 +1,58 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// failingEmbedder embeds documents as their text length and fails on
+// documents whose text is "bad".
+type failingEmbedder struct{}
+
+func (failingEmbedder) Name() string { return "test/failing" }
+
+func (failingEmbedder) Embed(_ context.Context, req *ai.EmbedRequest) (*ai.EmbedResponse, error) {
+	resp := &ai.EmbedResponse{}
+	for _, d := range req.Documents {
+		if documentText(d) == "bad" {
+			return nil, errors.New("cannot embed")
+		}
+		resp.Embeddings = append(resp.Embeddings, &ai.DocumentEmbedding{Embedding: []float32{float32(len(documentText(d)))}})
+	}
+	return resp, nil
+}
+
+func TestEmbedDocumentsQuarantine(t *testing.T) {
+	var quarantined []string
+	ds := &DocStore{
+		embedder: failingEmbedder{},
+		quarantineSink: QuarantineFunc(func(_ context.Context, doc *ai.Document, _ error) error {
+			quarantined = append(quarantined, documentText(doc))
+			return nil
+		}),
+	}
+	docs := []*ai.Document{
+		ai.DocumentFromText("ok", nil),
+		ai.DocumentFromText("bad", nil),
+		ai.DocumentFromText("fine", nil),
+	}
+	ctx := context.Background()
+
+	if _, err := ds.embedDocuments(ctx, docs, &IndexerOptions{}); err == nil {
+		t.Fatal("expected an error without ContinueOnError")
+	}
+
+	vectors, err := ds.embedDocuments(ctx, docs, &IndexerOptions{ContinueOnError: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vectors[0][0] != 2 || vectors[1] != nil || vectors[2][0] != 4 {
+		t.Errorf("got vectors %v", vectors)
+	}
+	if len(quarantined) != 1 || quarantined[0] != "bad" {
+		t.Errorf("got quarantined %v, want [bad]", quarantined)
+	}
+}
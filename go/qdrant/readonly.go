@@ -0,0 +1,19 @@
+package qdrant
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrReadOnly is wrapped by the errors of the write operations of a store
+// with Config.ReadOnly.
+var ErrReadOnly = errors.New("qdrant: store is read-only")
+
+// writable returns an error wrapping [ErrReadOnly] for the write operation
+// op if the store is read-only.
+func (ds *DocStore) writable(op string) error {
+	if ds.readOnly {
+		return fmt.Errorf("%w: %s rejected", ErrReadOnly, op)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestReadOnlyRejectsWrites(t *testing.T) {
+	// The store has no client: writes must fail before reaching Qdrant.
+	ds := &DocStore{readOnly: true, shardPerTenant: true, knownShards: map[string]bool{}}
+	ctx := context.Background()
+	docs := []*ai.Document{ai.DocumentFromText("a", nil)}
+	for name, err := range map[string]error{
+		"Index":          ds.Index(ctx, &ai.IndexerRequest{Documents: docs}),
+		"RollbackRun":    ds.RollbackRun(ctx, "run"),
+		"DeleteSource":   ds.DeleteSource(ctx, "source"),
+		"UpdateMetadata": ds.UpdateMetadata(ctx, "1", map[string]any{"k": "v"}),
+		"DeleteTenant":   ds.DeleteTenant(ctx, "acme"),
+	} {
+		if !errors.Is(err, ErrReadOnly) {
+			t.Errorf("%s: got %v, want ErrReadOnly", name, err)
+		}
+	}
+	if _, err := ds.Backfill(ctx, "v", nil, 0); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Backfill: got %v, want ErrReadOnly", err)
+	}
+}
+
+func TestLookupMissing(t *testing.T) {
+	if Indexer("no-such-collection") != nil {
+		t.Error("Indexer of a missing collection is not nil")
+	}
+	if Retriever("no-such-collection") != nil {
+		t.Error("Retriever of a missing collection is not nil")
+	}
+}
@@ -0,0 +1,120 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/google/uuid"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// Recommend retrieves documents like the positive examples and unlike the
+// negative ones, for "more like these, less like those" flows such as
+// feeds. The text of the query document, if any, is embedded and used as
+// an additional positive example.
+//
+// The examples are compared with the dense vector queried, see
+// RetrieverOptions.Vector; sparse and multivector searches are skipped.
+type Recommend struct {
+	Positive []RecommendExample `json:"positive,omitempty"`
+	Negative []RecommendExample `json:"negative,omitempty"`
+	// Strategy is how the examples are combined: "average" (the
+	// default) searches around the average of the positive examples minus
+	// the negative ones, while "bestScore" scores each point against
+	// every example, which handles varied examples and negative examples
+	// alone better, at a higher cost.
+	Strategy string `json:"strategy,omitempty"`
+}
+
+// RecommendExample is an example of [Recommend] and [Discover]: a stored
+// point, e.g. the [PointIDKey] of a retrieved document, or a raw vector.
+type RecommendExample struct {
+	ID     string    `json:"id,omitempty"`
+	Vector []float32 `json:"vector,omitempty"`
+}
+
+// recommendStrategies maps the names of Recommend.Strategy to Qdrant
+// strategies.
+var recommendStrategies = map[string]qclient.RecommendStrategy{
+	"average":   qclient.RecommendStrategy_AverageVector,
+	"bestScore": qclient.RecommendStrategy_BestScore,
+}
+
+func (r *Recommend) validate() error {
+	if len(r.Positive)+len(r.Negative) == 0 {
+		return errors.New("recommend: no examples")
+	}
+	if _, ok := recommendStrategies[r.Strategy]; r.Strategy != "" && !ok {
+		return fmt.Errorf("recommend: unknown strategy %q", r.Strategy)
+	}
+	for _, e := range slices.Concat(r.Positive, r.Negative) {
+		if _, err := e.input(); err != nil {
+			return fmt.Errorf("recommend: %v", err)
+		}
+	}
+	return nil
+}
+
+// input returns the Qdrant input of an example.
+func (e RecommendExample) input() (*qclient.VectorInput, error) {
+	switch {
+	case e.ID != "" && e.Vector != nil:
+		return nil, errors.New("an example has both an id and a vector")
+	case e.Vector != nil:
+		v, err := checkVector(e.Vector, -1, VectorError)
+		if err != nil {
+			return nil, err
+		}
+		return qclient.NewVectorInputDense(v), nil
+	case e.ID == "":
+		return nil, errors.New("an example has neither an id nor a vector")
+	}
+	if n, err := strconv.ParseUint(e.ID, 10, 64); err == nil {
+		return qclient.NewVectorInputID(qclient.NewIDNum(n)), nil
+	}
+	if _, err := uuid.Parse(e.ID); err != nil {
+		return nil, fmt.Errorf("invalid point id %q", e.ID)
+	}
+	return qclient.NewVectorInputID(qclient.NewID(e.ID)), nil
+}
+
+// recommendQuery sets the recommend query of RetrieverOptions.Recommend on
+// query, embedding the text of the query document as a positive example.
+func (ds *DocStore) recommendQuery(ctx context.Context, query *qclient.QueryPoints, qdoc *ai.Document, ropt *RetrieverOptions) error {
+	if ds.multiVector.primary() {
+		return errors.New("qdrant: RetrieverOptions.Recommend requires a dense vector")
+	}
+	r := ropt.Recommend
+	input := &qclient.RecommendInput{}
+	if r.Strategy != "" {
+		input.Strategy = recommendStrategies[r.Strategy].Enum()
+	}
+	for _, e := range r.Positive {
+		v, err := e.input()
+		if err != nil {
+			return err
+		}
+		input.Positive = append(input.Positive, v)
+	}
+	for _, e := range r.Negative {
+		v, err := e.input()
+		if err != nil {
+			return err
+		}
+		input.Negative = append(input.Negative, v)
+	}
+	if strings.TrimSpace(documentText(qdoc)) != "" {
+		q, err := ds.embedQuery(ctx, qdoc, ropt)
+		if err != nil {
+			return err
+		}
+		input.Positive = append(input.Positive, q.GetNearest())
+	}
+	query.Query = qclient.NewQueryRecommend(input)
+	return nil
+}
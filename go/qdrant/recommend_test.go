@@ -0,0 +1,54 @@
+package qdrant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestRecommendOptions(t *testing.T) {
+	ropt, err := parseRetrieverOptions(map[string]any{"recommend": map[string]any{
+		"positive": []any{map[string]any{"id": "5c56c793-69f3-4fbf-87e6-c4bf54c28c26"}, map[string]any{"id": "42"}},
+		"negative": []any{map[string]any{"vector": []any{0.5, 1}}},
+		"strategy": "bestScore",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ropt.Recommend.Positive) != 2 || len(ropt.Recommend.Negative) != 1 {
+		t.Errorf("got %+v", ropt.Recommend)
+	}
+	for _, bad := range []map[string]any{
+		{"recommend": map[string]any{}},
+		{"recommend": map[string]any{"positive": []any{map[string]any{}}}},
+		{"recommend": map[string]any{"positive": []any{map[string]any{"id": "not-an-id"}}}},
+		{"recommend": map[string]any{"positive": []any{map[string]any{"id": "1", "vector": []any{1}}}}},
+		{"recommend": map[string]any{"positive": []any{map[string]any{"id": "1"}}, "strategy": "median"}},
+		{"recommend": map[string]any{"positive": []any{map[string]any{"id": "1"}}}, "search": "sparse"},
+	} {
+		if _, err := parseRetrieverOptions(bad); err == nil {
+			t.Errorf("%v: expected an error", bad)
+		}
+	}
+}
+
+func TestRecommendQuery(t *testing.T) {
+	ds := &DocStore{}
+	ropt := &RetrieverOptions{Recommend: &Recommend{
+		Positive: []RecommendExample{{ID: "7"}},
+		Negative: []RecommendExample{{Vector: []float32{1, 0}}},
+		Strategy: "bestScore",
+	}}
+	query := &qclient.QueryPoints{}
+	// Without query text, nothing is embedded.
+	if err := ds.recommendQuery(context.Background(), query, &ai.Document{}, ropt); err != nil {
+		t.Fatal(err)
+	}
+	r := query.GetQuery().GetRecommend()
+	if r.GetPositive()[0].GetId().GetNum() != 7 || len(r.GetNegative()[0].GetDense().GetData()) != 2 ||
+		r.GetStrategy() != qclient.RecommendStrategy_BestScore {
+		t.Errorf("got %v", r)
+	}
+}
@@ -0,0 +1,44 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// recoverPanic, deferred, turns a panic of the calling function into an
+// error stored in *errp, so that a bad document or payload fails its
+// request instead of crashing the Genkit server. The error names what was
+// being processed, as described by format and args; the stack is logged.
+func recoverPanic(ctx context.Context, errp *error, format string, args ...any) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	what := fmt.Sprintf(format, args...)
+	slog.ErrorContext(ctx, "qdrant recovered from a panic", "during", what, "panic", r, "stack", string(debug.Stack()))
+	*errp = fmt.Errorf("qdrant %s panicked: %v", what, r)
+}
+
+// pointOf converts document i of a batch into a point, recovering from
+// panics of the conversion so that the document can be quarantined.
+func (ds *DocStore) pointOf(ctx context.Context, buf *pointBuffers, doc *ai.Document, text string, vector []float32, iopt *IndexerOptions, i int) (point *qclient.PointStruct, err error) {
+	defer recoverPanic(ctx, &err, "conversion of document %d", i)
+	return ds.point(ctx, buf, doc, text, vector, iopt)
+}
+
+// resultOf converts a retrieved point into a result, recovering from
+// panics of the conversion.
+func (ds *DocStore) resultOf(ctx context.Context, p *qclient.ScoredPoint) (r *result, err error) {
+	defer recoverPanic(ctx, &err, "conversion of point %s", pointIDString(p.GetId()))
+	d, err := ds.documentFromPayload(p.Payload)
+	if err != nil {
+		return nil, err
+	}
+	projectParts(d, ds.parts)
+	return &result{id: pointIDString(p.Id), score: p.Score, doc: d, payload: p.Payload}, nil
+}
@@ -0,0 +1,34 @@
+package qdrant
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRecoverPanic(t *testing.T) {
+	ctx := context.Background()
+	run := func(fail bool) (err error) {
+		defer recoverPanic(ctx, &err, "conversion of document %d", 3)
+		if fail {
+			var m map[string]int
+			m["x"] = 1
+		}
+		return nil
+	}
+	if err := run(false); err != nil {
+		t.Errorf("got %v without a panic", err)
+	}
+	err := run(true)
+	if err == nil || !strings.HasPrefix(err.Error(), "qdrant conversion of document 3 panicked: ") {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestPointOfRecovers(t *testing.T) {
+	ds := &DocStore{contentPayloadKey: "content", metadataPayloadKey: "metadata"}
+	_, err := ds.pointOf(context.Background(), nil, nil, "", []float32{1}, &IndexerOptions{}, 2)
+	if err == nil || !strings.Contains(err.Error(), "document 2 panicked") {
+		t.Errorf("got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package qdrant
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// Metadata keys attached by the retriever to every returned document.
+const (
+	// ScoreKey holds the similarity score of the document.
+	ScoreKey = "_score"
+	// PointIDKey holds the ID of the Qdrant point.
+	PointIDKey = "_id"
+	// EmbedTimeKey and QueryTimeKey hold the time in milliseconds spent
+	// embedding the query and querying Qdrant.
+	EmbedTimeKey = "_embed_ms"
+	QueryTimeKey = "_query_ms"
+	// AvailableKey holds the number of points matching the filter of the
+	// request. It is only set with RetrieverOptions.CountAvailable. As it
+	// is attached to the documents, responses without documents do not
+	// carry it; see [WithAvailable].
+	AvailableKey = "_available"
+)
+
+// Result is a retrieved document with the details attached by the
+// retriever.
+type Result struct {
+	Document  *ai.Document
+	ID        string
+	Score     float32
+	EmbedTime time.Duration
+	QueryTime time.Duration
+	// Available is the number of points matching the filter of the
+	// request, or -1 if it was not counted.
+	Available int64
+	// MatchedConditions lists the should conditions of the filter the
+	// document matches. See [MatchedConditionsKey].
+	MatchedConditions []int
+	// Group is the group of the document with RetrieverOptions.GroupBy.
+	// See [GroupKey].
+	Group string
+}
+
+// retrievalInfo holds the details of a retrieval shared by all results.
+type retrievalInfo struct {
+	embed, query time.Duration
+	available    int64 // -1 if not counted
+	confidence   Confidence
+}
+
+// annotate attaches the result details to the document metadata.
+func (r *result) annotate(t retrievalInfo) {
+	if r.doc.Metadata == nil {
+		r.doc.Metadata = make(map[string]any)
+	}
+	r.doc.Metadata[ScoreKey] = r.score
+	r.doc.Metadata[PointIDKey] = r.id
+	r.doc.Metadata[EmbedTimeKey] = milliseconds(t.embed)
+	r.doc.Metadata[QueryTimeKey] = milliseconds(t.query)
+	if t.available >= 0 {
+		r.doc.Metadata[AvailableKey] = t.available
+	}
+	r.doc.Metadata[TopScoreKey] = t.confidence.TopScore
+	r.doc.Metadata[ScoreGapKey] = t.confidence.ScoreGap
+	r.doc.Metadata[AboveThresholdKey] = t.confidence.AboveThreshold
+}
+
+type availableKey struct{}
+
+// WithAvailable returns a context in which retrievals with
+// RetrieverOptions.CountAvailable add the number of points matching their
+// filter to n, including when no document matches and the count cannot
+// be attached to the response. Retrievals without the option leave n
+// unchanged. The retrievals of a [HashRouter], which run concurrently,
+// add up to the count of the whole corpus.
+func WithAvailable(ctx context.Context, n *atomic.Int64) context.Context {
+	return context.WithValue(ctx, availableKey{}, n)
+}
+
+// reportAvailable adds n to the count of a context from WithAvailable.
+func reportAvailable(ctx context.Context, n int64) {
+	if p, _ := ctx.Value(availableKey{}).(*atomic.Int64); p != nil {
+		p.Add(n)
+	}
+}
+
+// countAvailable returns the exact number of points query can match.
+func (ds *DocStore) countAvailable(ctx context.Context, query *qclient.QueryPoints) (int64, error) {
+	n, err := ds.client.Count(ctx, &qclient.CountPoints{
+		CollectionName:   ds.collectionName,
+		Filter:           query.Filter,
+		Exact:            qclient.PtrOf(true),
+		ShardKeySelector: query.ShardKeySelector,
+		ReadConsistency:  query.ReadConsistency,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("qdrant count failed: %v", err)
+	}
+	return int64(n), nil
+}
+
+// ResultsFromResponse decodes the details the retriever attaches to each
+// document of resp. It accepts responses that went through JSON, as when
+// the retriever runs behind the Genkit reflection server.
+func ResultsFromResponse(resp *ai.RetrieverResponse) []Result {
+	if resp == nil {
+		return nil
+	}
+	results := make([]Result, 0, len(resp.Documents))
+	for _, d := range resp.Documents {
+		id, _ := d.Metadata[PointIDKey].(string)
+		group, _ := d.Metadata[GroupKey].(string)
+		available := int64(-1)
+		if _, ok := d.Metadata[AvailableKey]; ok {
+			available = int64(metadataNumber(d.Metadata, AvailableKey))
+		}
+		results = append(results, Result{
+			Document:          d,
+			ID:                id,
+			Score:             float32(metadataNumber(d.Metadata, ScoreKey)),
+			EmbedTime:         time.Duration(metadataNumber(d.Metadata, EmbedTimeKey) * float64(time.Millisecond)),
+			QueryTime:         time.Duration(metadataNumber(d.Metadata, QueryTimeKey) * float64(time.Millisecond)),
+			Available:         available,
+			MatchedConditions: metadataInts(d.Metadata, MatchedConditionsKey),
+			Group:             group,
+		})
+	}
+	return results
+}
+
+// metadataNumber returns the number stored under key, or 0.
+func metadataNumber(metadata map[string]any, key string) float64 {
+	return number(metadata[key])
+}
+
+// number returns the value of a number of metadata, or 0.
+func number(v any) float64 {
+	switch v := v.(type) {
+	case float32:
+		return float64(v)
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	default:
+		return 0
+	}
+}
+
+// metadataInts returns the list of numbers stored under key, or nil.
+func metadataInts(metadata map[string]any, key string) []int {
+	switch v := metadata[key].(type) {
+	case []int:
+		return v
+	case []any:
+		ints := make([]int, len(v))
+		for i, n := range v {
+			ints[i] = int(number(n))
+		}
+		return ints
+	default:
+		return nil
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,133 @@
+package qdrant
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestResultsFromResponse(t *testing.T) {
+	r := &result{id: "abc", score: 0.75, doc: ai.DocumentFromText("hello", nil)}
+	r.annotate(retrievalInfo{embed: 2 * time.Millisecond, query: 3 * time.Millisecond, available: 7})
+	resp := &ai.RetrieverResponse{Documents: []*ai.Document{r.doc}}
+
+	// Decoding must work both in process and after a JSON round trip.
+	b, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded ai.RetrieverResponse
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, resp := range []*ai.RetrieverResponse{resp, &decoded} {
+		got := ResultsFromResponse(resp)
+		if len(got) != 1 {
+			t.Fatalf("got %d results, want 1", len(got))
+		}
+		if got[0].ID != "abc" || got[0].Score != 0.75 || got[0].EmbedTime != 2*time.Millisecond || got[0].QueryTime != 3*time.Millisecond || got[0].Available != 7 {
+			t.Errorf("got %+v", got[0])
+		}
+	}
+}
+
+func TestResultsEmpty(t *testing.T) {
+	ds := &DocStore{contentPayloadKey: contentPayloadKey, metadataPayloadKey: metadataPayloadKey}
+	results, err := ds.results(context.Background(), nil)
+	if err != nil || results == nil || len(results) != 0 {
+		t.Errorf("got %v, %v; want an empty, non-nil list", results, err)
+	}
+	results = applyPreference(results, map[string]any{"lang": "en"}, 0.1, 10)
+	if len(results) != 0 {
+		t.Errorf("got %d results after preferences, want 0", len(results))
+	}
+	if got := ResultsFromResponse(&ai.RetrieverResponse{}); len(got) != 0 {
+		t.Errorf("got %d results from an empty response", len(got))
+	}
+}
+
+func TestWithAvailable(t *testing.T) {
+	reportAvailable(context.Background(), 3) // no receiver: no-op
+	var n atomic.Int64
+	ctx := WithAvailable(context.Background(), &n)
+	reportAvailable(ctx, 2)
+	reportAvailable(ctx, 3)
+	if got := n.Load(); got != 5 {
+		t.Errorf("got %d, want the sum of the counts", got)
+	}
+}
+
+// fakeClient returns a client whose calls are answered by handle instead
+// of a server.
+func fakeClient(t *testing.T, handle func(method string, req any) (proto.Message, error)) *qclient.Client {
+	t.Helper()
+	intercept := func(_ context.Context, method string, req, reply any, _ *grpc.ClientConn, _ grpc.UnaryInvoker, _ ...grpc.CallOption) error {
+		resp, err := handle(method, req)
+		if err != nil {
+			return err
+		}
+		proto.Merge(reply.(proto.Message), resp)
+		return nil
+	}
+	client, err := qclient.NewClient(&qclient.Config{Host: "qdrant.invalid", GrpcOptions: []grpc.DialOption{grpc.WithUnaryInterceptor(intercept)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// countingStore returns a store whose queries match nothing and whose
+// counts return n.
+func countingStore(t *testing.T, name string, n uint64) *DocStore {
+	client := fakeClient(t, func(method string, _ any) (proto.Message, error) {
+		switch method {
+		case "/qdrant.Points/Query":
+			return &qclient.QueryResponse{}, nil
+		case "/qdrant.Points/Count":
+			return &qclient.CountResponse{Result: &qclient.CountResult{Count: n}}, nil
+		}
+		return nil, status.Errorf(codes.Unimplemented, "%s", method)
+	})
+	return &DocStore{
+		collectionName:     name,
+		client:             client,
+		embedder:           failingEmbedder{},
+		contentPayloadKey:  contentPayloadKey,
+		metadataPayloadKey: metadataPayloadKey,
+		schema:             newSchemaCache(0),
+	}
+}
+
+func TestRetrieveAvailable(t *testing.T) {
+	var n atomic.Int64
+	ctx := WithAvailable(context.Background(), &n)
+	req := &ai.RetrieverRequest{Document: ai.DocumentFromText("question", nil), Options: &RetrieverOptions{CountAvailable: true}}
+	resp, err := countingStore(t, "docs", 3).Retrieve(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Documents) != 0 || n.Load() != 3 {
+		t.Errorf("got %d documents and %d available, want none and 3", len(resp.Documents), n.Load())
+	}
+
+	// The partitions of a hash router add up.
+	n.Store(0)
+	r := buildHashRouter([]*DocStore{countingStore(t, "a", 3), countingStore(t, "b", 4)}, 8)
+	if _, err := r.Retrieve(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if got := n.Load(); got != 7 {
+		t.Errorf("got %d available, want 7", got)
+	}
+}
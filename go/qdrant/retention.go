@@ -0,0 +1,102 @@
+package qdrant
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// indexedAtPayloadKey is the payload field holding the time a point was
+// last indexed.
+const indexedAtPayloadKey = "_indexed_at"
+
+// RetentionRule sets how long the documents of a class are kept.
+type RetentionRule struct {
+	// Match selects the documents of the rule by metadata equality, as
+	// RetrieverOptions.Where does, e.g. {"type": "chat_memory"}. An empty
+	// Match selects all documents.
+	Match map[string]any
+	// MaxAge is the age after indexing at which documents are deleted.
+	// Zero keeps them forever.
+	MaxAge time.Duration
+}
+
+// Purger deletes expired documents according to retention rules. Each
+// document follows the first rule it matches; documents matching no rule
+// are kept, and so are documents indexed before indexing times were
+// recorded. On collections requiring indexes for filters, the rules need
+// payload indexes on their Match keys and a datetime index on
+// "_indexed_at".
+type Purger struct {
+	Store *DocStore
+	Rules []RetentionRule
+	// Interval is the time between purges in Run. Defaults to 1h.
+	Interval time.Duration
+}
+
+// Run purges every Interval until ctx is done, and then returns
+// ctx.Err(). Failed purges are logged and retried at the next interval.
+func (p *Purger) Run(ctx context.Context) error {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := p.Purge(ctx); err != nil {
+			slog.WarnContext(ctx, "qdrant purge failed", "collection", p.Store.collectionName, "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge deletes the documents expired now.
+func (p *Purger) Purge(ctx context.Context) error {
+	filters, err := p.Store.retentionFilters(p.Rules, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, f := range filters {
+		if err := p.Store.deleteByFilter(ctx, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retentionFilters returns the filters of the documents expired at now,
+// one per rule with a MaxAge. Each filter excludes the documents of the
+// previous rules.
+func (ds *DocStore) retentionFilters(rules []RetentionRule, now time.Time) ([]*qclient.Filter, error) {
+	var filters []*qclient.Filter
+	var previous []*qclient.Condition
+	for _, r := range rules {
+		match, err := ds.equalityFilter(r.Match)
+		if err != nil {
+			return nil, err
+		}
+		if r.MaxAge > 0 {
+			must := []*qclient.Condition{qclient.NewDatetimeRange(indexedAtPayloadKey, &qclient.DatetimeRange{
+				Lt: timestamppb.New(now.Add(-r.MaxAge)),
+			})}
+			if match != nil {
+				must = append(must, qclient.NewFilterAsCondition(match))
+			}
+			filters = append(filters, &qclient.Filter{Must: must, MustNot: previous})
+		}
+		if match == nil {
+			// Later rules cannot match any document.
+			break
+		}
+		previous = append(previous[:len(previous):len(previous)], qclient.NewFilterAsCondition(match))
+	}
+	return filters, nil
+}
@@ -0,0 +1,41 @@
+package qdrant
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetentionFilters(t *testing.T) {
+	ds := &DocStore{metadataPayloadKey: "_metadata"}
+	now := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	filters, err := ds.retentionFilters([]RetentionRule{
+		{Match: map[string]any{"type": "chat_memory"}, MaxAge: 30 * 24 * time.Hour},
+		{Match: map[string]any{"type": "kb"}},
+		{MaxAge: 24 * time.Hour},
+		{Match: map[string]any{"type": "unreachable"}, MaxAge: time.Hour},
+	}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 2 {
+		t.Fatalf("got %d filters, want 2", len(filters))
+	}
+
+	memory := filters[0]
+	if len(memory.Must) != 2 || len(memory.MustNot) != 0 {
+		t.Fatalf("got chat memory filter %v", memory)
+	}
+	if got := memory.Must[0].GetField().GetDatetimeRange().GetLt().AsTime(); !got.Equal(now.Add(-30 * 24 * time.Hour)) {
+		t.Errorf("got cutoff %v", got)
+	}
+
+	// The catch-all rule spares the documents of the previous rules.
+	rest := filters[1]
+	if len(rest.Must) != 1 || len(rest.MustNot) != 2 {
+		t.Errorf("got catch-all filter %v", rest)
+	}
+
+	if _, err := ds.retentionFilters([]RetentionRule{{Match: map[string]any{"n": 1.5}, MaxAge: time.Hour}}, now); err == nil {
+		t.Error("expected an error for an invalid match")
+	}
+}
@@ -0,0 +1,139 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultStreamPageSize is the number of documents fetched per query by
+// RetrieveStream.
+const defaultStreamPageSize = 10
+
+// StreamOptions configures [DocStore.RetrieveStream].
+type StreamOptions struct {
+	// PageSize is the number of documents fetched per query. Smaller
+	// pages deliver the first documents sooner at the cost of more
+	// queries. Defaults to 10.
+	PageSize int
+}
+
+// RetrieveStream runs a retrieve request like the retriever, but passes
+// the documents to fn page by page as Qdrant returns them instead of
+// buffering the whole response, so that interactive UIs can show the
+// first documents of a large K early. Retrieval stops at the first error
+// of fn, which is returned.
+//
+// Documents carry the score and ID metadata of the retriever, but not the
+// details that need every result, such as the confidence indicator.
+// Options reordering the whole result set (MMR, Prefer, UseEntities,
+// FeedbackBoost and SessionBoost) are rejected, as is GroupBy.
+func (ds *DocStore) RetrieveStream(ctx context.Context, req *ai.RetrieverRequest, opts *StreamOptions, fn func(*ai.Document) error) error {
+	ropt, err := parseRetrieverOptions(req.Options)
+	if err != nil {
+		return err
+	}
+	if name := reorderingOption(ropt); name != "" {
+		return fmt.Errorf("qdrant: RetrieveStream does not support %s", name)
+	}
+	if ropt.GroupBy != nil {
+		return errors.New("qdrant: RetrieveStream does not support GroupBy")
+	}
+	ctx, cancel := withTimeout(ctx, "RetrieveStream", ds.retrieveTimeout)
+	defer cancel()
+	return timeoutError(ctx, ds.retrieveStream(ctx, req, ropt, opts, fn))
+}
+
+// reorderingOption returns the name of the first option reordering the
+// whole result set on the client, which cannot be applied page by page by
+// RetrieveStream or RetrieverOptions.Offset, or "" if there is none.
+func reorderingOption(ropt *RetrieverOptions) string {
+	switch {
+	case ropt.MMR != nil:
+		return "MMR"
+	case len(ropt.Prefer) > 0:
+		return "Prefer"
+	case ropt.UseEntities:
+		return "UseEntities"
+	case ropt.FeedbackBoost != 0:
+		return "FeedbackBoost"
+	case ropt.SessionBoost != 0:
+		return "SessionBoost"
+	}
+	return ""
+}
+
+func (ds *DocStore) retrieveStream(ctx context.Context, req *ai.RetrieverRequest, ropt *RetrieverOptions, opts *StreamOptions, fn func(*ai.Document) error) error {
+	qdoc, err := ds.condenseQuery(ctx, ropt.History, req.Document)
+	if err != nil {
+		return err
+	}
+	if !ropt.byExample() && ds.guard.trivial(documentText(qdoc)) {
+		if ds.guard.policy == GuardError {
+			return ErrTrivialQuery
+		}
+		return nil
+	}
+	query, err := ds.queryPoints(ctx, ropt)
+	if err != nil {
+		return err
+	}
+	k := ropt.K
+	if k == 0 {
+		k = defaultQueryLimit
+	}
+	query.Limit = qclient.PtrOf(uint64(k))
+	qv, err := ds.embedQueryVectors(ctx, query, qdoc, ropt)
+	if err != nil {
+		return err
+	}
+	// The search query is built for all k results, so that pages share
+	// the prefetch limits of a single query.
+	search := ds.searchQuery(query, qv)
+
+	pageSize := defaultStreamPageSize
+	if opts != nil && opts.PageSize > 0 {
+		pageSize = opts.PageSize
+	}
+	var sent []*result
+	for offset := 0; offset < k; offset += pageSize {
+		page := proto.Clone(search).(*qclient.QueryPoints)
+		page.Limit = qclient.PtrOf(uint64(min(pageSize, k-offset)))
+		page.Offset = qclient.PtrOf(query.GetOffset() + uint64(offset))
+		points, err := ds.query(ctx, page)
+		if err != nil {
+			return err
+		}
+		ds.recordUsage(ropt.Tenant, func(u *Usage) { u.Queries++ })
+		results, err := ds.results(ctx, points)
+		if err != nil {
+			return err
+		}
+		if results, err = ds.safety.apply(ctx, results); err != nil {
+			return err
+		}
+		annotateMatches(results, ropt.Filter.GetShould())
+		for _, r := range results {
+			if r.doc.Metadata == nil {
+				r.doc.Metadata = make(map[string]any)
+			}
+			r.doc.Metadata[ScoreKey] = r.score
+			r.doc.Metadata[PointIDKey] = r.id
+			if err := fn(r.doc); err != nil {
+				return err
+			}
+		}
+		ds.recordUsage(ropt.Tenant, func(u *Usage) { u.RetrievedDocuments += int64(len(results)) })
+		sent = append(sent, results...)
+		if len(points) < pageSize {
+			break
+		}
+	}
+	ds.rememberSession(ctx, sent)
+	ds.captureRetrieval(ctx, qdoc, sent)
+	return nil
+}
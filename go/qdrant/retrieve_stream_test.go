@@ -0,0 +1,41 @@
+package qdrant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestRetrieveStreamRejectsWholeSetOptions(t *testing.T) {
+	ds := &DocStore{}
+	for _, ropt := range []*RetrieverOptions{
+		{MMR: &MMR{Lambda: 0.5}},
+		{Prefer: map[string]any{"lang": "en"}},
+		{UseEntities: true},
+		{FeedbackBoost: 0.1},
+		{SessionBoost: 0.1},
+	} {
+		req := &ai.RetrieverRequest{Document: ai.DocumentFromText("query", nil), Options: ropt}
+		if err := ds.RetrieveStream(context.Background(), req, nil, func(*ai.Document) error { return nil }); err == nil {
+			t.Errorf("%+v: expected an error", ropt)
+		}
+	}
+}
+
+func TestRetrieveStreamTrivialQuery(t *testing.T) {
+	ds := &DocStore{guard: newQueryGuard(QueryGuardConfig{})}
+	req := &ai.RetrieverRequest{Document: ai.DocumentFromText("the", nil)}
+	err := ds.RetrieveStream(context.Background(), req, nil, func(*ai.Document) error {
+		t.Error("got a document for a trivial query")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ds.guard.policy = GuardError
+	if err := ds.RetrieveStream(context.Background(), req, nil, nil); err != ErrTrivialQuery {
+		t.Errorf("got %v, want ErrTrivialQuery", err)
+	}
+}
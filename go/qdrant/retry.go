@@ -0,0 +1,91 @@
+package qdrant
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryConfig configures how upserts and queries failing with a transient
+// error, e.g. while Qdrant restarts, are retried with exponential backoff.
+type RetryConfig struct {
+	// MaxAttempts is the number of attempts of a request, including the
+	// first one. Defaults to 4.
+	MaxAttempts int
+	// BaseDelay is the delay before the second attempt. It doubles with
+	// every attempt. Defaults to 200ms.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts. Defaults to 5s.
+	MaxDelay time.Duration
+	// Codes are the gRPC codes retried. Defaults to Unavailable and
+	// DeadlineExceeded.
+	Codes []codes.Code
+}
+
+// retriedMethods are the gRPC methods retried by [retrier]. Upserts are
+// idempotent since point IDs derive from the documents.
+var retriedMethods = []string{
+	"/qdrant.Points/Upsert",
+	"/qdrant.Points/Query",
+	"/qdrant.Points/QueryBatch",
+	"/qdrant.Points/QueryGroups",
+}
+
+// retrier retries upserts and queries failing with a transient error.
+type retrier struct {
+	cfg RetryConfig
+}
+
+func newRetrier(cfg RetryConfig) *retrier {
+	if cfg.MaxAttempts == 0 {
+		cfg.MaxAttempts = 4
+	}
+	if cfg.BaseDelay == 0 {
+		cfg.BaseDelay = 200 * time.Millisecond
+	}
+	if cfg.MaxDelay == 0 {
+		cfg.MaxDelay = 5 * time.Second
+	}
+	if cfg.Codes == nil {
+		cfg.Codes = []codes.Code{codes.Unavailable, codes.DeadlineExceeded}
+	}
+	return &retrier{cfg: cfg}
+}
+
+// unaryInterceptor implements [grpc.UnaryClientInterceptor].
+func (r *retrier) unaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if !slices.Contains(retriedMethods, method) {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	for attempt := 1; ; attempt++ {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		// A deadline of the caller is final, unlike one of the server.
+		if err == nil || attempt >= r.cfg.MaxAttempts || ctx.Err() != nil || !slices.Contains(r.cfg.Codes, status.Code(err)) {
+			return err
+		}
+		delay := r.delay(attempt)
+		slog.WarnContext(ctx, "qdrant request failed, retrying", "method", method, "attempt", attempt, "delay", delay, "error", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// delay returns the time to wait after the given failed attempt.
+func (r *retrier) delay(attempt int) time.Duration {
+	d := r.cfg.BaseDelay << (attempt - 1)
+	if d <= 0 { // overflow
+		d = r.cfg.MaxDelay
+	}
+	return min(d, r.cfg.MaxDelay)
+}
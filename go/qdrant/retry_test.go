@@ -0,0 +1,51 @@
+package qdrant
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetrierDelay(t *testing.T) {
+	r := newRetrier(RetryConfig{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second})
+	for attempt, want := range map[int]time.Duration{
+		1:  100 * time.Millisecond,
+		2:  200 * time.Millisecond,
+		4:  800 * time.Millisecond,
+		5:  time.Second,
+		80: time.Second,
+	} {
+		if got := r.delay(attempt); got != want {
+			t.Errorf("delay(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
+
+func TestRetrierInterceptor(t *testing.T) {
+	r := newRetrier(RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond})
+	calls := 0
+	code := codes.Unavailable
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		calls++
+		return status.Error(code, "failed")
+	}
+	call := func(method string) error {
+		calls = 0
+		return r.unaryInterceptor(context.Background(), method, nil, nil, nil, invoker)
+	}
+
+	if err := call("/qdrant.Points/Upsert"); status.Code(err) != codes.Unavailable || calls != 3 {
+		t.Errorf("got %v after %d calls, want Unavailable after 3", err, calls)
+	}
+	if call("/qdrant.Points/Delete"); calls != 1 {
+		t.Errorf("got %d calls of an unretried method, want 1", calls)
+	}
+	code = codes.InvalidArgument
+	if call("/qdrant.Points/Query"); calls != 1 {
+		t.Errorf("got %d calls for a permanent error, want 1", calls)
+	}
+}
@@ -0,0 +1,191 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+// Route is a collection a router retriever can send questions to.
+type Route struct {
+	// Collection is the name of a collection configured with [Init].
+	Collection string
+	// Description tells the classification model what the collection
+	// contains.
+	Description string
+	// Examples are typical questions for the collection. The centroid of
+	// their embeddings is compared with the question embedding.
+	Examples []string
+}
+
+// RouterConfig configures [DefineRouter].
+type RouterConfig struct {
+	// Name is the name the router retriever is registered under.
+	Name   string
+	Routes []Route
+	// Embedder classifies questions by similarity to the route example
+	// centroids. If nil, Model is used instead.
+	Embedder ai.Embedder
+	// Model classifies questions from the route descriptions.
+	Model ai.Model
+	// MaxRoutes is the maximum number of collections queried per
+	// question. Defaults to 1.
+	MaxRoutes int
+}
+
+type router struct {
+	cfg       RouterConfig
+	centroids [][]float32
+}
+
+// DefineRouter registers a retriever that classifies each question and
+// queries the best matching collections, for corpora split by domain.
+// Documents are returned grouped by collection, best route first.
+func DefineRouter(ctx context.Context, cfg RouterConfig) (ai.Retriever, error) {
+	if len(cfg.Routes) == 0 {
+		return nil, errors.New("qdrant router has no routes")
+	}
+	if cfg.Embedder == nil && cfg.Model == nil {
+		return nil, errors.New("qdrant router requires an embedder or a model")
+	}
+	if cfg.MaxRoutes <= 0 {
+		cfg.MaxRoutes = 1
+	}
+	for _, route := range cfg.Routes {
+		if Store(route.Collection) == nil {
+			return nil, fmt.Errorf("qdrant router: collection %q is not configured", route.Collection)
+		}
+	}
+
+	r := &router{cfg: cfg}
+	if cfg.Embedder != nil {
+		for _, route := range cfg.Routes {
+			if len(route.Examples) == 0 {
+				return nil, fmt.Errorf("qdrant router: route %q has no examples", route.Collection)
+			}
+			resp, err := ai.Embed(ctx, cfg.Embedder, ai.WithEmbedText(route.Examples...))
+			if err != nil {
+				return nil, fmt.Errorf("qdrant router embedding failed: %v", err)
+			}
+			if len(resp.Embeddings) != len(route.Examples) {
+				return nil, fmt.Errorf("qdrant router embedding failed: embedder returned %d embeddings for %d examples of route %q", len(resp.Embeddings), len(route.Examples), route.Collection)
+			}
+			vectors := make([][]float32, len(resp.Embeddings))
+			for i, e := range resp.Embeddings {
+				vectors[i] = e.Embedding
+			}
+			c, err := centroid(vectors)
+			if err != nil {
+				return nil, fmt.Errorf("qdrant router: route %q: %v", route.Collection, err)
+			}
+			r.centroids = append(r.centroids, c)
+		}
+	}
+	return ai.DefineRetriever(provider, cfg.Name, r.retrieve), nil
+}
+
+func (r *router) retrieve(ctx context.Context, req *ai.RetrieverRequest) (_ *ai.RetrieverResponse, err error) {
+	defer recoverPanic(ctx, &err, "router retrieve")
+	routes, err := r.classify(ctx, req.Document)
+	if err != nil {
+		return nil, err
+	}
+	ret := &ai.RetrieverResponse{}
+	for _, route := range routes {
+		resp, err := Retriever(route.Collection).Retrieve(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		ret.Documents = append(ret.Documents, resp.Documents...)
+	}
+	return ret, nil
+}
+
+// classify returns the routes to query for the question, best first.
+func (r *router) classify(ctx context.Context, question *ai.Document) ([]Route, error) {
+	if r.cfg.Embedder != nil {
+		resp, err := ai.Embed(ctx, r.cfg.Embedder, ai.WithEmbedDocs(question))
+		if err != nil {
+			return nil, fmt.Errorf("qdrant router embedding failed: %v", err)
+		}
+		if len(resp.Embeddings) != 1 {
+			return nil, fmt.Errorf("qdrant router embedding failed: embedder returned %d embeddings for 1 document", len(resp.Embeddings))
+		}
+		return rankRoutes(r.cfg.Routes, r.centroids, resp.Embeddings[0].Embedding, r.cfg.MaxRoutes), nil
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("Pick the collections best suited to answer the question, most relevant first.\n")
+	fmt.Fprintf(&prompt, "Answer with at most %d collection names, one per line, and nothing else.\n\nCollections:\n", r.cfg.MaxRoutes)
+	for _, route := range r.cfg.Routes {
+		fmt.Fprintf(&prompt, "- %s: %s\n", route.Collection, route.Description)
+	}
+	fmt.Fprintf(&prompt, "\nQuestion: %s", documentText(question))
+	out, err := ai.GenerateText(ctx, r.cfg.Model, ai.WithTextPrompt(prompt.String()))
+	if err != nil {
+		return nil, fmt.Errorf("qdrant router classification failed: %v", err)
+	}
+	routes := parseRoutes(r.cfg.Routes, out, r.cfg.MaxRoutes)
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("qdrant router: model answered no known collection: %q", out)
+	}
+	return routes, nil
+}
+
+// rankRoutes returns up to n routes ordered by the cosine similarity of
+// their centroid to v.
+func rankRoutes(routes []Route, centroids [][]float32, v []float32, n int) []Route {
+	idx := make([]int, len(routes))
+	scores := make([]float32, len(routes))
+	for i := range routes {
+		idx[i] = i
+		scores[i] = cosine(centroids[i], v)
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return scores[idx[a]] > scores[idx[b]] })
+	ranked := make([]Route, 0, n)
+	for _, i := range idx[:min(n, len(idx))] {
+		ranked = append(ranked, routes[i])
+	}
+	return ranked
+}
+
+// parseRoutes returns up to n routes named in the model output, in order.
+func parseRoutes(routes []Route, out string, n int) []Route {
+	var picked []Route
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(out, "\n") {
+		name := strings.Trim(strings.TrimSpace(line), "-*`\"' ")
+		for _, route := range routes {
+			if strings.EqualFold(route.Collection, name) && !seen[route.Collection] {
+				seen[route.Collection] = true
+				picked = append(picked, route)
+			}
+		}
+		if len(picked) == n {
+			break
+		}
+	}
+	return picked
+}
+
+// centroid returns the mean of vectors, which must have the same
+// non-zero size.
+func centroid(vectors [][]float32) ([]float32, error) {
+	if len(vectors) == 0 || len(vectors[0]) == 0 {
+		return nil, errors.New("embedder returned no vector")
+	}
+	c := make([]float32, len(vectors[0]))
+	for _, v := range vectors {
+		if len(v) != len(c) {
+			return nil, fmt.Errorf("embedder returned vectors of sizes %d and %d", len(c), len(v))
+		}
+		for i := range c {
+			c[i] += v[i] / float32(len(vectors))
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,32 @@
+package qdrant
+
+import "testing"
+
+func TestRankRoutes(t *testing.T) {
+	routes := []Route{{Collection: "billing"}, {Collection: "docs"}, {Collection: "legal"}}
+	centroids := [][]float32{{1, 0}, {0, 1}, {0.7, 0.7}}
+	got := rankRoutes(routes, centroids, []float32{0.1, 1}, 2)
+	if len(got) != 2 || got[0].Collection != "docs" || got[1].Collection != "legal" {
+		t.Errorf("got %v, want [docs legal]", got)
+	}
+}
+
+func TestParseRoutes(t *testing.T) {
+	routes := []Route{{Collection: "billing"}, {Collection: "docs"}}
+	got := parseRoutes(routes, "- Docs\nunknown\nbilling\ndocs", 2)
+	if len(got) != 2 || got[0].Collection != "docs" || got[1].Collection != "billing" {
+		t.Errorf("got %v, want [docs billing]", got)
+	}
+}
+
+func TestCentroid(t *testing.T) {
+	c, err := centroid([][]float32{{1, 0}, {0, 1}})
+	if err != nil || c[0] != 0.5 || c[1] != 0.5 {
+		t.Errorf("got %v, %v; want [0.5 0.5]", c, err)
+	}
+	for _, bad := range [][][]float32{nil, {{}}, {{1, 0}, {1}}} {
+		if _, err := centroid(bad); err == nil {
+			t.Errorf("expected an error for %v", bad)
+		}
+	}
+}
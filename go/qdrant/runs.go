@@ -0,0 +1,27 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// runPayloadKey is the payload field holding [IndexerOptions.RunID].
+const runPayloadKey = "_ingest_run"
+
+// RollbackRun deletes every point last written by the ingestion run with
+// the given ID. Points re-indexed by a later run carry that run's ID and
+// are kept.
+func (ds *DocStore) RollbackRun(ctx context.Context, runID string) error {
+	if runID == "" {
+		return errors.New("qdrant: empty run ID")
+	}
+	return ds.deleteByFilter(ctx, runFilter(runID))
+}
+
+func runFilter(runID string) *qclient.Filter {
+	return &qclient.Filter{
+		Must: []*qclient.Condition{qclient.NewMatchKeyword(runPayloadKey, runID)},
+	}
+}
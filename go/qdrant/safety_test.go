@@ -0,0 +1,59 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestSafetyFilter(t *testing.T) {
+	classify := func(ctx context.Context, doc *ai.Document) (bool, error) {
+		text := documentText(doc)
+		if text == "fail" {
+			return false, errors.New("classifier down")
+		}
+		return strings.Contains(text, "unsafe"), nil
+	}
+	newResults := func() []*result {
+		return []*result{
+			{id: "1", doc: ai.DocumentFromText("fine", nil)},
+			{id: "2", doc: ai.DocumentFromText("unsafe text", nil)},
+		}
+	}
+
+	var none *safetyFilter
+	if got, _ := none.apply(context.Background(), newResults()); len(got) != 2 {
+		t.Errorf("got %d results without a filter, want 2", len(got))
+	}
+
+	drop, err := newSafetyFilter(SafetyConfig{Classify: classify})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := drop.apply(context.Background(), newResults())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].id != "1" {
+		t.Errorf("got %v, want only the safe result", got)
+	}
+	if drop.checked.Load() != 2 || drop.unsafe.Load() != 1 {
+		t.Errorf("got %d checked and %d unsafe, want 2 and 1", drop.checked.Load(), drop.unsafe.Load())
+	}
+
+	flag, _ := newSafetyFilter(SafetyConfig{Classify: classify, Action: SafetyFlag})
+	got, _ = flag.apply(context.Background(), newResults())
+	if len(got) != 2 || got[1].doc.Metadata[UnsafeKey] != true || got[0].doc.Metadata[UnsafeKey] != nil {
+		t.Errorf("got %v, want the unsafe result flagged", got)
+	}
+
+	if _, err := drop.apply(context.Background(), []*result{{doc: ai.DocumentFromText("fail", nil)}}); err == nil {
+		t.Error("expected the classifier error")
+	}
+	if _, err := newSafetyFilter(SafetyConfig{}); err == nil {
+		t.Error("expected an error without a classifier")
+	}
+}
@@ -0,0 +1,74 @@
+package qdrant
+
+import (
+	"maps"
+	"sync"
+	"time"
+)
+
+// defaultSchemaTTL is how long the payload schema is cached when
+// Config.SchemaCacheTTL is not set.
+const defaultSchemaTTL = time.Minute
+
+// schemaCache caches the payload indexes of the collection for
+// [DocStore.ValidateFilter] and the creation of missing filter indexes.
+// Indexes created by the store are added to the cached view, so that it
+// only misses indexes created or dropped by others until it expires.
+type schemaCache struct {
+	ttl time.Duration // negative disables the cache
+
+	mu      sync.Mutex
+	schema  map[string]PayloadIndexType
+	fetched time.Time
+}
+
+func newSchemaCache(ttl time.Duration) *schemaCache {
+	if ttl == 0 {
+		ttl = defaultSchemaTTL
+	}
+	return &schemaCache{ttl: ttl}
+}
+
+// get returns a copy of the cached schema if it is fresh.
+func (c *schemaCache) get(now time.Time) (map[string]PayloadIndexType, bool) {
+	if c == nil || c.ttl < 0 {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.schema == nil || now.Sub(c.fetched) >= c.ttl {
+		return nil, false
+	}
+	return maps.Clone(c.schema), true
+}
+
+func (c *schemaCache) set(schema map[string]PayloadIndexType, now time.Time) {
+	if c == nil || c.ttl < 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.schema, c.fetched = maps.Clone(schema), now
+}
+
+// addIndex records an index created by the store.
+func (c *schemaCache) addIndex(key string, t PayloadIndexType) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.schema != nil {
+		c.schema[key] = t
+	}
+}
+
+// invalidate drops the cached schema, e.g. after recreating the collection.
+func (c *schemaCache) invalidate() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.schema = nil
+}
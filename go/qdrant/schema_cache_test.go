@@ -0,0 +1,37 @@
+package qdrant
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchemaCache(t *testing.T) {
+	now := time.Now()
+	c := newSchemaCache(0)
+	if _, ok := c.get(now); ok {
+		t.Fatal("empty cache returned a schema")
+	}
+	c.set(map[string]PayloadIndexType{"_metadata.lang": PayloadIndexKeyword}, now)
+	c.addIndex("_metadata.year", PayloadIndexInteger)
+	schema, ok := c.get(now.Add(time.Second))
+	if !ok || schema["_metadata.lang"] != PayloadIndexKeyword || schema["_metadata.year"] != PayloadIndexInteger {
+		t.Errorf("got %v, %v", schema, ok)
+	}
+	schema["_metadata.other"] = PayloadIndexBool
+	if cached, _ := c.get(now); len(cached) != 2 {
+		t.Errorf("changing a returned schema changed the cache: %v", cached)
+	}
+	if _, ok := c.get(now.Add(defaultSchemaTTL)); ok {
+		t.Error("expired schema returned")
+	}
+	c.invalidate()
+	if _, ok := c.get(now); ok {
+		t.Error("invalidated schema returned")
+	}
+
+	disabled := newSchemaCache(-1)
+	disabled.set(map[string]PayloadIndexType{}, now)
+	if _, ok := disabled.get(now); ok {
+		t.Error("disabled cache returned a schema")
+	}
+}
@@ -0,0 +1,32 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// scroll calls fn for every point matching req, following pagination
+// until all points have been visited or fn returns an error. Scrolls of
+// the collection of the store are restricted to its namespace.
+func (ds *DocStore) scroll(ctx context.Context, req *qclient.ScrollPoints, fn func(*qclient.RetrievedPoint) error) error {
+	if req.CollectionName == ds.collectionName {
+		req.Filter = ds.scopeFilter(req.Filter)
+	}
+	for {
+		resp, err := ds.client.GetPointsClient().Scroll(ctx, req)
+		if err != nil {
+			return fmt.Errorf("qdrant scroll failed: %v", err)
+		}
+		for _, p := range resp.GetResult() {
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+		if resp.NextPageOffset == nil {
+			return nil
+		}
+		req.Offset = resp.NextPageOffset
+	}
+}
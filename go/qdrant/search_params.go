@@ -0,0 +1,56 @@
+package qdrant
+
+import (
+	"errors"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// SearchParams tunes the vector search of a retrieval.
+type SearchParams struct {
+	// HnswEf is the size of the candidate list of the HNSW search. Higher
+	// values raise recall at the cost of latency. Defaults to the ef_construct
+	// of the collection.
+	HnswEf uint64 `json:"hnswEf,omitempty"`
+	// Exact searches without the HNSW index, comparing the query with
+	// every point. It is slow but gives the true nearest neighbours, which
+	// makes it the baseline of evaluation runs.
+	Exact bool `json:"exact,omitempty"`
+	// IndexedOnly skips segments that are not indexed yet, like
+	// RetrieverOptions.IndexedOnly.
+	IndexedOnly bool `json:"indexedOnly,omitempty"`
+}
+
+func (p *SearchParams) validate() error {
+	if p.Exact && p.HnswEf != 0 {
+		return errors.New("searchParams: hnswEf has no effect with exact search")
+	}
+	if p.Exact && p.IndexedOnly {
+		return errors.New("searchParams: indexedOnly cannot be combined with exact search")
+	}
+	return nil
+}
+
+// searchParams returns the Qdrant search params of the options, or nil if
+// they use the defaults.
+func (ropt *RetrieverOptions) searchParams() *qclient.SearchParams {
+	p := ropt.SearchParams
+	if p == nil {
+		p = &SearchParams{}
+	}
+	var params qclient.SearchParams
+	set := false
+	if p.HnswEf != 0 {
+		params.HnswEf, set = qclient.PtrOf(p.HnswEf), true
+	}
+	if p.Exact {
+		params.Exact, set = qclient.PtrOf(true), true
+	}
+	if p.IndexedOnly || ropt.IndexedOnly {
+		params.IndexedOnly, set = qclient.PtrOf(true), true
+	}
+	if !set {
+		return nil
+	}
+	return &params
+}
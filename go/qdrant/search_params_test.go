@@ -0,0 +1,26 @@
+package qdrant
+
+import "testing"
+
+func TestSearchParams(t *testing.T) {
+	if p := (&RetrieverOptions{}).searchParams(); p != nil {
+		t.Errorf("got params %v without options", p)
+	}
+	p := (&RetrieverOptions{IndexedOnly: true, SearchParams: &SearchParams{HnswEf: 256}}).searchParams()
+	if p.GetHnswEf() != 256 || !p.GetIndexedOnly() || p.GetExact() {
+		t.Errorf("got %v", p)
+	}
+	if p := (&RetrieverOptions{SearchParams: &SearchParams{Exact: true}}).searchParams(); !p.GetExact() {
+		t.Errorf("got %v", p)
+	}
+
+	for _, bad := range []*RetrieverOptions{
+		{SearchParams: &SearchParams{Exact: true, HnswEf: 64}},
+		{SearchParams: &SearchParams{Exact: true, IndexedOnly: true}},
+		{SearchParams: &SearchParams{Exact: true}, IndexedOnly: true},
+	} {
+		if err := bad.validate(); err == nil {
+			t.Errorf("%+v: expected an error", bad.SearchParams)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package qdrant
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// Limits of the session memory of a store. The least recently used
+// session is forgotten first, and a session only remembers its most
+// recently retrieved points.
+const (
+	maxSessions      = 1024
+	maxSessionPoints = 512
+)
+
+type sessionKey struct{}
+
+// WithSession returns a context carrying a conversation session ID. The
+// retriever remembers the documents it returns within a session, so that
+// RetrieverOptions.SessionBoost and SessionExclude can favor or skip them
+// in later turns of the conversation.
+func WithSession(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, id)
+}
+
+func sessionFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(sessionKey{}).(string)
+	return id
+}
+
+// sessionMemory records the points retrieved per session. The zero value
+// is ready to use.
+type sessionMemory struct {
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+type session struct {
+	ids  []string // oldest first
+	used time.Time
+}
+
+// seen returns the points retrieved in a session.
+func (m *sessionMemory) seen(id string) map[string]bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.sessions[id]
+	if s == nil {
+		return nil
+	}
+	seen := make(map[string]bool, len(s.ids))
+	for _, p := range s.ids {
+		seen[p] = true
+	}
+	return seen
+}
+
+// add records points retrieved in a session.
+func (m *sessionMemory) add(id string, points []string, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sessions == nil {
+		m.sessions = make(map[string]*session)
+	}
+	s := m.sessions[id]
+	if s == nil {
+		if len(m.sessions) >= maxSessions {
+			m.evict()
+		}
+		s = &session{}
+		m.sessions[id] = s
+	}
+	s.used = now
+	for _, p := range points {
+		for i, q := range s.ids {
+			if q == p {
+				s.ids = append(s.ids[:i], s.ids[i+1:]...)
+				break
+			}
+		}
+		s.ids = append(s.ids, p)
+	}
+	if n := len(s.ids) - maxSessionPoints; n > 0 {
+		s.ids = s.ids[n:]
+	}
+}
+
+// evict forgets the least recently used session.
+func (m *sessionMemory) evict() {
+	var oldest string
+	var used time.Time
+	for id, s := range m.sessions {
+		if oldest == "" || s.used.Before(used) {
+			oldest, used = id, s.used
+		}
+	}
+	delete(m.sessions, oldest)
+}
+
+// sessionFilter excludes the points already retrieved in the session of
+// ctx, or returns nil.
+func (ds *DocStore) sessionFilter(ctx context.Context) *qclient.Condition {
+	seen := ds.sessions.seen(sessionFromContext(ctx))
+	if len(seen) == 0 {
+		return nil
+	}
+	ids := make([]*qclient.PointId, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, pointIDOf(id))
+	}
+	return qclient.NewHasID(ids...)
+}
+
+// applySession adds boost to the score of the results already retrieved
+// in the session of ctx and sorts the results by descending score.
+func (ds *DocStore) applySession(ctx context.Context, results []*result, boost float32) {
+	seen := ds.sessions.seen(sessionFromContext(ctx))
+	if len(seen) == 0 {
+		return
+	}
+	net := make(map[string]int, len(seen))
+	for id := range seen {
+		net[id] = 1
+	}
+	rescore(results, net, boost)
+}
+
+// rememberSession records the results in the session of ctx, if any.
+func (ds *DocStore) rememberSession(ctx context.Context, results []*result) {
+	id := sessionFromContext(ctx)
+	if id == "" || len(results) == 0 {
+		return
+	}
+	points := make([]string, len(results))
+	for i, r := range results {
+		points[i] = r.id
+	}
+	ds.sessions.add(id, points, time.Now())
+}
@@ -0,0 +1,64 @@
+package qdrant
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSessionMemory(t *testing.T) {
+	var m sessionMemory
+	now := time.Now()
+	m.add("s1", []string{"a", "b"}, now)
+	m.add("s1", []string{"a", "c"}, now)
+	if seen := m.seen("s1"); len(seen) != 3 || !seen["a"] || !seen["c"] {
+		t.Errorf("got %v, want a, b and c", seen)
+	}
+	if seen := m.seen("s2"); seen != nil {
+		t.Errorf("got %v for an unknown session", seen)
+	}
+
+	for i := range maxSessions {
+		m.add(fmt.Sprint("other", i), []string{"x"}, now.Add(time.Duration(i+1)*time.Second))
+	}
+	if m.seen("s1") != nil {
+		t.Error("least recently used session was not evicted")
+	}
+
+	ids := make([]string, maxSessionPoints+10)
+	for i := range ids {
+		ids[i] = fmt.Sprint(i)
+	}
+	m.add("big", ids, now)
+	if seen := m.seen("big"); len(seen) != maxSessionPoints || seen["0"] {
+		t.Errorf("session keeps %d points, want the last %d", len(seen), maxSessionPoints)
+	}
+}
+
+func TestApplySession(t *testing.T) {
+	ds := &DocStore{}
+	ctx := WithSession(context.Background(), "conv")
+	ds.rememberSession(ctx, []*result{{id: "b"}})
+
+	results := []*result{{id: "a", score: 0.9}, {id: "b", score: 0.85}}
+	ds.applySession(ctx, results, 0.1)
+	if results[0].id != "b" {
+		t.Errorf("got %q first, want the previously retrieved b", results[0].id)
+	}
+	if ds.sessionFilter(context.Background()) != nil {
+		t.Error("got a filter without a session")
+	}
+	if ds.sessionFilter(ctx) == nil {
+		t.Error("got no filter for the session")
+	}
+}
+
+func TestSessionFilterNumericID(t *testing.T) {
+	ds := &DocStore{}
+	ctx := WithSession(context.Background(), "conv")
+	ds.rememberSession(ctx, []*result{{id: "42"}})
+	if got := ds.sessionFilter(ctx).GetHasId().GetHasId()[0]; got.GetNum() != 42 {
+		t.Errorf("got %v, want the numeric ID 42", got)
+	}
+}
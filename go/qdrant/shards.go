@@ -0,0 +1,113 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// ShardSelector restricts a query to a subset of the collection's shards.
+// Shards are addressed by their shard keys, so the collection must use
+// custom sharding.
+type ShardSelector struct {
+	// Include lists the shard keys to search. If empty, all shard keys
+	// of the collection are candidates.
+	Include []*qclient.ShardKey
+	// Exclude lists shard keys to skip, e.g. shards on a degraded node.
+	Exclude []*qclient.ShardKey
+}
+
+// shardSelector returns the shard selector of the retriever options.
+func (ropt *RetrieverOptions) shardSelector() *ShardSelector {
+	if len(ropt.ShardKeys) == 0 {
+		return ropt.Shards
+	}
+	sel := &ShardSelector{}
+	for _, k := range ropt.ShardKeys {
+		sel.Include = append(sel.Include, qclient.NewShardKey(k))
+	}
+	return sel
+}
+
+// shardKeySelector resolves the selector into the shard keys to query.
+// Exclusions are applied against the shard keys currently known to the
+// cluster, since Qdrant only accepts an explicit list of keys.
+func (ds *DocStore) shardKeySelector(ctx context.Context, sel *ShardSelector) (*qclient.ShardKeySelector, error) {
+	if sel == nil || (len(sel.Include) == 0 && len(sel.Exclude) == 0) {
+		return nil, nil
+	}
+
+	keys := sel.Include
+	if len(keys) == 0 {
+		var err error
+		keys, err = ds.shardKeys(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	excluded := make(map[string]bool, len(sel.Exclude))
+	for _, k := range sel.Exclude {
+		excluded[shardKeyString(k)] = true
+	}
+	selected := make([]*qclient.ShardKey, 0, len(keys))
+	for _, k := range keys {
+		if !excluded[shardKeyString(k)] {
+			selected = append(selected, k)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, errors.New("qdrant shard selector excludes every shard key")
+	}
+	return &qclient.ShardKeySelector{ShardKeys: selected}, nil
+}
+
+// shardKeys returns the distinct shard keys of the collection, failing if
+// it has none.
+func (ds *DocStore) shardKeys(ctx context.Context) ([]*qclient.ShardKey, error) {
+	keys, err := ds.clusterShardKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("qdrant collection %q has no shard keys", ds.collectionName)
+	}
+	return keys, nil
+}
+
+// clusterShardKeys returns the distinct shard keys of the collection.
+func (ds *DocStore) clusterShardKeys(ctx context.Context) ([]*qclient.ShardKey, error) {
+	info, err := ds.client.GetCollectionsClient().CollectionClusterInfo(ctx, &qclient.CollectionClusterInfoRequest{
+		CollectionName: ds.collectionName,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("qdrant failed to fetch cluster info: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	var keys []*qclient.ShardKey
+	add := func(k *qclient.ShardKey) {
+		if k == nil || seen[shardKeyString(k)] {
+			return
+		}
+		seen[shardKeyString(k)] = true
+		keys = append(keys, k)
+	}
+	for _, s := range info.GetLocalShards() {
+		add(s.GetShardKey())
+	}
+	for _, s := range info.GetRemoteShards() {
+		add(s.GetShardKey())
+	}
+	return keys, nil
+}
+
+// shardKeyString returns a comparable representation of a shard key.
+func shardKeyString(k *qclient.ShardKey) string {
+	if n, ok := k.GetKey().(*qclient.ShardKey_Number); ok {
+		return fmt.Sprintf("#%d", n.Number)
+	}
+	return k.GetKeyword()
+}
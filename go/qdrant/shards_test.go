@@ -0,0 +1,53 @@
+package qdrant
+
+import (
+	"context"
+	"testing"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+func TestShardKeySelector(t *testing.T) {
+	ds := &DocStore{}
+	ctx := context.Background()
+
+	sel, err := ds.shardKeySelector(ctx, &ShardSelector{
+		Include: []*qclient.ShardKey{qclient.NewShardKey("a"), qclient.NewShardKey("b"), qclient.NewShardKeyNum(3)},
+		Exclude: []*qclient.ShardKey{qclient.NewShardKey("b")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, k := range sel.GetShardKeys() {
+		got = append(got, shardKeyString(k))
+	}
+	if len(got) != 2 || got[0] != "a" || got[1] != "#3" {
+		t.Errorf("got shard keys %v, want [a #3]", got)
+	}
+
+	if _, err := ds.shardKeySelector(ctx, &ShardSelector{
+		Include: []*qclient.ShardKey{qclient.NewShardKey("a")},
+		Exclude: []*qclient.ShardKey{qclient.NewShardKey("a")},
+	}); err == nil {
+		t.Error("expected an error when every shard key is excluded")
+	}
+
+	if sel, err := ds.shardKeySelector(ctx, nil); sel != nil || err != nil {
+		t.Errorf("nil selector: got %v, %v", sel, err)
+	}
+}
+
+func TestRetrieverShardKeys(t *testing.T) {
+	ropt, err := parseRetrieverOptions(map[string]any{"shardKeys": []any{"eu", "us"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sel := ropt.shardSelector()
+	if len(sel.Include) != 2 || sel.Include[1].GetKeyword() != "us" {
+		t.Errorf("got selector %v", sel)
+	}
+	if _, err := parseRetrieverOptions(&RetrieverOptions{ShardKeys: []string{"eu"}, Shards: &ShardSelector{}}); err == nil {
+		t.Error("expected an error for shardKeys with Shards")
+	}
+}
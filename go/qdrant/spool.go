@@ -0,0 +1,215 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const spoolExt = ".upsert"
+
+// rejectedExt is appended to the name of a spooled request Qdrant
+// rejected, which is then kept for inspection but no longer drained.
+const rejectedExt = ".rejected"
+
+// spoolDrainInterval is the time between the background drains of a
+// spool holding requests.
+const spoolDrainInterval = 10 * time.Second
+
+// spool is a directory of upsert requests that could not reach Qdrant.
+// Each request is stored in its own file, named so that lexical order is
+// the order of arrival. The points are stored with their vectors, so
+// draining the spool does not embed the documents again.
+type spool struct {
+	dir string
+
+	mu  sync.Mutex // serializes drains
+	seq atomic.Uint64
+}
+
+// openSpool creates the spool directory if needed.
+func openSpool(dir string) (*spool, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("qdrant failed to create spool directory: %v", err)
+	}
+	return &spool{dir: dir}, nil
+}
+
+// push stores an upsert request. The file is written under a temporary name
+// and renamed, so a crash never leaves a partial request to drain.
+func (s *spool) push(req *qclient.UpsertPoints) error {
+	b, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%020d-%06d%s", time.Now().UnixNano(), s.seq.Add(1)%1000000, spoolExt)
+
+	tmp := filepath.Join(s.dir, "."+name)
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(s.dir, name))
+}
+
+// pending returns the paths of the spooled requests in arrival order.
+func (s *spool) pending() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, e := range entries {
+		if !e.IsDir() && !strings.HasPrefix(e.Name(), ".") && strings.HasSuffix(e.Name(), spoolExt) {
+			paths = append(paths, filepath.Join(s.dir, e.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// drain replays the spooled requests in order with upsert, removing each
+// one once it succeeds, and returns the number of requests replayed. It
+// stops at the first request failing because Qdrant is unreachable. A
+// request Qdrant rejects, or a corrupt file, is renamed with rejectedExt
+// so that it does not hold back the requests spooled after it, and the
+// drain goes on; the rejections are returned joined.
+func (s *spool) drain(ctx context.Context, upsert func(context.Context, *qclient.UpsertPoints) error) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	paths, err := s.pending()
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	var rejected []error
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return n, err
+		}
+		req := &qclient.UpsertPoints{}
+		if err := proto.Unmarshal(b, req); err != nil {
+			rejected = append(rejected, s.reject(ctx, path, fmt.Errorf("qdrant spool file %s is corrupt: %v", path, err)))
+			continue
+		}
+		if err := upsert(ctx, req); err != nil {
+			if unreachable(err) {
+				return n, err
+			}
+			rejected = append(rejected, s.reject(ctx, path, fmt.Errorf("qdrant rejected spool file %s: %w", path, err)))
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return n + 1, err
+		}
+		n++
+	}
+	return n, errors.Join(rejected...)
+}
+
+// reject moves a spooled request that cannot be replayed out of the
+// drain, and returns the reason it was rejected.
+func (s *spool) reject(ctx context.Context, path string, reason error) error {
+	slog.WarnContext(ctx, "qdrant spooled upsert rejected", "file", path+rejectedExt, "error", reason)
+	if err := os.Rename(path, path+rejectedExt); err != nil {
+		return fmt.Errorf("%w (moving it aside also failed: %v)", reason, err)
+	}
+	return reason
+}
+
+// unreachable reports whether err means that Qdrant could not be reached,
+// as opposed to rejecting the request.
+func unreachable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// upsert writes points to the collection. If a spool is configured, the
+// spooled requests are drained first so that writes reach Qdrant in order,
+// and the request is spooled instead if Qdrant is unreachable.
+func (ds *DocStore) upsert(ctx context.Context, req *qclient.UpsertPoints) error {
+	var err error
+	if ds.spool != nil {
+		_, err = ds.DrainSpool(ctx)
+		if err != nil && !unreachable(err) {
+			// The drain moved the requests the server rejects aside, so
+			// they do not block indexing.
+			slog.WarnContext(ctx, "qdrant spool drain failed", "collection", ds.collectionName, "error", err)
+			err = nil
+		}
+	}
+	if err == nil {
+		_, err = ds.dataClient().Upsert(ctx, req)
+	}
+	if err == nil || ds.spool == nil || !unreachable(err) {
+		return err
+	}
+	if serr := ds.spool.push(req); serr != nil {
+		return fmt.Errorf("%w (spooling also failed: %v)", err, serr)
+	}
+	slog.WarnContext(ctx, "qdrant unreachable, spooled upsert", "collection", ds.collectionName, "points", len(req.Points), "error", err)
+	return nil
+}
+
+// DrainSpool replays the upserts spooled while Qdrant was unreachable and
+// returns how many were replayed. Upserts Qdrant rejects are renamed with
+// a ".rejected" suffix in the spool directory and returned as errors;
+// they are not replayed again. It is called automatically before every
+// upsert and in the background every 10s while the spool holds requests,
+// and may be called to drain the spool sooner, e.g. when connectivity is
+// restored. Requires Config.SpoolDir.
+func (ds *DocStore) DrainSpool(ctx context.Context) (int, error) {
+	if ds.spool == nil {
+		return 0, errors.New("qdrant: no spool directory configured")
+	}
+	if err := ds.writable("DrainSpool"); err != nil {
+		return 0, err
+	}
+	n, err := ds.spool.drain(ctx, func(ctx context.Context, req *qclient.UpsertPoints) error {
+		_, err := ds.client.Upsert(ctx, req)
+		return err
+	})
+	if err != nil {
+		return n, fmt.Errorf("qdrant spool drain failed: %w", err)
+	}
+	return n, nil
+}
+
+// drainSpoolLoop drains the spool every interval while it holds requests,
+// so that they reach Qdrant once it is reachable again without waiting
+// for the next upsert. It returns when ctx is done.
+func (ds *DocStore) drainSpoolLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if paths, err := ds.spool.pending(); err != nil || len(paths) == 0 {
+			continue
+		}
+		if n, err := ds.DrainSpool(ctx); err != nil && !unreachable(err) {
+			slog.WarnContext(ctx, "qdrant spool drain failed", "collection", ds.collectionName, "error", err)
+		} else if n > 0 {
+			slog.InfoContext(ctx, "qdrant spool drained", "collection", ds.collectionName, "upserts", n)
+		}
+	}
+}
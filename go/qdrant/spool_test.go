@@ -0,0 +1,103 @@
+package qdrant
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSpool(t *testing.T) {
+	ctx := context.Background()
+	s, err := openSpool(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		req := &qclient.UpsertPoints{CollectionName: id}
+		if err := s.push(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// An unreachable server stops the drain and keeps the remaining
+	// requests.
+	var got []string
+	n, err := s.drain(ctx, func(ctx context.Context, req *qclient.UpsertPoints) error {
+		if req.CollectionName == "b" {
+			return status.Error(codes.Unavailable, "connection refused")
+		}
+		got = append(got, req.CollectionName)
+		return nil
+	})
+	if n != 1 || err == nil {
+		t.Errorf("got %d, %v; want 1 request replayed and an error", n, err)
+	}
+
+	n, err = s.drain(ctx, func(ctx context.Context, req *qclient.UpsertPoints) error {
+		got = append(got, req.CollectionName)
+		return nil
+	})
+	if n != 2 || err != nil {
+		t.Errorf("got %d, %v; want 2 requests replayed", n, err)
+	}
+	if len(got) != 3 || got[0] != "a" || got[1] != "b" || got[2] != "c" {
+		t.Errorf("replayed %v, want [a b c]", got)
+	}
+	if paths, _ := s.pending(); len(paths) != 0 {
+		t.Errorf("%d requests left in the spool", len(paths))
+	}
+}
+
+func TestSpoolRejected(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	s, err := openSpool(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		if err := s.push(&qclient.UpsertPoints{CollectionName: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "99999999999999999999-000000"+spoolExt), []byte("corrupt"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rejected and corrupt requests are moved aside without holding back
+	// the requests after them.
+	var got []string
+	n, err := s.drain(ctx, func(ctx context.Context, req *qclient.UpsertPoints) error {
+		if req.CollectionName == "a" {
+			return status.Error(codes.InvalidArgument, "bad vector")
+		}
+		got = append(got, req.CollectionName)
+		return nil
+	})
+	if n != 2 || err == nil || unreachable(err) {
+		t.Errorf("got %d, %v; want 2 requests replayed and the rejections", n, err)
+	}
+	if len(got) != 2 || got[0] != "b" || got[1] != "c" {
+		t.Errorf("replayed %v, want [b c]", got)
+	}
+	if paths, _ := s.pending(); len(paths) != 0 {
+		t.Errorf("%d requests left in the spool", len(paths))
+	}
+	if rejected, _ := filepath.Glob(filepath.Join(dir, "*"+rejectedExt)); len(rejected) != 2 {
+		t.Errorf("got rejected files %v, want 2", rejected)
+	}
+}
+
+func TestUnreachable(t *testing.T) {
+	if !unreachable(status.Error(codes.Unavailable, "connection refused")) {
+		t.Error("Unavailable should be unreachable")
+	}
+	if unreachable(status.Error(codes.InvalidArgument, "bad vector")) {
+		t.Error("InvalidArgument should not be unreachable")
+	}
+}
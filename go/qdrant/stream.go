@@ -0,0 +1,155 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// sourceIDKey is the metadata key holding the ID of the source document a
+// chunk was produced from, so that all chunks of a source can be replaced
+// or deleted together.
+const sourceIDKey = "source_id"
+
+// EventType is the kind of change carried by a [DocumentEvent].
+type EventType string
+
+const (
+	// EventUpsert creates or replaces the document of a source.
+	EventUpsert EventType = "upsert"
+	// EventDelete deletes the document of a source.
+	EventDelete EventType = "delete"
+)
+
+// DocumentEvent is a change to a source document, such as a message read
+// from Kafka or Pub/Sub.
+type DocumentEvent struct {
+	Type EventType
+	// SourceID identifies the source document in the upstream system.
+	SourceID string
+	// Document is the new version of the document. Unused for deletes.
+	Document *ai.Document
+}
+
+// EventStream is a stream of document events. Adapters for message
+// brokers implement it by decoding messages in Next and committing their
+// offsets or acknowledging them in Commit.
+type EventStream interface {
+	// Next blocks until an event is available. It returns io.EOF once
+	// the stream is exhausted.
+	Next(ctx context.Context) (*DocumentEvent, error)
+	// Commit marks an event as processed. Events not committed are
+	// expected to be delivered again, e.g. after a restart.
+	Commit(ctx context.Context, ev *DocumentEvent) error
+}
+
+// ChannelStream is an [EventStream] reading from a channel. Commit is a
+// no-op. It is useful for tests and in-process producers.
+type ChannelStream <-chan *DocumentEvent
+
+// Next implements [EventStream.Next].
+func (c ChannelStream) Next(ctx context.Context) (*DocumentEvent, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case ev, ok := <-c:
+		if !ok {
+			return nil, io.EOF
+		}
+		return ev, nil
+	}
+}
+
+// Commit implements [EventStream.Commit].
+func (c ChannelStream) Commit(ctx context.Context, ev *DocumentEvent) error {
+	return nil
+}
+
+// Consume applies the events of stream to the collection until the stream
+// ends or an event fails, keeping the collection in sync with the
+// upstream system. Upserted documents are chunked with opts and replace
+// all previous chunks of their source. An event is committed only once
+// applied, so delivery is at least once; applying an event twice is
+// harmless.
+func (ds *DocStore) Consume(ctx context.Context, stream EventStream, opts ChunkOptions) error {
+	for {
+		ev, err := stream.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("qdrant consume failed: %v", err)
+		}
+		if err := ds.applyEvent(ctx, ev, opts); err != nil {
+			return err
+		}
+		if err := stream.Commit(ctx, ev); err != nil {
+			return fmt.Errorf("qdrant consume commit failed: %v", err)
+		}
+	}
+}
+
+func (ds *DocStore) applyEvent(ctx context.Context, ev *DocumentEvent, opts ChunkOptions) error {
+	if ev.SourceID == "" {
+		return errors.New("qdrant consume: event has no source ID")
+	}
+	switch ev.Type {
+	case EventUpsert:
+		if ev.Document == nil {
+			return fmt.Errorf("qdrant consume: upsert of %q has no document", ev.SourceID)
+		}
+		return ds.ReplaceSource(ctx, ev.SourceID, []*ai.Document{ev.Document}, opts)
+	case EventDelete:
+		return ds.DeleteSource(ctx, ev.SourceID)
+	default:
+		return fmt.Errorf("qdrant consume: unknown event type %q for %q", ev.Type, ev.SourceID)
+	}
+}
+
+// ReplaceSource replaces all the chunks of a source document with the
+// chunks of docs. The new chunks are indexed before the old ones are
+// deleted, so the source keeps its previous chunks if indexing fails.
+func (ds *DocStore) ReplaceSource(ctx context.Context, sourceID string, docs []*ai.Document, opts ChunkOptions) error {
+	chunks := chunkDocuments(docs, map[string]any{sourceIDKey: sourceID}, opts)
+	filter, err := ds.staleChunksFilter(sourceID, chunks)
+	if err != nil {
+		return err
+	}
+	if err := ds.Index(ctx, &ai.IndexerRequest{Documents: chunks}); err != nil {
+		return err
+	}
+	return ds.deleteByFilter(ctx, filter)
+}
+
+// staleChunksFilter matches the chunks of a source other than chunks.
+func (ds *DocStore) staleChunksFilter(sourceID string, chunks []*ai.Document) (*qclient.Filter, error) {
+	filter := ds.sourceFilter(sourceID)
+	if len(chunks) == 0 {
+		return filter, nil
+	}
+	ids := make([]*qclient.PointId, len(chunks))
+	for i, chunk := range chunks {
+		id, err := ds.pointID(chunk, "")
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = pointIDOf(id)
+	}
+	filter.MustNot = []*qclient.Condition{qclient.NewHasID(ids...)}
+	return filter, nil
+}
+
+// DeleteSource deletes all the chunks of a source document.
+func (ds *DocStore) DeleteSource(ctx context.Context, sourceID string) error {
+	return ds.deleteByFilter(ctx, ds.sourceFilter(sourceID))
+}
+
+func (ds *DocStore) sourceFilter(sourceID string) *qclient.Filter {
+	return &qclient.Filter{
+		Must: []*qclient.Condition{qclient.NewMatchKeyword(ds.metadataField(sourceIDKey), sourceID)},
+	}
+}
@@ -0,0 +1,73 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestApplyEventInvalid(t *testing.T) {
+	ds := &DocStore{metadataPayloadKey: metadataPayloadKey}
+	for _, ev := range []*DocumentEvent{
+		{Type: EventDelete},
+		{Type: EventUpsert, SourceID: "doc-1"},
+		{Type: "rename", SourceID: "doc-1"},
+	} {
+		if err := ds.applyEvent(context.Background(), ev, ChunkOptions{}); err == nil {
+			t.Errorf("%+v: expected an error", ev)
+		}
+	}
+	if got := ds.sourceFilter("doc-1").GetMust()[0].GetField().GetKey(); got != "_metadata.source_id" {
+		t.Errorf("got source filter on %q", got)
+	}
+}
+
+func TestChannelStream(t *testing.T) {
+	c := make(chan *DocumentEvent, 1)
+	c <- &DocumentEvent{Type: EventDelete, SourceID: "doc-1"}
+	close(c)
+	s := ChannelStream(c)
+	if ev, err := s.Next(context.Background()); err != nil || ev.SourceID != "doc-1" {
+		t.Errorf("got %v, %v", ev, err)
+	}
+	if _, err := s.Next(context.Background()); !errors.Is(err, io.EOF) {
+		t.Errorf("got %v after the last event, want io.EOF", err)
+	}
+}
+
+func TestReplaceSourceIndexesFirst(t *testing.T) {
+	var calls []string
+	var deleted *qclient.Filter
+	ds := countingStore(t, "docs", 0)
+	ds.client = fakeClient(t, func(method string, req any) (proto.Message, error) {
+		calls = append(calls, method)
+		if del, ok := req.(*qclient.DeletePoints); ok {
+			deleted = del.GetPoints().GetFilter()
+		}
+		return &qclient.PointsOperationResponse{}, nil
+	})
+	ctx := context.Background()
+
+	// A failed index keeps the previous chunks of the source.
+	if err := ds.ReplaceSource(ctx, "a.md", []*ai.Document{ai.DocumentFromText("bad", nil)}, ChunkOptions{}); err == nil {
+		t.Fatal("expected the embedding error")
+	}
+	if len(calls) != 0 {
+		t.Errorf("got calls %v, want none", calls)
+	}
+
+	if err := ds.ReplaceSource(ctx, "a.md", []*ai.Document{ai.DocumentFromText("good", nil)}, ChunkOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 || calls[0] != "/qdrant.Points/Upsert" || calls[1] != "/qdrant.Points/Delete" {
+		t.Fatalf("got calls %v, want an upsert then a delete", calls)
+	}
+	if len(deleted.GetMustNot()) != 1 || len(deleted.MustNot[0].GetHasId().GetHasId()) != 1 {
+		t.Errorf("got delete filter %v, want the new chunk excluded", deleted)
+	}
+}
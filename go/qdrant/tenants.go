@@ -0,0 +1,215 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// tenantShardKey returns the shard key selector of a tenant. In shard per
+// tenant mode every tenant is stored under a shard key named after it.
+func (ds *DocStore) tenantShardKey(tenant string) (*qclient.ShardKeySelector, error) {
+	if !ds.shardPerTenant {
+		return nil, fmt.Errorf("qdrant: tenant %q given but Config.ShardPerTenant is not set", tenant)
+	}
+	return &qclient.ShardKeySelector{ShardKeys: []*qclient.ShardKey{qclient.NewShardKey(tenant)}}, nil
+}
+
+// indexShardKey returns the shard key selector for an index request,
+// creating the shard key of the request or of its tenant the first time
+// it is used.
+func (ds *DocStore) indexShardKey(ctx context.Context, iopt *IndexerOptions) (*qclient.ShardKeySelector, error) {
+	if iopt.ShardKey != "" {
+		if ds.shardPerTenant {
+			return nil, errors.New("qdrant: IndexerOptions.ShardKey cannot be used when Config.ShardPerTenant is set")
+		}
+		if err := ds.ensureShardKey(ctx, iopt.ShardKey); err != nil {
+			return nil, err
+		}
+		return &qclient.ShardKeySelector{ShardKeys: []*qclient.ShardKey{qclient.NewShardKey(iopt.ShardKey)}}, nil
+	}
+	if iopt.Tenant == "" {
+		if ds.shardPerTenant {
+			return nil, errors.New("qdrant: IndexerOptions.Tenant is required when Config.ShardPerTenant is set")
+		}
+		if ds.tenantKey != "" {
+			return nil, errors.New("qdrant: IndexerOptions.Tenant is required when Config.TenantKey is set")
+		}
+		return nil, nil
+	}
+	if !ds.shardPerTenant && ds.tenantKey != "" {
+		return nil, nil
+	}
+	sel, err := ds.tenantShardKey(iopt.Tenant)
+	if err != nil {
+		return nil, err
+	}
+	if err := ds.ensureShardKey(ctx, iopt.Tenant); err != nil {
+		return nil, err
+	}
+	return sel, nil
+}
+
+// ensureShardKey creates the shard key if the collection does not have it.
+// Known shard keys are cached, so the cluster is only consulted for
+// tenants not seen before.
+func (ds *DocStore) ensureShardKey(ctx context.Context, key string) error {
+	ds.shardMu.Lock()
+	defer ds.shardMu.Unlock()
+	if ds.knownShards[key] {
+		return nil
+	}
+	if err := ds.refreshShardKeys(ctx); err != nil {
+		return err
+	}
+	if ds.knownShards[key] {
+		return nil
+	}
+	if err := ds.writable("shard key creation"); err != nil {
+		return err
+	}
+	err := ds.client.CreateShardKey(ctx, ds.collectionName, &qclient.CreateShardKey{
+		ShardKey: qclient.NewShardKey(key),
+	})
+	if err != nil {
+		// Another process may have created the key concurrently.
+		if rerr := ds.refreshShardKeys(ctx); rerr == nil && ds.knownShards[key] {
+			return nil
+		}
+		return fmt.Errorf("qdrant failed to create shard key %q: %v", key, err)
+	}
+	ds.knownShards[key] = true
+	return nil
+}
+
+// refreshShardKeys reloads the cache of known shard keys. The caller must
+// hold ds.shardMu.
+func (ds *DocStore) refreshShardKeys(ctx context.Context) error {
+	keys, err := ds.clusterShardKeys(ctx)
+	if err != nil {
+		return err
+	}
+	if ds.knownShards == nil {
+		ds.knownShards = make(map[string]bool)
+	}
+	for _, k := range keys {
+		ds.knownShards[shardKeyString(k)] = true
+	}
+	return nil
+}
+
+// tenantFilter restricts filter to the points of a tenant when tenants
+// are partitioned by Config.TenantKey.
+func (ds *DocStore) tenantFilter(filter *qclient.Filter, tenant string) (*qclient.Filter, error) {
+	if ds.tenantKey == "" {
+		return filter, nil
+	}
+	if tenant == "" {
+		return nil, errors.New("qdrant: RetrieverOptions.Tenant is required when Config.TenantKey is set")
+	}
+	return &qclient.Filter{
+		Must: []*qclient.Condition{
+			qclient.NewMatchKeyword(ds.tenantKey, tenant),
+			qclient.NewFilterAsCondition(filter),
+		},
+	}, nil
+}
+
+// createTenantIndex creates the tenant index on Config.TenantKey, which
+// lets Qdrant co-locate the points of each tenant.
+func (ds *DocStore) createTenantIndex(ctx context.Context) error {
+	_, err := ds.client.CreateFieldIndex(ctx, &qclient.CreateFieldIndexCollection{
+		CollectionName: ds.collectionName,
+		FieldName:      ds.tenantKey,
+		FieldType:      qclient.FieldType_FieldTypeKeyword.Enum(),
+		FieldIndexParams: qclient.NewPayloadIndexParamsKeyword(&qclient.KeywordIndexParams{
+			IsTenant: qclient.PtrOf(true),
+		}),
+		Wait: qclient.PtrOf(true),
+	})
+	if err != nil {
+		return fmt.Errorf("qdrant failed to create tenant index: %v", err)
+	}
+	return nil
+}
+
+// TenantStats describes the data stored for a tenant.
+type TenantStats struct {
+	Tenant string
+	// Points is the exact number of points stored for the tenant.
+	Points uint64
+}
+
+// CreateTenant prepares the collection for a new tenant by creating its
+// shard key. Creating an existing tenant is not an error. Requires
+// Config.ShardPerTenant or Config.TenantKey; payload tenants need no
+// preparation.
+func (ds *DocStore) CreateTenant(ctx context.Context, tenant string) error {
+	if !ds.shardPerTenant && ds.tenantKey != "" {
+		if tenant == "" {
+			return errors.New("qdrant: tenant is required")
+		}
+		return nil
+	}
+	if _, err := ds.tenantShardKey(tenant); err != nil {
+		return err
+	}
+	return ds.ensureShardKey(ctx, tenant)
+}
+
+// DeleteTenant deletes all the data of a tenant by dropping its shard key,
+// or with Config.TenantKey by deleting the points of the tenant. Requires
+// Config.ShardPerTenant or Config.TenantKey.
+func (ds *DocStore) DeleteTenant(ctx context.Context, tenant string) error {
+	if err := ds.writable("DeleteTenant"); err != nil {
+		return err
+	}
+	if !ds.shardPerTenant && ds.tenantKey != "" {
+		filter, err := ds.tenantFilter(&qclient.Filter{}, tenant)
+		if err != nil {
+			return err
+		}
+		return ds.deleteByFilter(ctx, filter)
+	}
+	if _, err := ds.tenantShardKey(tenant); err != nil {
+		return err
+	}
+	ds.shardMu.Lock()
+	defer ds.shardMu.Unlock()
+	err := ds.client.DeleteShardKey(ctx, ds.collectionName, &qclient.DeleteShardKey{
+		ShardKey: qclient.NewShardKey(tenant),
+	})
+	if err != nil {
+		return fmt.Errorf("qdrant failed to delete tenant %q: %v", tenant, err)
+	}
+	delete(ds.knownShards, tenant)
+	return nil
+}
+
+// TenantStats returns statistics about the data of a tenant in the
+// namespace and environment of the store. Requires Config.ShardPerTenant
+// or Config.TenantKey.
+func (ds *DocStore) TenantStats(ctx context.Context, tenant string) (*TenantStats, error) {
+	req, err := ds.tenantStatsRequest(tenant)
+	if err != nil {
+		return nil, err
+	}
+	n, err := ds.client.Count(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("qdrant failed to count points of tenant %q: %v", tenant, err)
+	}
+	return &TenantStats{Tenant: tenant, Points: n}, nil
+}
+
+// tenantStatsRequest returns the count request of TenantStats.
+func (ds *DocStore) tenantStatsRequest(tenant string) (*qclient.CountPoints, error) {
+	if tenant == "" {
+		return nil, errors.New("qdrant: tenant is required")
+	}
+	if !ds.shardPerTenant && ds.tenantKey == "" {
+		return nil, fmt.Errorf("qdrant: tenant %q given but neither Config.ShardPerTenant nor Config.TenantKey is set", tenant)
+	}
+	return ds.countRequest(nil, &CountOptions{Tenant: tenant})
+}
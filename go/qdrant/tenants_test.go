@@ -0,0 +1,110 @@
+package qdrant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestIndexShardKey(t *testing.T) {
+	ctx := context.Background()
+	ds := &DocStore{shardPerTenant: true, knownShards: map[string]bool{"acme": true}}
+	if _, err := ds.indexShardKey(ctx, &IndexerOptions{}); err == nil {
+		t.Error("expected an error for a missing tenant")
+	}
+	// A known tenant must not require a round trip to the cluster.
+	sel, err := ds.indexShardKey(ctx, &IndexerOptions{Tenant: "acme"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sel.GetShardKeys()[0].GetKeyword(); got != "acme" {
+		t.Errorf("got shard key %q, want %q", got, "acme")
+	}
+
+	ds = &DocStore{}
+	if sel, err := ds.indexShardKey(ctx, &IndexerOptions{}); sel != nil || err != nil {
+		t.Errorf("got %v, %v; want no shard key without ShardPerTenant", sel, err)
+	}
+	if _, err := ds.indexShardKey(ctx, &IndexerOptions{Tenant: "acme"}); err == nil {
+		t.Error("expected an error for a tenant without ShardPerTenant")
+	}
+}
+
+func TestTenantHelpersRequireShardPerTenant(t *testing.T) {
+	ctx := context.Background()
+	ds := &DocStore{}
+	if err := ds.CreateTenant(ctx, "acme"); err == nil {
+		t.Error("CreateTenant: expected an error without ShardPerTenant")
+	}
+	if err := ds.DeleteTenant(ctx, "acme"); err == nil {
+		t.Error("DeleteTenant: expected an error without ShardPerTenant")
+	}
+	if _, err := ds.TenantStats(ctx, "acme"); err == nil {
+		t.Error("TenantStats: expected an error without ShardPerTenant")
+	}
+}
+
+func TestTenantHelpersPayload(t *testing.T) {
+	ds := &DocStore{collectionName: "docs", tenantKey: "tenant", namespace: "app"}
+	if err := ds.CreateTenant(context.Background(), "acme"); err != nil {
+		t.Errorf("CreateTenant: %v", err)
+	}
+	req, err := ds.tenantStatsRequest("acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	must := req.GetFilter().GetMust()
+	if req.ShardKeySelector != nil || len(must) != 2 || must[0].GetField().GetKey() != namespacePayloadKey ||
+		must[1].GetFilter().GetMust()[0].GetField().GetMatch().GetKeyword() != "acme" {
+		t.Errorf("got %v, want a count of the tenant in the namespace", req)
+	}
+	if _, err := ds.tenantStatsRequest(""); err == nil {
+		t.Error("TenantStats: expected an error for a missing tenant")
+	}
+}
+
+func TestTenantPayload(t *testing.T) {
+	ctx := context.Background()
+	ds := &DocStore{tenantKey: "tenant"}
+	if _, err := ds.indexShardKey(ctx, &IndexerOptions{}); err == nil {
+		t.Error("expected an error for a missing tenant")
+	}
+	if sel, err := ds.indexShardKey(ctx, &IndexerOptions{Tenant: "acme"}); sel != nil || err != nil {
+		t.Errorf("got %v, %v; want no shard key with a payload tenant", sel, err)
+	}
+
+	if _, err := ds.tenantFilter(nil, ""); err == nil {
+		t.Error("expected an error for a retrieval without a tenant")
+	}
+	f, err := ds.tenantFilter(nil, "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := f.GetMust()[0].GetField(); c.GetKey() != "tenant" || c.GetMatch().GetKeyword() != "acme" {
+		t.Errorf("got filter %v", f)
+	}
+
+	doc := ai.DocumentFromText("hello", nil)
+	a, _ := ds.pointID(doc, "a")
+	b, _ := ds.pointID(doc, "b")
+	if a == b {
+		t.Error("got the same point ID for two tenants")
+	}
+}
+
+func TestIndexShardKeyOption(t *testing.T) {
+	ctx := context.Background()
+	ds := &DocStore{knownShards: map[string]bool{"eu": true}}
+	sel, err := ds.indexShardKey(ctx, &IndexerOptions{ShardKey: "eu"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sel.GetShardKeys()[0].GetKeyword(); got != "eu" {
+		t.Errorf("got shard key %q, want eu", got)
+	}
+	ds.shardPerTenant = true
+	if _, err := ds.indexShardKey(ctx, &IndexerOptions{ShardKey: "eu", Tenant: "acme"}); err == nil {
+		t.Error("expected an error for a shard key with ShardPerTenant")
+	}
+}
@@ -0,0 +1,29 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// withTimeout returns ctx with a deadline d from now, or ctx itself if d is
+// not positive. The cause of a timeout names the operation.
+func withTimeout(ctx context.Context, op string, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeoutCause(ctx, d, fmt.Errorf("qdrant.%s timed out after %v", op, d))
+}
+
+// timeoutError returns the cause of a timeout of ctx set by withTimeout
+// instead of err, which is usually a less helpful DeadlineExceeded status.
+func timeoutError(ctx context.Context, err error) error {
+	if err == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	if cause := context.Cause(ctx); cause != ctx.Err() {
+		return fmt.Errorf("%w: %v", cause, err)
+	}
+	return err
+}
@@ -0,0 +1,32 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithTimeout(t *testing.T) {
+	ctx, cancel := withTimeout(context.Background(), "Index", 0)
+	cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("got a deadline without a timeout")
+	}
+
+	ctx, cancel = withTimeout(context.Background(), "Retrieve", time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+	failed := errors.New("rpc error: code = DeadlineExceeded")
+	err := timeoutError(ctx, failed)
+	if !strings.Contains(err.Error(), "qdrant.Retrieve timed out after 1ms") {
+		t.Errorf("got %v, want the timeout as cause", err)
+	}
+	if err := timeoutError(ctx, nil); err != nil {
+		t.Errorf("got %v for a successful call", err)
+	}
+	if err := timeoutError(context.Background(), failed); err != failed {
+		t.Errorf("got %v without a timeout, want the original error", err)
+	}
+}
@@ -0,0 +1,64 @@
+package qdrant
+
+import (
+	"github.com/firebase/genkit/go/ai"
+)
+
+// Usage counts the work done on behalf of a tenant, so that the cost of
+// embedding and Qdrant operations can be attributed.
+//
+// Genkit embedders do not report token usage, so the size of the embedded
+// text is counted in bytes instead.
+type Usage struct {
+	// EmbeddedDocuments and EmbeddedBytes count the documents and query
+	// texts sent to the embedder.
+	EmbeddedDocuments int64
+	EmbeddedBytes     int64
+	// Upserts counts upsert requests and UpsertedPoints the points they
+	// wrote.
+	Upserts        int64
+	UpsertedPoints int64
+	// Queries counts query requests and RetrievedDocuments the documents
+	// they returned.
+	Queries            int64
+	RetrievedDocuments int64
+}
+
+// Usage returns the usage recorded since Init, keyed by tenant. Requests
+// without a tenant are recorded under the empty string.
+func (ds *DocStore) Usage() map[string]Usage {
+	ds.usageMu.Lock()
+	defer ds.usageMu.Unlock()
+	usage := make(map[string]Usage, len(ds.usage))
+	for tenant, u := range ds.usage {
+		usage[tenant] = *u
+	}
+	return usage
+}
+
+// recordUsage applies fn to the usage of tenant.
+func (ds *DocStore) recordUsage(tenant string, fn func(u *Usage)) {
+	ds.usageMu.Lock()
+	defer ds.usageMu.Unlock()
+	if ds.usage == nil {
+		ds.usage = make(map[string]*Usage)
+	}
+	u, ok := ds.usage[tenant]
+	if !ok {
+		u = &Usage{}
+		ds.usage[tenant] = u
+	}
+	fn(u)
+}
+
+// recordEmbedding records the documents sent to the embedder.
+func (ds *DocStore) recordEmbedding(tenant string, docs []*ai.Document) {
+	var n int64
+	for _, d := range docs {
+		n += int64(documentLen(d))
+	}
+	ds.recordUsage(tenant, func(u *Usage) {
+		u.EmbeddedDocuments += int64(len(docs))
+		u.EmbeddedBytes += n
+	})
+}
@@ -0,0 +1,28 @@
+package qdrant
+
+import (
+	"testing"
+
+	"github.com/firebase/genkit/go/ai"
+)
+
+func TestUsage(t *testing.T) {
+	ds := &DocStore{}
+	ds.recordEmbedding("acme", []*ai.Document{ai.DocumentFromText("abc", nil), ai.DocumentFromText("de", nil)})
+	ds.recordUsage("acme", func(u *Usage) { u.Upserts++ })
+	ds.recordUsage("", func(u *Usage) { u.Queries++ })
+
+	usage := ds.Usage()
+	if got := usage["acme"]; got.EmbeddedDocuments != 2 || got.EmbeddedBytes != 5 || got.Upserts != 1 {
+		t.Errorf("got acme usage %+v", got)
+	}
+	if got := usage[""]; got.Queries != 1 {
+		t.Errorf("got default usage %+v", got)
+	}
+
+	// The returned map is a snapshot.
+	ds.recordUsage("", func(u *Usage) { u.Queries++ })
+	if usage[""].Queries != 1 {
+		t.Error("Usage returned live counters")
+	}
+}
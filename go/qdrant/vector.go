@@ -0,0 +1,82 @@
+package qdrant
+
+import (
+	"fmt"
+	"math"
+)
+
+// VectorPolicy selects what the indexer does with embeddings holding NaN
+// or infinite values, which would poison similarity scores.
+type VectorPolicy int
+
+const (
+	// VectorError fails the index request.
+	VectorError VectorPolicy = iota
+	// VectorClamp replaces NaN with 0 and infinities with the largest
+	// finite magnitude of the vector, or 1 if it has none, of the same
+	// sign. Clamping to the largest float32 instead would overflow the
+	// norm of the vector.
+	VectorClamp
+	// VectorSkip skips the document and passes it to Config.Quarantine.
+	VectorSkip
+)
+
+// NonFiniteVectorError reports an embedding holding a NaN or infinite
+// value.
+type NonFiniteVectorError struct {
+	// Document is the index of the document in the request, or -1 for a
+	// retrieval query.
+	Document int
+	// Position is the index of the first non-finite value.
+	Position int
+	Value    float32
+}
+
+func (e *NonFiniteVectorError) Error() string {
+	if e.Document < 0 {
+		return fmt.Sprintf("qdrant: query embedding has non-finite value %v at position %d", e.Value, e.Position)
+	}
+	return fmt.Sprintf("qdrant: embedding of document %d has non-finite value %v at position %d", e.Document, e.Value, e.Position)
+}
+
+// checkVector returns the vector to store for the embedding of document
+// doc following policy. With VectorClamp it returns a sanitized copy; with
+// the other policies it returns a *NonFiniteVectorError.
+func checkVector(v []float32, doc int, policy VectorPolicy) ([]float32, error) {
+	pos := -1
+	for i, x := range v {
+		if math.IsNaN(float64(x)) || math.IsInf(float64(x), 0) {
+			pos = i
+			break
+		}
+	}
+	if pos < 0 {
+		return v, nil
+	}
+	if policy != VectorClamp {
+		return nil, &NonFiniteVectorError{Document: doc, Position: pos, Value: v[pos]}
+	}
+	bound := float32(0)
+	for _, x := range v {
+		if !math.IsNaN(float64(x)) && !math.IsInf(float64(x), 0) {
+			bound = max(bound, float32(math.Abs(float64(x))))
+		}
+	}
+	if bound == 0 {
+		bound = 1
+	}
+	clamped := make([]float32, len(v))
+	for i, x := range v {
+		switch {
+		case math.IsNaN(float64(x)):
+			clamped[i] = 0
+		case math.IsInf(float64(x), 1):
+			clamped[i] = bound
+		case math.IsInf(float64(x), -1):
+			clamped[i] = -bound
+		default:
+			clamped[i] = x
+		}
+	}
+	return clamped, nil
+}
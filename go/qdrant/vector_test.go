@@ -0,0 +1,43 @@
+package qdrant
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCheckVector(t *testing.T) {
+	nan := float32(math.NaN())
+	inf := float32(math.Inf(-1))
+	v := []float32{0.5, nan, inf}
+
+	_, err := checkVector(v, 3, VectorError)
+	var nf *NonFiniteVectorError
+	if !errors.As(err, &nf) || nf.Document != 3 || nf.Position != 1 {
+		t.Errorf("got %v, want a non-finite error for document 3 at position 1", err)
+	}
+	if _, err := checkVector(v, 0, VectorSkip); err == nil {
+		t.Error("VectorSkip: expected an error so the document is quarantined")
+	}
+
+	got, err := checkVector(v, 0, VectorClamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0] != 0.5 || got[1] != 0 || got[2] != -0.5 {
+		t.Errorf("got %v", got)
+	}
+	var norm float32
+	for _, x := range got {
+		norm += x * x
+	}
+	if math.IsInf(float64(norm), 0) || norm == 0 {
+		t.Errorf("clamped vector %v has norm %v", got, norm)
+	}
+	if got, _ := checkVector([]float32{inf, nan}, 0, VectorClamp); got[0] != -1 || got[1] != 0 {
+		t.Errorf("got %v for a vector without finite values", got)
+	}
+	if !math.IsNaN(float64(v[1])) {
+		t.Error("clamping modified the embedding in place")
+	}
+}
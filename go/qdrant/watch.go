@@ -0,0 +1,184 @@
+package qdrant
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	qclient "github.com/qdrant/go-client/qdrant"
+)
+
+// contentHashKey is the metadata key holding the hash of the file a
+// document was read from.
+const contentHashKey = "content_hash"
+
+// DirWatcher keeps a collection in sync with the files of a directory,
+// read with the [Extractor] registered for their MIME type: new and
+// changed files are (re)indexed and the chunks of removed files are
+// deleted. Files are identified by their absolute path and compared by
+// content hash, so unchanged files are never re-embedded, including
+// after a restart.
+type DirWatcher struct {
+	Store *DocStore
+	Dir   string
+	// Extensions restricts the watched files, e.g. []string{".md", ".txt"}.
+	// If empty, all files with a registered extractor are watched.
+	Extensions []string
+	// Interval is the time between scans of the directory in Run.
+	// Defaults to 5s.
+	Interval time.Duration
+	// Chunk configures how files are split before indexing.
+	Chunk ChunkOptions
+
+	hashes map[string]string // content hash by source ID
+}
+
+// SyncResult reports the changes applied by [DirWatcher.Sync].
+type SyncResult struct {
+	Indexed   int
+	Deleted   int
+	Unchanged int
+}
+
+// Run syncs the directory every Interval until ctx is done, and then
+// returns ctx.Err(). Failed syncs are logged and retried at the next scan.
+func (w *DirWatcher) Run(ctx context.Context) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := w.Sync(ctx); err != nil {
+			slog.WarnContext(ctx, "qdrant directory sync failed", "dir", w.Dir, "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync scans the directory once and applies the changes since the last
+// scan. The first call loads the hashes of the files already indexed.
+func (w *DirWatcher) Sync(ctx context.Context) (*SyncResult, error) {
+	root, err := filepath.Abs(w.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if w.hashes == nil {
+		if w.hashes, err = w.indexedHashes(ctx, root); err != nil {
+			return nil, err
+		}
+	}
+
+	files, err := w.scan(root)
+	if err != nil {
+		return nil, err
+	}
+	res := &SyncResult{}
+	for path, hash := range files {
+		if w.hashes[path] == hash {
+			res.Unchanged++
+			continue
+		}
+		doc, err := readDocument(ctx, path)
+		if err != nil {
+			return res, err
+		}
+		doc.Metadata[contentHashKey] = hash
+		if err := w.Store.ReplaceSource(ctx, path, []*ai.Document{doc}, w.Chunk); err != nil {
+			return res, err
+		}
+		w.hashes[path] = hash
+		res.Indexed++
+	}
+	for path := range w.hashes {
+		if _, ok := files[path]; ok {
+			continue
+		}
+		if err := w.Store.DeleteSource(ctx, path); err != nil {
+			return res, err
+		}
+		delete(w.hashes, path)
+		res.Deleted++
+	}
+	return res, nil
+}
+
+// scan returns the content hash of every watched file under root that an
+// extractor can read.
+func (w *DirWatcher) scan(root string) (map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() || !w.watched(path) {
+			return nil
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if extractor(fileMIMEType(path, b)) == nil {
+			return nil
+		}
+		sum := sha256.Sum256(b)
+		files[path] = hex.EncodeToString(sum[:])
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("qdrant directory scan failed: %v", err)
+	}
+	return files, nil
+}
+
+func (w *DirWatcher) watched(path string) bool {
+	if len(w.Extensions) == 0 {
+		return true
+	}
+	ext := filepath.Ext(path)
+	for _, e := range w.Extensions {
+		if strings.EqualFold(e, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// indexedHashes returns the content hashes of the files under root that
+// are already in the collection.
+func (w *DirWatcher) indexedHashes(ctx context.Context, root string) (map[string]string, error) {
+	ds := w.Store
+	hashes := make(map[string]string)
+	prefix := root + string(filepath.Separator)
+	err := ds.scroll(ctx, &qclient.ScrollPoints{
+		CollectionName: ds.collectionName,
+		Filter: ds.envFilter(&qclient.Filter{
+			MustNot: []*qclient.Condition{qclient.NewIsEmpty(ds.metadataField(contentHashKey))},
+		}, &RetrieverOptions{}),
+		WithPayload: qclient.NewWithPayloadInclude(ds.metadataField(sourceIDKey), ds.metadataField(contentHashKey)),
+	}, func(p *qclient.RetrievedPoint) error {
+		md := p.Payload[ds.metadataPayloadKey].GetStructValue().GetFields()
+		source := md[sourceIDKey].GetStringValue()
+		if strings.HasPrefix(source, prefix) {
+			hashes[source] = md[contentHashKey].GetStringValue()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
@@ -0,0 +1,36 @@
+package qdrant
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDirWatcherScan(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"a.md": "alpha", "b.txt": "beta", "c.png": "binary"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w := &DirWatcher{Dir: dir, Extensions: []string{".md", ".TXT"}}
+	files, err := w.scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("got %d files, want 2: %v", len(files), files)
+	}
+	before := files[filepath.Join(dir, "a.md")]
+
+	if err := os.WriteFile(filepath.Join(dir, "a.md"), []byte("alpha v2"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	files, err = w.scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files[filepath.Join(dir, "a.md")] == before {
+		t.Error("content hash did not change with the file")
+	}
+}